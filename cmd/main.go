@@ -8,15 +8,22 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/GolovachevS/pr-reviewer-service/internal/availability"
 	"github.com/GolovachevS/pr-reviewer-service/internal/config"
 	migrate "github.com/GolovachevS/pr-reviewer-service/internal/db"
 	transport "github.com/GolovachevS/pr-reviewer-service/internal/http"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge/github"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge/gitlab"
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+	"github.com/GolovachevS/pr-reviewer-service/internal/metrics"
 	"github.com/GolovachevS/pr-reviewer-service/internal/service"
 	postgres "github.com/GolovachevS/pr-reviewer-service/internal/storage"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/GolovachevS/pr-reviewer-service/internal/tracing"
+	"github.com/GolovachevS/pr-reviewer-service/internal/webhook"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -33,29 +40,88 @@ func run() error {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	logger := newLogger(cfg.LogLevel)
+	logger := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
 	slog.SetDefault(logger)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		return runMigrate(ctx, cfg, os.Args[2:], os.Stdout)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
 	if err != nil {
-		return fmt.Errorf("connect database: %w", err)
+		return fmt.Errorf("configure tracing: %w", err)
 	}
-	defer pool.Close()
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Warn("flush traces", slog.String("error", err.Error()))
+		}
+	}()
 
-	if err := pool.Ping(ctx); err != nil {
-		return fmt.Errorf("ping database: %w", err)
+	appMetrics := metrics.New()
+	store, readiness, closeStore, err := newRepository(ctx, cfg, appMetrics)
+	if err != nil {
+		return err
 	}
+	defer closeStore()
 
-	if err := migrate.Run(ctx, pool); err != nil {
-		return fmt.Errorf("run migrations: %w", err)
+	picker, err := service.NewPicker(cfg.ReviewerStrategy)
+	if err != nil {
+		return fmt.Errorf("configure reviewer picker: %w", err)
 	}
 
-	store := postgres.New(pool)
-	svc := service.New(store, service.NewRandomPicker())
-	httpServer := transport.NewServer(svc)
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{
+		PollInterval: cfg.WebhookPollInterval,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+	}, logger)
+	dispatcherCtx, stopDispatcher := context.WithCancel(ctx)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(dispatcherCtx)
+	}()
+	defer func() {
+		stopDispatcher()
+		<-dispatcherDone
+	}()
+
+	svc := service.New(store, picker, service.WithMetrics(appMetrics))
+	if cfg.AvailabilityReassignInterval > 0 {
+		reassigner := availability.NewReassigner(svc, cfg.AvailabilityReassignInterval, logger)
+		reassignerCtx, stopReassigner := context.WithCancel(ctx)
+		reassignerDone := make(chan struct{})
+		go func() {
+			defer close(reassignerDone)
+			reassigner.Run(reassignerCtx)
+		}()
+		defer func() {
+			stopReassigner()
+			<-reassignerDone
+		}()
+	}
+	if cfg.AdminToken == "" {
+		logger.Warn("ADMIN_TOKEN is not set, only stored API keys are accepted")
+	}
+	serverOptions := append([]transport.Option{
+		transport.WithAdminToken(cfg.AdminToken),
+		transport.WithMetrics(appMetrics),
+		transport.WithLogger(logger),
+	}, readiness...)
+	if cfg.GitHubSecret != "" {
+		serverOptions = append(serverOptions, transport.WithForge(github.New(cfg.GitHubSecret)))
+	}
+	if cfg.GitLabToken != "" {
+		serverOptions = append(serverOptions, transport.WithForge(gitlab.New(cfg.GitLabToken)))
+	}
+	httpServer := transport.NewServer(svc, serverOptions...)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.AppPort,
@@ -90,19 +156,36 @@ func run() error {
 	return nil
 }
 
-func newLogger(level string) *slog.Logger {
-	lvl := new(slog.LevelVar)
-	switch strings.ToLower(level) {
-	case "debug":
-		lvl.Set(slog.LevelDebug)
-	case "warn":
-		lvl.Set(slog.LevelWarn)
-	case "error":
-		lvl.Set(slog.LevelError)
-	default:
-		lvl.Set(slog.LevelInfo)
-	}
-
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: lvl})
-	return slog.New(handler)
+// newRepository builds the configured storage backend and returns the
+// readiness checks of its dependencies and a function releasing its
+// resources. Connection pool statistics are exported to m.
+func newRepository(ctx context.Context, cfg config.Config, m *metrics.Metrics) (service.Repository, []transport.Option, func(), error) {
+	if cfg.Storage == config.StorageMemory {
+		slog.Warn("using in-memory storage, data is lost on restart")
+		return memory.New(), nil, func() {}, nil
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("connect database: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, nil, nil, fmt.Errorf("ping database: %w", err)
+	}
+
+	if err := migrate.Run(ctx, pool); err != nil {
+		pool.Close()
+		return nil, nil, nil, fmt.Errorf("run migrations: %w", err)
+	}
+
+	m.RegisterPool(pool)
+	readiness := []transport.Option{
+		transport.WithReadinessCheck("database", pool.Ping),
+		transport.WithReadinessCheck("migrations", func(ctx context.Context) error {
+			return migrate.Verify(ctx, pool)
+		}),
+	}
+	return postgres.New(pool), readiness, pool.Close, nil
 }
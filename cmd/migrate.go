@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/config"
+	migrate "github.com/GolovachevS/pr-reviewer-service/internal/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate handles the `migrate` subcommand: up applies pending migrations,
+// down rolls back the given number of migrations (one by default) and status
+// prints the state of every migration.
+func runMigrate(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	if cfg.Storage != config.StoragePostgres {
+		return fmt.Errorf("migrations require STORAGE=%s", config.StoragePostgres)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer pool.Close()
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := migrate.Run(ctx, pool); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q: %s", args[1], migrateUsage)
+			}
+		}
+		if err := migrate.Rollback(ctx, pool, steps); err != nil {
+			return err
+		}
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	states, err := migrate.Status(ctx, pool)
+	if err != nil {
+		return err
+	}
+	return printStates(out, states)
+}
+
+func printStates(out io.Writer, states []migrate.State) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range states {
+		status, appliedAt := "pending", "-"
+		if s.AppliedAt != nil {
+			status = "applied"
+			appliedAt = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		switch {
+		case s.Changed:
+			status = "changed"
+		case s.Missing:
+			status = "missing"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,69 @@
+// Package availability hands over the reviews of users whose unavailability
+// period has started.
+package availability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// Actor is recorded in the audit log for reassignments made by the job.
+const Actor = "availability"
+
+// Service is the part of the service layer the reassigner works with.
+type Service interface {
+	ReassignUnavailableReviewers(ctx context.Context, now time.Time) ([]domain.ReviewerReplacement, error)
+}
+
+// Reassigner periodically moves the OPEN reviews of users whose
+// unavailability period started since the previous run.
+type Reassigner struct {
+	svc      Service
+	interval time.Duration
+	now      func() time.Time
+	logger   *slog.Logger
+}
+
+// NewReassigner returns a reassigner checking for started periods every
+// interval.
+func NewReassigner(svc Service, interval time.Duration, logger *slog.Logger) *Reassigner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Reassigner{
+		svc:      svc,
+		interval: interval,
+		now:      func() time.Time { return time.Now().UTC() },
+		logger:   logger,
+	}
+}
+
+// Run reassigns reviews until ctx is cancelled.
+func (r *Reassigner) Run(ctx context.Context) {
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("availability reassignment failed", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// RunOnce reassigns the reviews of users whose period covers now and returns
+// how many reviews were handed over.
+func (r *Reassigner) RunOnce(ctx context.Context) (int, error) {
+	replacements, err := r.svc.ReassignUnavailableReviewers(service.WithActor(ctx, Actor), r.now())
+	if err != nil {
+		return 0, fmt.Errorf("reassign unavailable reviewers: %w", err)
+	}
+	return len(replacements), nil
+}
@@ -0,0 +1,74 @@
+package availability
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+)
+
+type firstPicker struct{}
+
+func (firstPicker) Pick(candidates []service.Candidate, limit int) []string {
+	var ids []string
+	for _, c := range candidates[:min(limit, len(candidates))] {
+		ids = append(ids, c.UserID)
+	}
+	return ids
+}
+
+func (p firstPicker) PickOne(candidates []service.Candidate) (string, bool) {
+	ids := p.Pick(candidates, 1)
+	if len(ids) == 0 {
+		return "", false
+	}
+	return ids[0], true
+}
+
+func TestReassignerHandsOverReviewsOnce(t *testing.T) {
+	ctx := context.Background()
+	svc := service.New(memory.New(), firstPicker{})
+	members := []domain.TeamMember{
+		{UserID: "a1", Username: "Ann", IsActive: true},
+		{UserID: "b1", Username: "Bob", IsActive: true},
+		{UserID: "c1", Username: "Cid", IsActive: true},
+		{UserID: "d1", Username: "Dan", IsActive: true},
+	}
+	if _, err := svc.CreateTeam(ctx, domain.Team{TeamName: "core", Members: members}); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	pr, err := svc.CreatePullRequest(ctx, service.CreatePullRequestInput{PullRequestID: "pr-1", PullRequestName: "PR", AuthorID: "a1"})
+	if err != nil || !slices.Equal(pr.Assigned, []string{"b1", "c1"}) {
+		t.Fatalf("CreatePullRequest: %+v %v", pr, err)
+	}
+
+	now := time.Now().UTC()
+	if _, err := svc.CreateUnavailability(ctx, domain.Unavailability{UserID: "b1", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("CreateUnavailability: %v", err)
+	}
+
+	r := NewReassigner(svc, time.Minute, nil)
+	for i, want := range []int{1, 0} {
+		n, err := r.RunOnce(ctx)
+		if err != nil || n != want {
+			t.Fatalf("run %d: got %d, %v; want %d", i, n, err, want)
+		}
+	}
+
+	pr, err = svc.GetPullRequest(ctx, "", "pr-1")
+	if err != nil || !slices.Equal(pr.Assigned, []string{"c1", "d1"}) {
+		t.Fatalf("b1 must be replaced by d1: %+v %v", pr, err)
+	}
+	history, err := svc.GetPullRequestHistory(ctx, "", "pr-1")
+	if err != nil {
+		t.Fatalf("GetPullRequestHistory: %v", err)
+	}
+	last := history[len(history)-1]
+	if last.Reason != domain.AuditReviewerUnavailable || last.Actor != Actor || last.OldReviewerID != "b1" {
+		t.Fatalf("unexpected audit event: %+v", last)
+	}
+}
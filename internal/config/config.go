@@ -3,25 +3,86 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+)
+
+// Supported storage backends.
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
 )
 
 // Config stores runtime configuration for the service.
 type Config struct {
-	AppPort     string
-	DatabaseURL string
-	LogLevel    string
+	AppPort          string
+	Storage          string
+	DatabaseURL      string
+	LogLevel         string
+	LogFormat        string
+	ReviewerStrategy string
+	AdminToken       string
+	GitHubSecret     string
+	GitLabToken      string
+
+	WebhookPollInterval time.Duration
+	WebhookMaxAttempts  int
+
+	AvailabilityReassignInterval time.Duration
+
+	TracingEndpoint    string
+	TracingServiceName string
+	TracingSampleRatio float64
 }
 
 // Load reads configuration from environment variables with sane defaults.
 func Load() (Config, error) {
 	cfg := Config{
-		AppPort:     getEnv("PORT", "8080"),
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		AppPort:          getEnv("PORT", "8080"),
+		Storage:          getEnv("STORAGE", StoragePostgres),
+		DatabaseURL:      os.Getenv("DATABASE_URL"),
+		LogLevel:         getEnv("LOG_LEVEL", "info"),
+		LogFormat:        getEnv("LOG_FORMAT", logging.FormatText),
+		ReviewerStrategy: getEnv("REVIEWER_STRATEGY", "random"),
+		AdminToken:       os.Getenv("ADMIN_TOKEN"),
+		GitHubSecret:     os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitLabToken:      os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+
+		TracingEndpoint:    strings.TrimSuffix(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/"),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "pr-reviewer-service"),
+	}
+
+	var err error
+	if cfg.WebhookPollInterval, err = time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "1s")); err != nil || cfg.WebhookPollInterval <= 0 {
+		return Config{}, fmt.Errorf("WEBHOOK_POLL_INTERVAL must be a positive duration")
+	}
+	if cfg.WebhookMaxAttempts, err = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8")); err != nil || cfg.WebhookMaxAttempts < 1 {
+		return Config{}, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be a positive integer")
+	}
+	if interval := os.Getenv("AVAILABILITY_REASSIGN_INTERVAL"); interval != "" {
+		if cfg.AvailabilityReassignInterval, err = time.ParseDuration(interval); err != nil || cfg.AvailabilityReassignInterval <= 0 {
+			return Config{}, fmt.Errorf("AVAILABILITY_REASSIGN_INTERVAL must be a positive duration")
+		}
+	}
+	if cfg.TracingSampleRatio, err = strconv.ParseFloat(getEnv("TRACE_SAMPLE_RATIO", "1"), 64); err != nil || cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		return Config{}, fmt.Errorf("TRACE_SAMPLE_RATIO must be a number between 0 and 1")
+	}
+
+	if cfg.LogFormat != logging.FormatJSON && cfg.LogFormat != logging.FormatText {
+		return Config{}, fmt.Errorf("LOG_FORMAT must be json or text")
 	}
 
-	if cfg.DatabaseURL == "" {
-		return Config{}, fmt.Errorf("DATABASE_URL is required")
+	switch cfg.Storage {
+	case StoragePostgres:
+		if cfg.DatabaseURL == "" {
+			return Config{}, fmt.Errorf("DATABASE_URL is required")
+		}
+	case StorageMemory:
+	default:
+		return Config{}, fmt.Errorf("unknown STORAGE %q", cfg.Storage)
 	}
 
 	return cfg, nil
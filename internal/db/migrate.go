@@ -2,47 +2,347 @@ package migrate
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 //go:embed sql/*.sql
 var migrationFiles embed.FS
 
-// Run executes embedded SQL migrations sequentially.
+// lockKey identifies the advisory lock that serialises migration runs
+// between replicas starting at the same time.
+const lockKey int64 = 7_246_110_412_001
+
+const createVersionTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`
+
+// State describes a single migration as seen by Status.
+type State struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Changed reports that the embedded file no longer matches the applied checksum.
+	Changed bool
+	// Missing reports that the migration is recorded as applied but is not embedded in this build.
+	Missing bool
+}
+
+type migration struct {
+	version  int64
+	name     string
+	checksum string
+	up       string
+	down     string
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Run applies every pending migration, each in its own transaction.
+// It refuses to run if an already applied migration was edited or removed.
 func Run(ctx context.Context, pool *pgxpool.Pool) error {
-	entries, err := migrationFiles.ReadDir("sql")
-	if err != nil {
-		return fmt.Errorf("read migrations: %w", err)
+	return withLock(ctx, pool, func(conn *pgxpool.Conn, migrations []migration, applied map[int64]appliedMigration) error {
+		if err := verify(migrations, applied); err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, m.up, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`, m.version, m.name, m.checksum); err != nil {
+				return fmt.Errorf("apply migration %s: %w", label(m.version, m.name), err)
+			}
+		}
+		return nil
+	})
+}
+
+// Rollback reverts the given number of most recently applied migrations.
+func Rollback(ctx context.Context, pool *pgxpool.Pool, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("rollback steps must be positive, got %d", steps)
 	}
 
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Name() < entries[j].Name()
+	return withLock(ctx, pool, func(conn *pgxpool.Conn, migrations []migration, applied map[int64]appliedMigration) error {
+		if err := verify(migrations, applied); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.version]; !ok {
+				continue
+			}
+			if m.down == "" {
+				return fmt.Errorf("migration %s has no down script", label(m.version, m.name))
+			}
+			if err := apply(ctx, conn, m.down, `DELETE FROM schema_migrations WHERE version = $1`, m.version); err != nil {
+				return fmt.Errorf("roll back migration %s: %w", label(m.version, m.name), err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// Status lists embedded migrations together with their applied state,
+// followed by applied versions that are missing from this build.
+func Status(ctx context.Context, pool *pgxpool.Pool) ([]State, error) {
+	var states []State
+	err := withLock(ctx, pool, func(_ *pgxpool.Conn, migrations []migration, applied map[int64]appliedMigration) error {
+		states = buildStates(migrations, applied)
+		return nil
 	})
+	return states, err
+}
+
+// Verify checks that the database schema matches this build: every embedded
+// migration is applied unchanged and no unknown one is. Unlike Status it
+// neither takes the migration lock nor creates schema_migrations, so it is
+// cheap enough for readiness probes.
+func Verify(ctx context.Context, db querier) error {
+	migrations, err := loadMigrations(migrationFiles, "sql")
+	if err != nil {
+		return err
+	}
+	applied, err := loadApplied(ctx, db)
+	if err != nil {
+		return err
+	}
+	return verifyComplete(migrations, applied)
+}
+
+// querier is the part of a pool or connection loadApplied needs.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func withLock(ctx context.Context, pool *pgxpool.Pool, fn func(*pgxpool.Conn, []migration, map[int64]appliedMigration) error) error {
+	migrations, err := loadMigrations(migrationFiles, "sql")
+	if err != nil {
+		return err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			// a connection still holding the lock must not go back to the pool
+			_ = conn.Hijack().Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createVersionTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, migrations, applied)
+}
+
+func apply(ctx context.Context, conn *pgxpool.Conn, script, record string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer rollbackTx(ctx, tx)
 
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return fmt.Errorf("record version: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func rollbackTx(ctx context.Context, tx pgx.Tx) {
+	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		logging.FromContext(ctx).Warn("rollback migration", slog.String("error", err.Error()))
+	}
+}
+
+func loadApplied(ctx context.Context, conn querier) (map[int64]appliedMigration, error) {
+	rows, err := conn.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("select applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var (
+			version int64
+			item    appliedMigration
+		)
+		if err := rows.Scan(&version, &item.name, &item.checksum, &item.appliedAt); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[version] = item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// loadMigrations reads NNN_name.up.sql and optional NNN_name.down.sql pairs
+// from dir and returns them ordered by version.
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*migration)
+	downs := make(map[int64]string)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
-		queryBytes, err := migrationFiles.ReadFile("sql/" + entry.Name())
+		fileName := entry.Name()
+		base, isUp := strings.CutSuffix(fileName, ".up.sql")
+		if !isUp {
+			var isDown bool
+			if base, isDown = strings.CutSuffix(fileName, ".down.sql"); !isDown {
+				return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", fileName)
+			}
+		}
+
+		rawVersion, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(rawVersion, 10, 64)
+		if !ok || err != nil || version < 1 || name == "" {
+			return nil, fmt.Errorf("migration %s: expected NNN_name prefix", fileName)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
 		if err != nil {
-			return fmt.Errorf("read migration %s: %w", entry.Name(), err)
+			return nil, fmt.Errorf("read migration %s: %w", fileName, err)
 		}
 
-		query := strings.TrimSpace(string(queryBytes))
-		if query == "" {
+		if !isUp {
+			downs[version] = strings.TrimSpace(string(content))
 			continue
 		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", existing.name, name, version)
+		}
+		sum := sha256.Sum256(content)
+		byVersion[version] = &migration{
+			version:  version,
+			name:     name,
+			checksum: hex.EncodeToString(sum[:]),
+			up:       strings.TrimSpace(string(content)),
+		}
+	}
+
+	for version, down := range downs {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration %d has no matching up migration", version)
+		}
+		m.down = down
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
 
-		if _, err := pool.Exec(ctx, query); err != nil {
-			return fmt.Errorf("execute migration %s: %w", entry.Name(), err)
+// verify fails if an applied migration was edited after it ran or is no
+// longer shipped with the binary.
+func verify(migrations []migration, applied map[int64]appliedMigration) error {
+	for _, state := range buildStates(migrations, applied) {
+		switch {
+		case state.Changed:
+			return fmt.Errorf("migration %s was modified after it had been applied", label(state.Version, state.Name))
+		case state.Missing:
+			return fmt.Errorf("migration %s is applied but missing from this build", label(state.Version, state.Name))
 		}
 	}
+	return nil
+}
 
+// verifyComplete is verify that also fails while a migration is pending.
+func verifyComplete(migrations []migration, applied map[int64]appliedMigration) error {
+	if err := verify(migrations, applied); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; !ok {
+			return fmt.Errorf("migration %s is not applied", label(m.version, m.name))
+		}
+	}
 	return nil
 }
+
+func buildStates(migrations []migration, applied map[int64]appliedMigration) []State {
+	states := make([]State, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.version] = true
+		state := State{Version: m.version, Name: m.name}
+		if a, ok := applied[m.version]; ok {
+			appliedAt := a.appliedAt
+			state.AppliedAt = &appliedAt
+			state.Changed = a.checksum != m.checksum
+		}
+		states = append(states, state)
+	}
+
+	var missing []State
+	for version, a := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := a.appliedAt
+		missing = append(missing, State{Version: version, Name: a.name, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Version < missing[j].Version })
+
+	return append(states, missing...)
+}
+
+func label(version int64, name string) string {
+	return fmt.Sprintf("%03d_%s", version, name)
+}
@@ -0,0 +1,154 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadMigrationsOrdersAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/010_late.up.sql":     {Data: []byte("CREATE TABLE late ();")},
+		"sql/002_second.up.sql":   {Data: []byte("CREATE TABLE second ();")},
+		"sql/002_second.down.sql": {Data: []byte("DROP TABLE second;\n")},
+		"sql/001_first.up.sql":    {Data: []byte("CREATE TABLE first ();")},
+	}
+
+	migrations, err := loadMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(migrations) != 3 {
+		t.Fatalf("expected 3 migrations, got %d", len(migrations))
+	}
+	for i, want := range []int64{1, 2, 10} {
+		if migrations[i].version != want {
+			t.Fatalf("migration %d: expected version %d, got %d", i, want, migrations[i].version)
+		}
+	}
+	if migrations[1].name != "second" || migrations[1].down != "DROP TABLE second;" {
+		t.Fatalf("unexpected second migration: %+v", migrations[1])
+	}
+	if migrations[0].down != "" || migrations[0].checksum == "" || migrations[0].checksum == migrations[1].checksum {
+		t.Fatalf("unexpected first migration: %+v", migrations[0])
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "missing direction",
+			fsys: fstest.MapFS{"sql/001_init.sql": {}},
+			want: "suffix",
+		},
+		{
+			name: "missing version",
+			fsys: fstest.MapFS{"sql/init.up.sql": {}},
+			want: "NNN_name",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{"sql/001_a.up.sql": {}, "sql/001_b.up.sql": {}},
+			want: "share version",
+		},
+		{
+			name: "orphan down",
+			fsys: fstest.MapFS{"sql/001_a.up.sql": {}, "sql/002_b.down.sql": {}},
+			want: "no matching up",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(tt.fsys, "sql")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsHaveDownScripts(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "sql")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, m := range migrations {
+		if m.down == "" {
+			t.Fatalf("migration %s has no down script", label(m.version, m.name))
+		}
+	}
+}
+
+func TestVerifyDetectsEditedAndMissingMigrations(t *testing.T) {
+	migrations := []migration{{version: 1, name: "init", checksum: "aaa"}, {version: 2, name: "next", checksum: "bbb"}}
+	now := time.Now()
+
+	if err := verify(migrations, map[int64]appliedMigration{1: {name: "init", checksum: "aaa", appliedAt: now}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := verify(migrations, map[int64]appliedMigration{1: {name: "init", checksum: "changed", appliedAt: now}})
+	if err == nil || !strings.Contains(err.Error(), "001_init was modified") {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+
+	err = verify(migrations, map[int64]appliedMigration{3: {name: "gone", checksum: "ccc", appliedAt: now}})
+	if err == nil || !strings.Contains(err.Error(), "003_gone is applied but missing") {
+		t.Fatalf("expected missing migration error, got %v", err)
+	}
+}
+
+func TestVerifyCompleteRequiresEveryMigration(t *testing.T) {
+	migrations := []migration{{version: 1, name: "init", checksum: "aaa"}, {version: 2, name: "next", checksum: "bbb"}}
+	now := time.Now()
+
+	err := verifyComplete(migrations, map[int64]appliedMigration{1: {name: "init", checksum: "aaa", appliedAt: now}})
+	if err == nil || !strings.Contains(err.Error(), "002_next is not applied") {
+		t.Fatalf("expected pending migration error, got %v", err)
+	}
+
+	err = verifyComplete(migrations, map[int64]appliedMigration{
+		1: {name: "init", checksum: "aaa", appliedAt: now},
+		2: {name: "next", checksum: "changed", appliedAt: now},
+	})
+	if err == nil || !strings.Contains(err.Error(), "002_next was modified") {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+
+	if err := verifyComplete(migrations, map[int64]appliedMigration{
+		1: {name: "init", checksum: "aaa", appliedAt: now},
+		2: {name: "next", checksum: "bbb", appliedAt: now},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildStates(t *testing.T) {
+	migrations := []migration{{version: 1, name: "init", checksum: "aaa"}, {version: 2, name: "next", checksum: "bbb"}}
+	now := time.Now()
+
+	states := buildStates(migrations, map[int64]appliedMigration{
+		1: {name: "init", checksum: "aaa", appliedAt: now},
+		5: {name: "gone", checksum: "eee", appliedAt: now},
+	})
+
+	if len(states) != 3 {
+		t.Fatalf("expected 3 states, got %+v", states)
+	}
+	if states[0].AppliedAt == nil || states[0].Changed || states[0].Missing {
+		t.Fatalf("unexpected applied state: %+v", states[0])
+	}
+	if states[1].AppliedAt != nil {
+		t.Fatalf("expected pending state: %+v", states[1])
+	}
+	if states[2].Version != 5 || !states[2].Missing {
+		t.Fatalf("expected missing state: %+v", states[2])
+	}
+}
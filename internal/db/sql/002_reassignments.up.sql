@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS reviewer_reassignments (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    old_reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    new_reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reassignments_pr ON reviewer_reassignments(pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_reassignments_old ON reviewer_reassignments(old_reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_reassignments_new ON reviewer_reassignments(new_reviewer_id);
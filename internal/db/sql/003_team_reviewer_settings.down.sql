@@ -0,0 +1,3 @@
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_reviewers_check;
+ALTER TABLE teams DROP COLUMN IF EXISTS min_reviewers;
+ALTER TABLE teams DROP COLUMN IF EXISTS required_reviewers;
@@ -0,0 +1,10 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_reviewers INTEGER NOT NULL DEFAULT 2;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS min_reviewers INTEGER NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'teams_reviewers_check') THEN
+        ALTER TABLE teams ADD CONSTRAINT teams_reviewers_check
+            CHECK (required_reviewers >= 1 AND min_reviewers >= 0 AND min_reviewers <= required_reviewers);
+    END IF;
+END $$;
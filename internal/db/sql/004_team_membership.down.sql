@@ -0,0 +1,2 @@
+-- Fails while users without a team exist; move or delete them first.
+ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
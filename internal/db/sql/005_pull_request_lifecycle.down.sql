@@ -0,0 +1,10 @@
+-- Enum values cannot be dropped, so the type is rebuilt. Fails while DRAFT or
+-- CLOSED pull requests exist.
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS closed_at;
+
+ALTER TABLE pull_requests ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE pr_status RENAME TO pr_status_old;
+CREATE TYPE pr_status AS ENUM ('OPEN', 'MERGED');
+ALTER TABLE pull_requests ALTER COLUMN status TYPE pr_status USING status::text::pr_status;
+ALTER TABLE pull_requests ALTER COLUMN status SET DEFAULT 'OPEN';
+DROP TYPE pr_status_old;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    pull_request_id TEXT,
+    user_id TEXT,
+    old_reviewer_id TEXT,
+    new_reviewer_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_pr ON audit_events(pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user ON audit_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_old ON audit_events(old_reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_new ON audit_events(new_reviewer_id);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS forge_accounts (
+    forge TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (forge, login)
+);
+
+CREATE INDEX IF NOT EXISTS idx_forge_accounts_user ON forge_accounts(user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ownership_rules;
+DROP TABLE IF EXISTS pull_request_files;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS repository;
@@ -0,0 +1,19 @@
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS repository TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS pull_request_files (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, path)
+);
+
+CREATE TABLE IF NOT EXISTS ownership_rules (
+    rule_id BIGSERIAL PRIMARY KEY,
+    repository TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    team_name TEXT REFERENCES teams(team_name) ON DELETE CASCADE,
+    user_ids TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((team_name IS NULL) <> (cardinality(user_ids) = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ownership_rules_repository ON ownership_rules(repository, rule_id);
@@ -0,0 +1,37 @@
+-- Fails if the same pull request id is used in several repositories.
+DROP INDEX IF EXISTS idx_pull_requests_repository_created;
+DROP INDEX IF EXISTS idx_audit_events_pr;
+CREATE INDEX IF NOT EXISTS idx_audit_events_pr ON audit_events(pull_request_id);
+DROP INDEX IF EXISTS idx_reassignments_pr;
+CREATE INDEX IF NOT EXISTS idx_reassignments_pr ON reviewer_reassignments(pull_request_id);
+
+ALTER TABLE pull_request_files
+    DROP CONSTRAINT pull_request_files_pull_request_fkey,
+    DROP CONSTRAINT pull_request_files_pkey;
+ALTER TABLE reviewer_reassignments DROP CONSTRAINT reviewer_reassignments_pull_request_fkey;
+ALTER TABLE pull_request_reviewers
+    DROP CONSTRAINT pull_request_reviewers_pull_request_fkey,
+    DROP CONSTRAINT pull_request_reviewers_pkey;
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_pkey;
+
+ALTER TABLE pull_requests ADD PRIMARY KEY (pull_request_id);
+ALTER TABLE pull_request_reviewers
+    ADD PRIMARY KEY (pull_request_id, reviewer_id),
+    ADD CONSTRAINT pull_request_reviewers_pull_request_id_fkey FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE reviewer_reassignments
+    ADD CONSTRAINT reviewer_reassignments_pull_request_id_fkey FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pull_request_files
+    ADD PRIMARY KEY (pull_request_id, path),
+    ADD CONSTRAINT pull_request_files_pull_request_id_fkey FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+
+ALTER TABLE audit_events DROP COLUMN IF EXISTS repository;
+ALTER TABLE pull_request_files DROP COLUMN IF EXISTS repository;
+ALTER TABLE reviewer_reassignments DROP COLUMN IF EXISTS repository;
+ALTER TABLE pull_request_reviewers DROP COLUMN IF EXISTS repository;
+
+ALTER TABLE ownership_rules DROP CONSTRAINT IF EXISTS ownership_rules_repository_fkey;
+DROP TABLE IF EXISTS repository_teams;
+DROP TABLE IF EXISTS repositories;
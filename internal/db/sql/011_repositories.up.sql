@@ -0,0 +1,71 @@
+CREATE TABLE IF NOT EXISTS repositories (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS repository_teams (
+    repository TEXT NOT NULL REFERENCES repositories(name) ON DELETE CASCADE,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    PRIMARY KEY (repository, team_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_teams_team ON repository_teams(team_name);
+
+-- Repositories already named by pull requests and ownership rules are
+-- registered. Pull requests without one keep the empty repository.
+INSERT INTO repositories(name)
+SELECT repository FROM pull_requests WHERE repository <> ''
+UNION
+SELECT repository FROM ownership_rules
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE ownership_rules
+    ADD CONSTRAINT ownership_rules_repository_fkey
+    FOREIGN KEY (repository) REFERENCES repositories(name) ON DELETE CASCADE;
+
+-- Pull request ids become unique within a repository, so every table keyed
+-- by a pull request carries its repository too.
+ALTER TABLE pull_request_reviewers ADD COLUMN IF NOT EXISTS repository TEXT NOT NULL DEFAULT '';
+ALTER TABLE reviewer_reassignments ADD COLUMN IF NOT EXISTS repository TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_request_files ADD COLUMN IF NOT EXISTS repository TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS repository TEXT NOT NULL DEFAULT '';
+
+UPDATE pull_request_reviewers r SET repository = pr.repository
+FROM pull_requests pr WHERE pr.pull_request_id = r.pull_request_id AND pr.repository <> '';
+UPDATE reviewer_reassignments r SET repository = pr.repository
+FROM pull_requests pr WHERE pr.pull_request_id = r.pull_request_id AND pr.repository <> '';
+UPDATE pull_request_files f SET repository = pr.repository
+FROM pull_requests pr WHERE pr.pull_request_id = f.pull_request_id AND pr.repository <> '';
+
+ALTER TABLE audit_events DISABLE TRIGGER audit_events_append_only;
+UPDATE audit_events e SET repository = pr.repository
+FROM pull_requests pr WHERE pr.pull_request_id = e.pull_request_id AND pr.repository <> '';
+ALTER TABLE audit_events ENABLE TRIGGER audit_events_append_only;
+
+ALTER TABLE pull_request_reviewers
+    DROP CONSTRAINT pull_request_reviewers_pull_request_id_fkey,
+    DROP CONSTRAINT pull_request_reviewers_pkey;
+ALTER TABLE reviewer_reassignments DROP CONSTRAINT reviewer_reassignments_pull_request_id_fkey;
+ALTER TABLE pull_request_files
+    DROP CONSTRAINT pull_request_files_pull_request_id_fkey,
+    DROP CONSTRAINT pull_request_files_pkey;
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_pkey;
+
+ALTER TABLE pull_requests ADD PRIMARY KEY (repository, pull_request_id);
+ALTER TABLE pull_request_reviewers
+    ADD PRIMARY KEY (repository, pull_request_id, reviewer_id),
+    ADD CONSTRAINT pull_request_reviewers_pull_request_fkey FOREIGN KEY (repository, pull_request_id)
+        REFERENCES pull_requests(repository, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE reviewer_reassignments
+    ADD CONSTRAINT reviewer_reassignments_pull_request_fkey FOREIGN KEY (repository, pull_request_id)
+        REFERENCES pull_requests(repository, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pull_request_files
+    ADD PRIMARY KEY (repository, pull_request_id, path),
+    ADD CONSTRAINT pull_request_files_pull_request_fkey FOREIGN KEY (repository, pull_request_id)
+        REFERENCES pull_requests(repository, pull_request_id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_reassignments_pr;
+CREATE INDEX IF NOT EXISTS idx_reassignments_pr ON reviewer_reassignments(repository, pull_request_id);
+DROP INDEX IF EXISTS idx_audit_events_pr;
+CREATE INDEX IF NOT EXISTS idx_audit_events_pr ON audit_events(repository, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_repository_created ON pull_requests(repository, created_at);
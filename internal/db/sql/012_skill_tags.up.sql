@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS required_tags TEXT[] NOT NULL DEFAULT '{}';
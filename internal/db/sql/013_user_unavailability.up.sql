@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_unavailability (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    reviews_reassigned_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_unavailability_user ON user_unavailability(user_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_user_unavailability_pending ON user_unavailability(starts_at)
+    WHERE reviews_reassigned_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS max_open_reviews INT CHECK (max_open_reviews >= 0);
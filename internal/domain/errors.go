@@ -1,19 +1,29 @@
 package domain
 
 import (
+	"fmt"
 	"net/http"
 )
 
 type ErrorCode string
 
 const (
-	ErrCodeTeamExists  ErrorCode = "TEAM_EXISTS"
-	ErrCodePRExists    ErrorCode = "PR_EXISTS"
-	ErrCodePRMerged    ErrorCode = "PR_MERGED"
-	ErrCodeNotAssigned ErrorCode = "NOT_ASSIGNED"
-	ErrCodeNoCandidate ErrorCode = "NO_CANDIDATE"
-	ErrCodeNotFound    ErrorCode = "NOT_FOUND"
-	ErrCodeInternal    ErrorCode = "INTERNAL"
+	ErrCodeTeamExists        ErrorCode = "TEAM_EXISTS"
+	ErrCodePRExists          ErrorCode = "PR_EXISTS"
+	ErrCodePRMerged          ErrorCode = "PR_MERGED"
+	ErrCodePRClosed          ErrorCode = "PR_CLOSED"
+	ErrCodeInvalidTransition ErrorCode = "INVALID_TRANSITION"
+	ErrCodeNotAssigned       ErrorCode = "NOT_ASSIGNED"
+	ErrCodeNoCandidate       ErrorCode = "NO_CANDIDATE"
+	ErrCodeCapReached        ErrorCode = "REVIEW_CAP_REACHED"
+	ErrCodeNotFound          ErrorCode = "NOT_FOUND"
+	ErrCodeUserInTeam        ErrorCode = "USER_IN_TEAM"
+	ErrCodeRepositoryExists  ErrorCode = "REPOSITORY_EXISTS"
+	ErrCodeRepositoryInUse   ErrorCode = "REPOSITORY_IN_USE"
+	ErrCodeTeamNotLinked     ErrorCode = "TEAM_NOT_LINKED"
+	ErrCodeUnauthorized      ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden         ErrorCode = "FORBIDDEN"
+	ErrCodeInternal          ErrorCode = "INTERNAL"
 )
 
 // AppError keeps domain level errors consistent.
@@ -51,6 +61,18 @@ func NewPRMergedError() *AppError {
 	return &AppError{Code: ErrCodePRMerged, Message: "cannot mutate merged pull request", Status: http.StatusConflict}
 }
 
+func NewPRClosedError() *AppError {
+	return &AppError{Code: ErrCodePRClosed, Message: "cannot mutate closed pull request", Status: http.StatusConflict}
+}
+
+func NewInvalidTransitionError(from, to string) *AppError {
+	return &AppError{
+		Code:    ErrCodeInvalidTransition,
+		Message: fmt.Sprintf("pull request cannot move from %s to %s", from, to),
+		Status:  http.StatusConflict,
+	}
+}
+
 func NewNotAssignedError() *AppError {
 	return &AppError{Code: ErrCodeNotAssigned, Message: "reviewer is not assigned to this pull request", Status: http.StatusConflict}
 }
@@ -58,3 +80,54 @@ func NewNotAssignedError() *AppError {
 func NewNoCandidateError() *AppError {
 	return &AppError{Code: ErrCodeNoCandidate, Message: "no active replacement candidate in team", Status: http.StatusConflict}
 }
+
+func NewNotEnoughReviewersError() *AppError {
+	return &AppError{Code: ErrCodeNoCandidate, Message: "not enough active reviewers in team", Status: http.StatusConflict}
+}
+
+// NewCapReachedError is returned instead of NewNoCandidateError and
+// NewNotEnoughReviewersError when candidates were left out only because they
+// review as many OPEN pull requests as their cap allows.
+func NewCapReachedError() *AppError {
+	return &AppError{Code: ErrCodeCapReached, Message: "remaining reviewers reached their open review limit", Status: http.StatusConflict}
+}
+
+func NewNoOwnerError(pattern string) *AppError {
+	return &AppError{
+		Code:    ErrCodeNoCandidate,
+		Message: fmt.Sprintf("no active reviewer for ownership rule %q", pattern),
+		Status:  http.StatusConflict,
+	}
+}
+
+// NewInvalidTeamSettingsError reports settings that are invalid only together
+// with the stored ones. It uses the same status and code as request validation.
+func NewInvalidTeamSettingsError(err error) *AppError {
+	return &AppError{Code: ErrCodeNotFound, Message: "min_reviewers must be between 0 and required_reviewers", Status: http.StatusBadRequest, Err: err}
+}
+
+func NewUserInTeamError() *AppError {
+	return &AppError{Code: ErrCodeUserInTeam, Message: "user already belongs to another team", Status: http.StatusConflict}
+}
+
+func NewRepositoryExistsError(err error) *AppError {
+	return &AppError{Code: ErrCodeRepositoryExists, Message: "repository already exists", Status: http.StatusConflict, Err: err}
+}
+
+func NewRepositoryInUseError() *AppError {
+	return &AppError{Code: ErrCodeRepositoryInUse, Message: "repository has pull requests", Status: http.StatusConflict}
+}
+
+// NewTeamNotLinkedError is returned when a pull request is created in a
+// repository linked to teams other than the author's.
+func NewTeamNotLinkedError() *AppError {
+	return &AppError{Code: ErrCodeTeamNotLinked, Message: "author's team does not work in this repository", Status: http.StatusConflict}
+}
+
+func NewUnauthorizedError() *AppError {
+	return &AppError{Code: ErrCodeUnauthorized, Message: "missing, unknown or revoked API token", Status: http.StatusUnauthorized}
+}
+
+func NewForbiddenError() *AppError {
+	return &AppError{Code: ErrCodeForbidden, Message: "API key role does not allow this operation", Status: http.StatusForbidden}
+}
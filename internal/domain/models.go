@@ -2,48 +2,320 @@ package domain
 
 import "time"
 
-// TeamMember describes a user within a team payload.
+// TeamMember describes a user within a team payload. MaxOpenReviews overrides
+// the team cap for the user; OpenReviews is only filled in responses.
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID         string   `json:"user_id"`
+	Username       string   `json:"username"`
+	IsActive       bool     `json:"is_active"`
+	Tags           []string `json:"tags,omitempty"`
+	MaxOpenReviews *int     `json:"max_open_reviews,omitempty"`
+	OpenReviews    int      `json:"open_reviews"`
 }
 
+// DefaultRequiredReviewers is used when a team does not configure its own count.
+const DefaultRequiredReviewers = 2
+
+// MaxRequiredReviewers caps how many reviewers a team may request per PR.
+const MaxRequiredReviewers = 10
+
 // Team represents a team with members.
+// RequiredReviewers is how many reviewers are assigned to a new PR of the team,
+// MinReviewers is the least number the PR may be created with.
+// MaxOpenReviews is the default cap on OPEN reviews per member, 0 means none.
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName          string       `json:"team_name"`
+	RequiredReviewers int          `json:"required_reviewers"`
+	MinReviewers      int          `json:"min_reviewers"`
+	MaxOpenReviews    int          `json:"max_open_reviews"`
+	Members           []TeamMember `json:"members"`
 }
 
-// User is a single user entity.
+// User is a single user entity. TeamName is empty for users removed from their team.
+// A nil MaxOpenReviews falls back to the team cap.
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID         string   `json:"user_id"`
+	Username       string   `json:"username"`
+	TeamName       string   `json:"team_name"`
+	IsActive       bool     `json:"is_active"`
+	Tags           []string `json:"tags,omitempty"`
+	MaxOpenReviews *int     `json:"max_open_reviews,omitempty"`
 }
 
-// PullRequest holds PR data returned to clients.
+// Pull request statuses. A DRAFT has no reviewers until it is marked ready;
+// reviewers of MERGED and CLOSED pull requests are frozen.
+const (
+	StatusDraft  = "DRAFT"
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// PullRequest holds PR data returned to clients. PullRequestID is unique
+// within Repository; PRs without a repository share the empty one.
 type PullRequest struct {
 	PullRequestID   string     `json:"pull_request_id"`
+	Repository      string     `json:"repository,omitempty"`
 	PullRequestName string     `json:"pull_request_name"`
 	AuthorID        string     `json:"author_id"`
 	Status          string     `json:"status"`
 	Assigned        []string   `json:"assigned_reviewers"`
+	RequiredTags    []string   `json:"required_tags,omitempty"`
 	CreatedAt       time.Time  `json:"createdAt"`
 	MergedAt        *time.Time `json:"mergedAt"`
+	ClosedAt        *time.Time `json:"closedAt"`
+	// SkippedAtCap lists teammates left out at their open review cap when the
+	// PR got fewer than required_reviewers. It is set only in the response of
+	// the call that assigned the reviewers.
+	SkippedAtCap []string `json:"skipped_at_cap,omitempty"`
+}
+
+// PullRequestList is a page of pull requests. NextCursor is empty on the last
+// page.
+type PullRequestList struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
 }
 
 // PullRequestShort is used for listing assignments per reviewer.
 type PullRequestShort struct {
 	PullRequestID   string `json:"pull_request_id"`
+	Repository      string `json:"repository,omitempty"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
 }
 
-// UserReviews bundles review assignments for response payloads.
+// UserReviews bundles review assignments for response payloads. NextCursor is
+// empty on the last page.
 type UserReviews struct {
 	UserID       string             `json:"user_id"`
 	PullRequests []PullRequestShort `json:"pull_requests"`
+	NextCursor   string             `json:"next_cursor,omitempty"`
+}
+
+// ReviewerStats aggregates review load for a single user.
+type ReviewerStats struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       bool   `json:"is_active"`
+	AssignedTotal  int    `json:"assigned_total"`
+	OpenReviews    int    `json:"open_reviews"`
+	MergedReviews  int    `json:"merged_reviews"`
+	ClosedReviews  int    `json:"closed_reviews"`
+	ReassignedFrom int    `json:"reassigned_from"`
+	ReassignedTo   int    `json:"reassigned_to"`
+}
+
+// TeamStats aggregates pull requests and review load for a team.
+type TeamStats struct {
+	TeamName           string `json:"team_name"`
+	MembersTotal       int    `json:"members_total"`
+	ActiveMembers      int    `json:"active_members"`
+	PullRequestsTotal  int    `json:"pull_requests_total"`
+	DraftPullRequests  int    `json:"draft_pull_requests"`
+	OpenPullRequests   int    `json:"open_pull_requests"`
+	MergedPullRequests int    `json:"merged_pull_requests"`
+	ClosedPullRequests int    `json:"closed_pull_requests"`
+	AssignmentsTotal   int    `json:"assignments_total"`
+	OpenAssignments    int    `json:"open_assignments"`
+	Reassignments      int    `json:"reassignments"`
+}
+
+// PullRequestStats describes assignment counters of a single PR.
+type PullRequestStats struct {
+	PullRequestID   string `json:"pull_request_id"`
+	Repository      string `json:"repository,omitempty"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Status          string `json:"status"`
+	ReviewersCount  int    `json:"reviewers_count"`
+	Reassignments   int    `json:"reassignments"`
+}
+
+// ReviewerReplacement describes a reviewer swap on a single pull request.
+// NewReviewerID is empty when no active candidate was available.
+type ReviewerReplacement struct {
+	PullRequestID string `json:"pull_request_id"`
+	Repository    string `json:"repository,omitempty"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+// DeactivationResult reports users switched off in bulk and the PRs touched.
+type DeactivationResult struct {
+	Deactivated   []string              `json:"deactivated"`
+	Reassignments []ReviewerReplacement `json:"reassignments"`
+}
+
+// Unavailability is a period, such as a vacation, during which the user is
+// not picked as a reviewer. EndsAt is exclusive.
+type Unavailability struct {
+	ID        int64     `json:"period_id"`
+	UserID    string    `json:"user_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MembershipChange reports a team after its members were edited and the
+// reviews handed over as a result.
+type MembershipChange struct {
+	Team          Team                  `json:"team"`
+	Reassignments []ReviewerReplacement `json:"reassignments"`
+}
+
+// Audit event reasons.
+const (
+	AuditAssigned            = "ASSIGNED"
+	AuditReassigned          = "REASSIGNED"
+	AuditReviewerDeactivated = "REVIEWER_DEACTIVATED"
+	AuditReviewerUnavailable = "REVIEWER_UNAVAILABLE"
+	AuditTeamChanged         = "TEAM_CHANGED"
+	AuditReadyForReview      = "READY_FOR_REVIEW"
+	AuditMerged              = "MERGED"
+	AuditClosed              = "CLOSED"
+	AuditReopened            = "REOPENED"
+	AuditUserActivated       = "USER_ACTIVATED"
+	AuditUserDeactivated     = "USER_DEACTIVATED"
+)
+
+// AuditEvent is an append-only record of a change to reviewer assignments,
+// pull request status or user activity. Reviewer events carry the old and/or
+// new reviewer, status events only the PR and activity events only the user.
+type AuditEvent struct {
+	ID            int64     `json:"id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Actor         string    `json:"actor"`
+	Reason        string    `json:"reason"`
+	PullRequestID string    `json:"pull_request_id,omitempty"`
+	Repository    string    `json:"repository,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	OldReviewerID string    `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string    `json:"new_reviewer_id,omitempty"`
+}
+
+// Webhook event types.
+const (
+	WebhookReviewerAssigned   = "reviewer.assigned"
+	WebhookReviewerReassigned = "reviewer.reassigned"
+	WebhookPullRequestMerged  = "pull_request.merged"
+)
+
+// WebhookEventTypes lists every event type a webhook may subscribe to.
+var WebhookEventTypes = []string{WebhookReviewerAssigned, WebhookReviewerReassigned, WebhookPullRequestMerged}
+
+// WebhookEventFor returns the webhook event type audit events with the given
+// reason are published as, or an empty string when they are not published.
+func WebhookEventFor(reason string) string {
+	switch reason {
+	case AuditAssigned:
+		return WebhookReviewerAssigned
+	case AuditReassigned, AuditReviewerDeactivated, AuditReviewerUnavailable, AuditTeamChanged:
+		return WebhookReviewerReassigned
+	case AuditMerged:
+		return WebhookPullRequestMerged
+	}
+	return ""
+}
+
+// Webhook is a subscriber endpoint. Payloads are signed with Secret, which is
+// never returned by the API.
+type Webhook struct {
+	ID         int64     `json:"webhook_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body posted to webhooks: the audit event that
+// triggered the delivery tagged with its webhook event type.
+type WebhookPayload struct {
+	Event string `json:"event"`
+	AuditEvent
+}
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryDead      = "DEAD"
+)
+
+// WebhookDelivery is an outbox entry: one payload due for one webhook.
+type WebhookDelivery struct {
+	ID            int64      `json:"delivery_id"`
+	WebhookID     int64      `json:"webhook_id"`
+	URL           string     `json:"-"`
+	Secret        string     `json:"-"`
+	EventType     string     `json:"event"`
+	Payload       []byte     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+}
+
+// API key roles. An admin may call every endpoint; a user may work with pull
+// requests and read data.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// APIKey identifies a client of the API. Only a hash of its token is stored;
+// the token itself is shown once, when the key is created.
+type APIKey struct {
+	ID        int64      `json:"key_id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// Allows reports whether the key's role grants the given role.
+func (k APIKey) Allows(role string) bool {
+	return k.Role == RoleAdmin || k.Role == role
+}
+
+// Code hosting platforms whose pull request events the service ingests.
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+)
+
+// Forges lists the platforms logins may be linked for.
+var Forges = []string{ForgeGitHub, ForgeGitLab}
+
+// ForgeAccount links a login on a code hosting platform to a user.
+type ForgeAccount struct {
+	Forge  string `json:"forge"`
+	Login  string `json:"login"`
+	UserID string `json:"user_id"`
+}
+
+// Repository is a code repository pull requests are opened in. Teams lists
+// the teams working in it.
+type Repository struct {
+	Name      string    `json:"name"`
+	Teams     []string  `json:"teams"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OwnershipRule requires a reviewer from the active members of TeamName, or
+// from UserIDs, on every pull request of Repository that changes a file
+// matching the CODEOWNERS-style Pattern. Exactly one of TeamName and UserIDs
+// is set.
+type OwnershipRule struct {
+	ID         int64     `json:"rule_id"`
+	Repository string    `json:"repository"`
+	Pattern    string    `json:"pattern"`
+	TeamName   string    `json:"team_name,omitempty"`
+	UserIDs    []string  `json:"user_ids,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyContextKey = "api_key"
+
+// bootstrapKey is the identity of requests made with the admin token.
+var bootstrapKey = domain.APIKey{Name: "admin", Role: domain.RoleAdmin}
+
+// authenticate rejects requests without a valid "Authorization: Bearer" token
+// and stores the caller's key in the gin context. The key name becomes the
+// audit actor.
+func authenticate(svc *service.Service, adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		token = strings.TrimSpace(token)
+		if !ok || token == "" {
+			abortWithError(c, domain.NewUnauthorizedError())
+			return
+		}
+
+		key := bootstrapKey
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			var err error
+			if key, err = svc.Authenticate(c.Request.Context(), token); err != nil {
+				abortWithError(c, err)
+				return
+			}
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Request = c.Request.WithContext(service.WithActor(c.Request.Context(), key.Name))
+		c.Next()
+	}
+}
+
+// requireRole lets through callers whose key grants role.
+func requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, _ := c.Get(apiKeyContextKey)
+		if apiKey, ok := key.(domain.APIKey); !ok || !apiKey.Allows(role) {
+			abortWithError(c, domain.NewForbiddenError())
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortWithError(c *gin.Context, err error) {
+	respondError(c, err)
+	c.Abort()
+}
@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestServer(t *testing.T) (*gin.Engine, *service.Service) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	svc := service.New(memory.New(), nil)
+	return NewServer(svc, WithAdminToken("root")), svc
+}
+
+func do(engine *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) domain.ErrorCode {
+	t.Helper()
+	var resp struct {
+		Error struct {
+			Code domain.ErrorCode `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return resp.Error.Code
+}
+
+func TestAuthRejectsMissingAndUnknownTokens(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	if rec := do(engine, nethttp.MethodGet, "/health", "", ""); rec.Code != nethttp.StatusOK {
+		t.Fatalf("/health without token: %d", rec.Code)
+	}
+	for _, token := range []string{"", "unknown"} {
+		rec := do(engine, nethttp.MethodGet, "/team/get?team_name=backend", token, "")
+		if rec.Code != nethttp.StatusUnauthorized || errorCode(t, rec) != domain.ErrCodeUnauthorized {
+			t.Fatalf("token %q: got %d %s", token, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestAuthRoles(t *testing.T) {
+	engine, svc := newTestServer(t)
+	_, userToken, err := svc.CreateAPIKey(context.Background(), "ci", domain.RoleUser)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	team := `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true}]}`
+	rec := do(engine, nethttp.MethodPost, "/team/add", userToken, team)
+	if rec.Code != nethttp.StatusForbidden || errorCode(t, rec) != domain.ErrCodeForbidden {
+		t.Fatalf("user creating a team: got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("admin creating a team: got %d %s", rec.Code, rec.Body)
+	}
+
+	req := httptest.NewRequest(nethttp.MethodPost, "/pullRequest/create", strings.NewReader(`{"pull_request_id":"pr-1","pull_request_name":"PR","author_id":"u1"}`))
+	req.Header.Set("Authorization", "Bearer "+userToken)
+	req.Header.Set("X-Actor", "mallory")
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != nethttp.StatusCreated {
+		t.Fatalf("user creating a PR: got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodGet, "/users/getReview?user_id=u2", userToken, ""); rec.Code != nethttp.StatusOK {
+		t.Fatalf("user reading reviews: got %d %s", rec.Code, rec.Body)
+	}
+	for _, call := range []struct{ method, path, body string }{
+		{nethttp.MethodPost, "/users/setIsActive", `{"user_id":"u2","is_active":false}`},
+		{nethttp.MethodPost, "/pullRequest/close", `{"pull_request_id":"pr-1"}`},
+		{nethttp.MethodPost, "/pullRequest/reopen", `{"pull_request_id":"pr-1"}`},
+		{nethttp.MethodPost, "/pullRequest/ready", `{"pull_request_id":"pr-1"}`},
+		{nethttp.MethodGet, "/stats/reviewers", ""},
+	} {
+		if rec := do(engine, call.method, call.path, userToken, call.body); rec.Code != nethttp.StatusForbidden {
+			t.Fatalf("user calling %s: got %d %s", call.path, rec.Code, rec.Body)
+		}
+	}
+
+	events, err := svc.GetPullRequestHistory(context.Background(), "", "pr-1")
+	if err != nil || len(events) == 0 || events[0].Actor != "ci" {
+		t.Fatalf("key name must be recorded as actor whatever X-Actor says: %+v, %v", events, err)
+	}
+}
+
+func TestAuthKeyLifecycle(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	rec := do(engine, nethttp.MethodPost, "/apiKey/create", "root", `{"name":"ops","role":"admin"}`)
+	if rec.Code != nethttp.StatusCreated {
+		t.Fatalf("create key: got %d %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		APIKey domain.APIKey `json:"api_key"`
+		Token  string        `json:"token"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.Token == "" {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+
+	if rec := do(engine, nethttp.MethodGet, "/apiKey/list", created.Token, ""); rec.Code != nethttp.StatusOK || strings.Contains(rec.Body.String(), created.Token) {
+		t.Fatalf("list keys: got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/apiKey/revoke", "root", `{"key_id":`+strconv.FormatInt(created.APIKey.ID, 10)+`}`); rec.Code != nethttp.StatusOK {
+		t.Fatalf("revoke key: got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodGet, "/apiKey/list", created.Token, ""); rec.Code != nethttp.StatusUnauthorized {
+		t.Fatalf("revoked key still accepted: got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/apiKey/create", "root", `{"name":"x","role":"owner"}`); rec.Code != nethttp.StatusBadRequest {
+		t.Fatalf("unknown role accepted: got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"errors"
+	nethttp "net/http"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type unavailabilityRequest struct {
+	UserID   string     `json:"user_id" binding:"required"`
+	StartsAt *time.Time `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at" binding:"required"`
+	Reason   string     `json:"reason"`
+}
+
+type unavailabilityIDRequest struct {
+	PeriodID int64 `json:"period_id" binding:"required"`
+}
+
+func (h handler) createUnavailability(c *gin.Context) {
+	var req unavailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if !req.EndsAt.After(*req.StartsAt) {
+		respondValidationError(c, errors.New("ends_at must be after starts_at"))
+		return
+	}
+	period, err := h.svc.CreateUnavailability(c.Request.Context(), domain.Unavailability{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt.UTC(),
+		EndsAt:   req.EndsAt.UTC(),
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusCreated, gin.H{"period": period})
+}
+
+func (h handler) listUnavailability(c *gin.Context) {
+	periods, err := h.svc.ListUnavailability(c.Request.Context(), c.Query("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"periods": periods})
+}
+
+func (h handler) deleteUnavailability(c *gin.Context) {
+	var req unavailabilityIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := h.svc.DeleteUnavailability(c.Request.Context(), req.PeriodID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"period_id": req.PeriodID})
+}
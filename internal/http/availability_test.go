@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+func TestUnavailableUsersAreNotPicked(t *testing.T) {
+	engine, _ := newTestServer(t)
+	team := `{"team_name":"web","required_reviewers":1,"members":[{"user_id":"a1","username":"Ann","is_active":true},{"user_id":"b1","username":"Bob","is_active":true},{"user_id":"c1","username":"Cid","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+
+	now := time.Now().UTC()
+	period := func(userID string, from, to time.Time) string {
+		return fmt.Sprintf(`{"user_id":%q,"starts_at":%q,"ends_at":%q,"reason":"vacation"}`, userID, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	for name, body := range map[string]string{
+		"no end":   `{"user_id":"b1","starts_at":"2030-01-01T00:00:00Z"}`,
+		"reversed": period("b1", now.Add(time.Hour), now),
+	} {
+		if rec := do(engine, nethttp.MethodPost, "/users/availability/add", "root", body); rec.Code != nethttp.StatusBadRequest {
+			t.Errorf("%s: got %d %s", name, rec.Code, rec.Body)
+		}
+	}
+	if rec := do(engine, nethttp.MethodPost, "/users/availability/add", "root", period("ghost", now, now.Add(time.Hour))); rec.Code != nethttp.StatusNotFound {
+		t.Fatalf("unknown user: %d %s", rec.Code, rec.Body)
+	}
+	rec := do(engine, nethttp.MethodPost, "/users/availability/add", "root", period("b1", now.Add(-time.Hour), now.Add(time.Hour)))
+	var created struct {
+		Period domain.Unavailability `json:"period"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); rec.Code != nethttp.StatusCreated || err != nil || created.Period.ID == 0 {
+		t.Fatalf("users/availability/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for i := 1; i <= 5; i++ {
+		body := fmt.Sprintf(`{"pull_request_id":"pr-%d","pull_request_name":"UI","author_id":"a1"}`, i)
+		rec = do(engine, nethttp.MethodPost, "/pullRequest/create", "root", body)
+		var pr struct {
+			PR domain.PullRequest `json:"pr"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &pr); err != nil || !slices.Equal(pr.PR.Assigned, []string{"c1"}) {
+			t.Fatalf("an unavailable user was picked: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec = do(engine, nethttp.MethodGet, "/users/availability/list?user_id=b1", "root", "")
+	var list struct {
+		Periods []domain.Unavailability `json:"periods"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Periods) != 1 || list.Periods[0].Reason != "vacation" {
+		t.Fatalf("users/availability/list: %s %v", rec.Body, err)
+	}
+
+	body := fmt.Sprintf(`{"period_id":%d}`, created.Period.ID)
+	if rec := do(engine, nethttp.MethodPost, "/users/availability/delete", "root", body); rec.Code != nethttp.StatusOK {
+		t.Fatalf("users/availability/delete: %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/users/availability/delete", "root", body); rec.Code != nethttp.StatusNotFound {
+		t.Fatalf("deleting twice: %d %s", rec.Code, rec.Body)
+	}
+}
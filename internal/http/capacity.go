@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"errors"
+	nethttp "net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setMaxOpenReviewsRequest sets the cap of a user; a missing or null
+// max_open_reviews restores the team default.
+type setMaxOpenReviewsRequest struct {
+	UserID         string `json:"user_id" binding:"required"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+func (h handler) setUserMaxOpenReviews(c *gin.Context) {
+	var req setMaxOpenReviewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateMaxOpenReviews(req.MaxOpenReviews); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	user, err := h.svc.SetUserMaxOpenReviews(c.Request.Context(), req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"user": user})
+}
+
+func validateMaxOpenReviews(limit *int) error {
+	if limit != nil && *limit < 0 {
+		return errors.New("max_open_reviews must not be negative")
+	}
+	return nil
+}
+
+func valueOrZero(limit *int) int {
+	if limit == nil {
+		return 0
+	}
+	return *limit
+}
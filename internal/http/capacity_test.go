@@ -0,0 +1,133 @@
+package transport
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"slices"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+func TestOpenReviewCap(t *testing.T) {
+	engine, _ := newTestServer(t)
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", `{"team_name":"web","max_open_reviews":-1,"members":[]}`); rec.Code != nethttp.StatusBadRequest {
+		t.Fatalf("negative cap: %d %s", rec.Code, rec.Body)
+	}
+	team := `{"team_name":"web","required_reviewers":1,"min_reviewers":1,"max_open_reviews":1,"members":[{"user_id":"a1","username":"Ann","is_active":true},{"user_id":"b1","username":"Bob","is_active":true},{"user_id":"c1","username":"Cid","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for _, body := range []string{
+		`{"pull_request_id":"pr-1","pull_request_name":"UI","author_id":"a1"}`,
+		`{"pull_request_id":"pr-2","pull_request_name":"UI","author_id":"a1"}`,
+	} {
+		if rec := do(engine, nethttp.MethodPost, "/pullRequest/create", "root", body); rec.Code != nethttp.StatusCreated {
+			t.Fatalf("pullRequest/create: %d %s", rec.Code, rec.Body)
+		}
+	}
+	third := `{"pull_request_id":"pr-3","pull_request_name":"UI","author_id":"a1"}`
+	rec := do(engine, nethttp.MethodPost, "/pullRequest/create", "root", third)
+	if rec.Code != nethttp.StatusConflict || errorCode(t, rec) != domain.ErrCodeCapReached {
+		t.Fatalf("everyone at the cap: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(engine, nethttp.MethodGet, "/team/get?team_name=web", "root", "")
+	var got domain.Team
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.MaxOpenReviews != 1 {
+		t.Fatalf("team/get: %s %v", rec.Body, err)
+	}
+	for _, member := range got.Members {
+		if want := map[string]int{"b1": 1, "c1": 1}[member.UserID]; member.OpenReviews != want {
+			t.Fatalf("%s: got %d open reviews, want %d", member.UserID, member.OpenReviews, want)
+		}
+	}
+
+	if rec := do(engine, nethttp.MethodPost, "/users/setMaxOpenReviews", "root", `{"user_id":"b1","max_open_reviews":-2}`); rec.Code != nethttp.StatusBadRequest {
+		t.Fatalf("negative user cap: %d %s", rec.Code, rec.Body)
+	}
+	rec = do(engine, nethttp.MethodPost, "/users/setMaxOpenReviews", "root", `{"user_id":"b1","max_open_reviews":2}`)
+	var user struct {
+		User domain.User `json:"user"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &user); rec.Code != nethttp.StatusOK || err != nil || user.User.MaxOpenReviews == nil || *user.User.MaxOpenReviews != 2 {
+		t.Fatalf("users/setMaxOpenReviews: %d %s", rec.Code, rec.Body)
+	}
+	rec = do(engine, nethttp.MethodPost, "/pullRequest/create", "root", third)
+	var pr struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &pr); err != nil || !slices.Equal(pr.PR.Assigned, []string{"b1"}) {
+		t.Fatalf("raised cap ignored: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCreateReportsReviewersSkippedAtCap(t *testing.T) {
+	engine, _ := newTestServer(t)
+	team := `{"team_name":"web","required_reviewers":2,"max_open_reviews":1,"members":[{"user_id":"a1","username":"Ann","is_active":true},{"user_id":"b1","username":"Bob","is_active":true},{"user_id":"c1","username":"Cid","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for _, tc := range []struct {
+		id       string
+		assigned []string
+		skipped  []string
+	}{
+		{"pr-1", []string{"b1", "c1"}, nil},
+		{"pr-2", nil, []string{"b1", "c1"}},
+	} {
+		body := `{"pull_request_id":"` + tc.id + `","pull_request_name":"UI","author_id":"a1"}`
+		rec := do(engine, nethttp.MethodPost, "/pullRequest/create", "root", body)
+		var got struct {
+			PR struct {
+				Assigned     []string `json:"assigned_reviewers"`
+				SkippedAtCap []string `json:"skipped_at_cap"`
+			} `json:"pr"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != nethttp.StatusCreated || err != nil {
+			t.Fatalf("%s: %d %s", tc.id, rec.Code, rec.Body)
+		}
+		if !slices.Equal(got.PR.Assigned, tc.assigned) || !slices.Equal(got.PR.SkippedAtCap, tc.skipped) {
+			t.Fatalf("%s: %s", tc.id, rec.Body)
+		}
+	}
+}
+
+func TestUpdateTeamKeepsOmittedSettings(t *testing.T) {
+	engine, _ := newTestServer(t)
+	team := `{"team_name":"web","required_reviewers":3,"min_reviewers":1,"max_open_reviews":4,"members":[]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for _, tc := range []struct {
+		body string
+		want domain.Team
+	}{
+		{`{"team_name":"web","max_open_reviews":6}`, domain.Team{RequiredReviewers: 3, MinReviewers: 1, MaxOpenReviews: 6}},
+		{`{"team_name":"web","min_reviewers":2}`, domain.Team{RequiredReviewers: 3, MinReviewers: 2, MaxOpenReviews: 6}},
+		{`{"team_name":"web","required_reviewers":4}`, domain.Team{RequiredReviewers: 4, MinReviewers: 2, MaxOpenReviews: 6}},
+	} {
+		rec := do(engine, nethttp.MethodPost, "/team/update", "root", tc.body)
+		var got struct {
+			Team domain.Team `json:"team"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != nethttp.StatusOK || err != nil {
+			t.Fatalf("%s: %d %s", tc.body, rec.Code, rec.Body)
+		}
+		if got.Team.RequiredReviewers != tc.want.RequiredReviewers || got.Team.MinReviewers != tc.want.MinReviewers || got.Team.MaxOpenReviews != tc.want.MaxOpenReviews {
+			t.Fatalf("%s: got %+v", tc.body, got.Team)
+		}
+	}
+
+	rec := do(engine, nethttp.MethodPost, "/team/update", "root", `{"team_name":"web","required_reviewers":1}`)
+	if rec.Code != nethttp.StatusBadRequest {
+		t.Fatalf("required below the stored minimum: %d %s", rec.Code, rec.Body)
+	}
+	rec = do(engine, nethttp.MethodPost, "/team/update", "root", `{"team_name":"ghost","max_open_reviews":1}`)
+	if rec.Code != nethttp.StatusNotFound {
+		t.Fatalf("unknown team: %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,50 @@
+// Package forge defines how pull request webhooks of code hosting platforms
+// reach the service. Each platform has an Adapter in its own subpackage; the
+// HTTP layer serves every configured adapter at /forge/<name>.
+package forge
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Adapter verifies and parses webhooks of one platform.
+type Adapter interface {
+	// Forge names the platform; it is also the forge of linked accounts.
+	Forge() string
+	// Parse authenticates the request and maps it to an Event. Requests that
+	// need no action yield an Event with ActionIgnore.
+	Parse(header http.Header, body []byte) (Event, error)
+}
+
+// Actions a platform event maps to.
+const (
+	ActionIgnore = ""
+	ActionCreate = "create"
+	ActionMerge  = "merge"
+	ActionClose  = "close"
+	ActionReopen = "reopen"
+	ActionReady  = "ready"
+)
+
+// Event is a platform pull request event reduced to what the service needs.
+// PullRequestID is unique across repositories of the platform and Repository
+// is the platform name of its repository. AuthorLogin is only needed for
+// ActionCreate.
+type Event struct {
+	Action        string
+	Repository    string
+	PullRequestID string
+	Title         string
+	AuthorLogin   string
+	Draft         bool
+	Sender        string
+}
+
+var (
+	// ErrInvalidSignature is returned when a request does not carry the
+	// configured secret or a signature made with it.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrMalformedPayload is returned for payloads missing required fields.
+	ErrMalformedPayload = errors.New("malformed webhook payload")
+)
@@ -0,0 +1,114 @@
+// Package github adapts GitHub pull_request webhooks.
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge"
+)
+
+// GitHub request headers.
+const (
+	EventHeader     = "X-GitHub-Event"
+	SignatureHeader = "X-Hub-Signature-256"
+)
+
+// Adapter accepts webhooks signed with a shared secret.
+type Adapter struct {
+	secret string
+}
+
+var _ forge.Adapter = (*Adapter)(nil)
+
+// New returns an adapter verifying payloads with secret.
+func New(secret string) *Adapter {
+	return &Adapter{secret: secret}
+}
+
+func (a *Adapter) Forge() string {
+	return domain.ForgeGitHub
+}
+
+type payload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// Parse verifies the X-Hub-Signature-256 header and maps a pull_request event.
+// Other events, such as ping, and pull_request actions without a counterpart
+// are returned with ActionIgnore. Pull requests are identified as
+// "owner/repo#number".
+func (a *Adapter) Parse(header http.Header, body []byte) (forge.Event, error) {
+	if !validSignature(header.Get(SignatureHeader), body, a.secret) {
+		return forge.Event{}, forge.ErrInvalidSignature
+	}
+	if header.Get(EventHeader) != "pull_request" {
+		return forge.Event{}, nil
+	}
+
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return forge.Event{}, forge.ErrMalformedPayload
+	}
+	if p.Number == 0 || p.Repository.FullName == "" || p.PullRequest.User.Login == "" {
+		return forge.Event{}, forge.ErrMalformedPayload
+	}
+
+	event := forge.Event{
+		Repository:    p.Repository.FullName,
+		PullRequestID: p.Repository.FullName + "#" + strconv.Itoa(p.Number),
+		Title:         p.PullRequest.Title,
+		AuthorLogin:   p.PullRequest.User.Login,
+		Draft:         p.PullRequest.Draft,
+		Sender:        p.Sender.Login,
+	}
+	switch p.Action {
+	case "opened":
+		event.Action = forge.ActionCreate
+	case "closed":
+		event.Action = forge.ActionClose
+		if p.PullRequest.Merged {
+			event.Action = forge.ActionMerge
+		}
+	case "reopened":
+		event.Action = forge.ActionReopen
+	case "ready_for_review":
+		event.Action = forge.ActionReady
+	}
+	return event, nil
+}
+
+func validSignature(signature string, body []byte, secret string) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge"
+)
+
+const testSecret = "github-secret"
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func signedHeader(event string, body []byte, secret string) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	h := http.Header{}
+	h.Set(EventHeader, event)
+	h.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return h
+}
+
+func TestParsePullRequestEvents(t *testing.T) {
+	cases := []struct {
+		fixture string
+		action  string
+		draft   bool
+		sender  string
+	}{
+		{"pull_request_opened.json", forge.ActionCreate, false, "Alice-Dev"},
+		{"pull_request_opened_draft.json", forge.ActionCreate, true, "Alice-Dev"},
+		{"pull_request_closed_merged.json", forge.ActionMerge, false, "bob-ops"},
+		{"pull_request_closed.json", forge.ActionClose, false, "bob-ops"},
+		{"pull_request_reopened.json", forge.ActionReopen, false, "Alice-Dev"},
+		{"pull_request_labeled.json", forge.ActionIgnore, false, "Alice-Dev"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			body := fixture(t, tc.fixture)
+			event, err := New(testSecret).Parse(signedHeader("pull_request", body, testSecret), body)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			want := forge.Event{
+				Action:        tc.action,
+				Repository:    "octo-org/reviewer",
+				PullRequestID: "octo-org/reviewer#42",
+				Title:         "Add search endpoint",
+				AuthorLogin:   "Alice-Dev",
+				Draft:         tc.draft,
+				Sender:        tc.sender,
+			}
+			if event != want {
+				t.Fatalf("got %+v, want %+v", event, want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoresOtherEvents(t *testing.T) {
+	body := fixture(t, "ping.json")
+	event, err := New(testSecret).Parse(signedHeader("ping", body, testSecret), body)
+	if err != nil || event.Action != forge.ActionIgnore {
+		t.Fatalf("Parse(ping) = %+v, %v", event, err)
+	}
+}
+
+func TestParseRejectsBadSignatures(t *testing.T) {
+	body := fixture(t, "pull_request_opened.json")
+
+	headers := map[string]http.Header{
+		"wrong secret": signedHeader("pull_request", body, "other"),
+		"missing":      {EventHeader: []string{"pull_request"}},
+		"not hex":      {EventHeader: []string{"pull_request"}, SignatureHeader: []string{"sha256=zz"}},
+		"sha1":         {EventHeader: []string{"pull_request"}, SignatureHeader: []string{"sha1=0a1b"}},
+	}
+	for name, header := range headers {
+		if _, err := New(testSecret).Parse(header, body); !errors.Is(err, forge.ErrInvalidSignature) {
+			t.Fatalf("%s: got %v, want ErrInvalidSignature", name, err)
+		}
+	}
+
+	tampered := append([]byte(nil), body...)
+	tampered[len(tampered)-3] = ' '
+	if _, err := New(testSecret).Parse(signedHeader("pull_request", body, testSecret), tampered); !errors.Is(err, forge.ErrInvalidSignature) {
+		t.Fatalf("tampered body: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestParseRejectsMalformedPayloads(t *testing.T) {
+	for _, body := range [][]byte{[]byte(`not json`), []byte(`{"action":"opened","number":1}`)} {
+		if _, err := New(testSecret).Parse(signedHeader("pull_request", body, testSecret), body); !errors.Is(err, forge.ErrMalformedPayload) {
+			t.Fatalf("%s: got %v, want ErrMalformedPayload", body, err)
+		}
+	}
+}
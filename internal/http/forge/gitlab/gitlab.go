@@ -0,0 +1,160 @@
+// Package gitlab adapts GitLab merge request webhooks.
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge"
+)
+
+// GitLab request headers.
+const (
+	EventHeader = "X-Gitlab-Event"
+	TokenHeader = "X-Gitlab-Token"
+)
+
+// MergeRequestEvent is the X-Gitlab-Event value of merge request webhooks.
+const MergeRequestEvent = "Merge Request Hook"
+
+// Adapter accepts webhooks carrying a shared secret token.
+type Adapter struct {
+	token string
+}
+
+var _ forge.Adapter = (*Adapter)(nil)
+
+// New returns an adapter expecting token in the X-Gitlab-Token header.
+func New(token string) *Adapter {
+	return &Adapter{token: token}
+}
+
+func (a *Adapter) Forge() string {
+	return domain.ForgeGitLab
+}
+
+type payload struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID            int    `json:"iid"`
+		AuthorID       int    `json:"author_id"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          *bool  `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft          *boolChange   `json:"draft"`
+		WorkInProgress *boolChange   `json:"work_in_progress"`
+		Title          *stringChange `json:"title"`
+	} `json:"changes"`
+}
+
+type boolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+type stringChange struct {
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// Parse verifies the X-Gitlab-Token header and maps a merge request event.
+// Other events and actions without a counterpart, such as approvals or plain
+// edits, are returned with ActionIgnore. Merge requests are identified as
+// "group/project!iid". The author of an open event is the numeric GitLab user
+// id from author_id, since the event user is whoever triggered the hook.
+func (a *Adapter) Parse(header http.Header, body []byte) (forge.Event, error) {
+	if a.token == "" || subtle.ConstantTimeCompare([]byte(header.Get(TokenHeader)), []byte(a.token)) != 1 {
+		return forge.Event{}, forge.ErrInvalidSignature
+	}
+	if header.Get(EventHeader) != MergeRequestEvent {
+		return forge.Event{}, nil
+	}
+
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return forge.Event{}, forge.ErrMalformedPayload
+	}
+	if p.ObjectKind != "merge_request" {
+		return forge.Event{}, nil
+	}
+	attrs := p.ObjectAttributes
+	if attrs.IID == 0 || p.Project.PathWithNamespace == "" || p.User.Username == "" {
+		return forge.Event{}, forge.ErrMalformedPayload
+	}
+
+	event := forge.Event{
+		Repository:    p.Project.PathWithNamespace,
+		PullRequestID: p.Project.PathWithNamespace + "!" + strconv.Itoa(attrs.IID),
+		Title:         attrs.Title,
+		Draft:         isDraft(attrs.Draft, attrs.WorkInProgress, attrs.Title),
+		Sender:        p.User.Username,
+	}
+	switch attrs.Action {
+	case "open":
+		if attrs.AuthorID == 0 {
+			return forge.Event{}, forge.ErrMalformedPayload
+		}
+		event.Action = forge.ActionCreate
+		event.AuthorLogin = strconv.Itoa(attrs.AuthorID)
+	case "merge":
+		event.Action = forge.ActionMerge
+	case "close":
+		event.Action = forge.ActionClose
+	case "reopen":
+		event.Action = forge.ActionReopen
+	case "update":
+		if !event.Draft && leftDraft(p) {
+			event.Action = forge.ActionReady
+		}
+	}
+	return event, nil
+}
+
+// isDraft prefers the draft attribute and falls back to work_in_progress and
+// the title prefix for payloads of older GitLab versions.
+func isDraft(draft *bool, workInProgress bool, title string) bool {
+	if draft != nil {
+		return *draft
+	}
+	return workInProgress || draftTitle(title)
+}
+
+// leftDraft reports whether an update event marked the merge request ready.
+func leftDraft(p payload) bool {
+	changes := p.Changes
+	switch {
+	case changes.Draft != nil:
+		return changes.Draft.Previous && !changes.Draft.Current
+	case changes.WorkInProgress != nil:
+		return changes.WorkInProgress.Previous && !changes.WorkInProgress.Current
+	case changes.Title != nil:
+		return draftTitle(changes.Title.Previous) && !draftTitle(changes.Title.Current)
+	}
+	return false
+}
+
+// draftPrefixes are the title prefixes GitLab treats as marking a draft.
+var draftPrefixes = []string{"draft:", "[draft]", "(draft)", "wip:", "[wip]"}
+
+func draftTitle(title string) bool {
+	title = strings.ToLower(strings.TrimSpace(title))
+	for _, prefix := range draftPrefixes {
+		if strings.HasPrefix(title, prefix) {
+			return true
+		}
+	}
+	return false
+}
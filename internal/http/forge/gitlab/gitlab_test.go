@@ -0,0 +1,127 @@
+package gitlab
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge"
+)
+
+const testToken = "gitlab-token"
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func tokenHeader(event, token string) http.Header {
+	h := http.Header{}
+	h.Set(EventHeader, event)
+	h.Set(TokenHeader, token)
+	return h
+}
+
+func TestParseMergeRequestEvents(t *testing.T) {
+	cases := []struct {
+		fixture string
+		action  string
+		title   string
+		author  string
+		draft   bool
+		sender  string
+	}{
+		{"merge_request_open.json", forge.ActionCreate, "Add search endpoint", "51", false, "alice.dev"},
+		{"merge_request_open_by_bot.json", forge.ActionCreate, "Add search endpoint", "51", false, "release-bot"},
+		{"merge_request_open_draft.json", forge.ActionCreate, "Draft: Add search endpoint", "51", true, "alice.dev"},
+		{"merge_request_open_wip.json", forge.ActionCreate, "WIP: Add search endpoint", "51", true, "alice.dev"},
+		{"merge_request_update_ready.json", forge.ActionReady, "Add search endpoint", "", false, "alice.dev"},
+		{"merge_request_update_title.json", forge.ActionIgnore, "Add search endpoint v2", "", false, "alice.dev"},
+		{"merge_request_approved.json", forge.ActionIgnore, "Add search endpoint", "", false, "bob.ops"},
+		{"merge_request_merge.json", forge.ActionMerge, "Add search endpoint", "", false, "bob.ops"},
+		{"merge_request_close.json", forge.ActionClose, "Add search endpoint", "", false, "bob.ops"},
+		{"merge_request_reopen.json", forge.ActionReopen, "Add search endpoint", "", false, "bob.ops"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			body := fixture(t, tc.fixture)
+			event, err := New(testToken).Parse(tokenHeader(MergeRequestEvent, testToken), body)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			want := forge.Event{
+				Action:        tc.action,
+				Repository:    "platform/reviewer",
+				PullRequestID: "platform/reviewer!17",
+				Title:         tc.title,
+				AuthorLogin:   tc.author,
+				Draft:         tc.draft,
+				Sender:        tc.sender,
+			}
+			if event != want {
+				t.Fatalf("got %+v, want %+v", event, want)
+			}
+		})
+	}
+}
+
+func TestDraftTitle(t *testing.T) {
+	cases := map[string]bool{
+		"Draft: search":    true,
+		"  [Draft] search": true,
+		"(draft) search":   true,
+		"WIP: search":      true,
+		"[WIP] search":     true,
+		"Drafting search":  false,
+		"Fix WIP: search":  false,
+	}
+	for title, want := range cases {
+		if got := draftTitle(title); got != want {
+			t.Fatalf("draftTitle(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
+
+func TestParseIgnoresOtherEvents(t *testing.T) {
+	body := []byte(`{"object_kind":"push","ref":"refs/heads/main"}`)
+	event, err := New(testToken).Parse(tokenHeader("Push Hook", testToken), body)
+	if err != nil || event.Action != forge.ActionIgnore {
+		t.Fatalf("Parse(push) = %+v, %v", event, err)
+	}
+}
+
+func TestParseRejectsBadTokens(t *testing.T) {
+	body := fixture(t, "merge_request_open.json")
+
+	headers := map[string]http.Header{
+		"wrong token": tokenHeader(MergeRequestEvent, "other"),
+		"missing":     {EventHeader: []string{MergeRequestEvent}},
+	}
+	for name, header := range headers {
+		if _, err := New(testToken).Parse(header, body); !errors.Is(err, forge.ErrInvalidSignature) {
+			t.Fatalf("%s: got %v, want ErrInvalidSignature", name, err)
+		}
+	}
+	if _, err := New("").Parse(tokenHeader(MergeRequestEvent, ""), body); !errors.Is(err, forge.ErrInvalidSignature) {
+		t.Fatalf("empty token: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestParseRejectsMalformedPayloads(t *testing.T) {
+	bodies := [][]byte{
+		[]byte(`not json`),
+		[]byte(`{"object_kind":"merge_request","object_attributes":{"iid":1,"action":"open"}}`),
+		[]byte(`{"object_kind":"merge_request","user":{"username":"bot"},"project":{"path_with_namespace":"platform/reviewer"},"object_attributes":{"iid":1,"action":"open"}}`),
+	}
+	for _, body := range bodies {
+		if _, err := New(testToken).Parse(tokenHeader(MergeRequestEvent, testToken), body); !errors.Is(err, forge.ErrMalformedPayload) {
+			t.Fatalf("%s: got %v, want ErrMalformedPayload", body, err)
+		}
+	}
+}
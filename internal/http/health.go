@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"context"
+	nethttp "net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds each readiness check so that a hung dependency
+// fails the probe instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// Dependency statuses reported by /readyz.
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+type readinessCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+type dependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// live answers while the process can serve requests at all; it checks no
+// dependencies, so a database outage does not get the pod restarted.
+func live(c *gin.Context) {
+	c.JSON(nethttp.StatusOK, gin.H{"status": statusOK})
+}
+
+// ready runs every readiness check in order and answers 503 if any failed.
+func ready(checks []readinessCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overall, httpStatus := statusOK, nethttp.StatusOK
+		dependencies := make(map[string]dependencyStatus, len(checks))
+		for _, rc := range checks {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+			start := time.Now()
+			err := rc.check(ctx)
+			cancel()
+
+			dep := dependencyStatus{
+				Status:    statusOK,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				dep.Status, dep.Error = statusUnavailable, err.Error()
+				overall, httpStatus = statusUnavailable, nethttp.StatusServiceUnavailable
+			}
+			dependencies[rc.name] = dep
+		}
+		c.JSON(httpStatus, gin.H{"status": overall, "dependencies": dependencies})
+	}
+}
@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadinessReportsEveryDependency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var dbErr error
+	engine := NewServer(service.New(memory.New(), nil),
+		WithReadinessCheck("database", func(context.Context) error { return dbErr }),
+		WithReadinessCheck("migrations", func(context.Context) error { return nil }),
+	)
+
+	type readiness struct {
+		Status       string                      `json:"status"`
+		Dependencies map[string]dependencyStatus `json:"dependencies"`
+	}
+	probe := func(wantStatus int) readiness {
+		t.Helper()
+		rec := do(engine, nethttp.MethodGet, "/readyz", "", "")
+		if rec.Code != wantStatus {
+			t.Fatalf("/readyz: want %d, got %d %s", wantStatus, rec.Code, rec.Body)
+		}
+		var body readiness
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode /readyz: %v", err)
+		}
+		return body
+	}
+
+	body := probe(nethttp.StatusOK)
+	if body.Status != statusOK || len(body.Dependencies) != 2 || body.Dependencies["database"].Status != statusOK {
+		t.Fatalf("unexpected readiness %+v", body)
+	}
+
+	dbErr = errors.New("connection refused")
+	body = probe(nethttp.StatusServiceUnavailable)
+	database := body.Dependencies["database"]
+	if body.Status != statusUnavailable || database.Status != statusUnavailable || database.Error != "connection refused" {
+		t.Fatalf("unexpected readiness %+v", body)
+	}
+	if body.Dependencies["migrations"].Status != statusOK {
+		t.Fatalf("healthy dependency reported as %+v", body.Dependencies["migrations"])
+	}
+
+	for _, path := range []string{"/livez", "/health"} {
+		if rec := do(engine, nethttp.MethodGet, path, "", ""); rec.Code != nethttp.StatusOK {
+			t.Fatalf("%s must not depend on the database: %d %s", path, rec.Code, rec.Body)
+		}
+	}
+}
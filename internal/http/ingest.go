@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"slices"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody caps the size of accepted forge webhook payloads.
+const maxWebhookBody = 5 << 20
+
+type forgeAccountRequest struct {
+	Forge  string `json:"forge" binding:"required"`
+	Login  string `json:"login" binding:"required"`
+	UserID string `json:"user_id"`
+}
+
+func (h handler) forgeWebhook(adapter forge.Adapter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+		if err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		event, err := adapter.Parse(c.Request.Header, body)
+		if err != nil {
+			respondForgeError(c, err)
+			return
+		}
+		h.applyForgeEvent(c, adapter.Forge(), event)
+	}
+}
+
+// applyForgeEvent performs the pull request operation the event maps to on
+// behalf of "<forge>:<sender>". A redelivered opening event returns the PR
+// created the first time. Pull requests of a platform repository registered
+// under the same name live in it, the others in the default repository.
+func (h handler) applyForgeEvent(c *gin.Context, forgeName string, event forge.Event) {
+	if event.Action == forge.ActionIgnore {
+		c.JSON(nethttp.StatusOK, gin.H{"ignored": true})
+		return
+	}
+	ctx := service.WithActor(c.Request.Context(), forgeName+":"+event.Sender)
+
+	repository, err := h.forgeRepository(ctx, event.Repository)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	var pr domain.PullRequest
+	if event.Action == forge.ActionCreate {
+		pr, err = h.createForgePullRequest(ctx, forgeName, repository, event)
+	} else {
+		pr, err = h.changeForgePullRequest(ctx, repository, event)
+		// Pull requests opened before their repository was registered stay in
+		// the default one.
+		if isNotFound(err) && repository != "" {
+			pr, err = h.changeForgePullRequest(ctx, "", event)
+		}
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"action": event.Action, "pr": pr})
+}
+
+// forgeRepository returns name if it is a registered repository and the
+// default repository otherwise.
+func (h handler) forgeRepository(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	if _, err := h.svc.GetRepository(ctx, name); err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+func (h handler) changeForgePullRequest(ctx context.Context, repository string, event forge.Event) (domain.PullRequest, error) {
+	switch event.Action {
+	case forge.ActionMerge:
+		return h.svc.MergePullRequest(ctx, repository, event.PullRequestID)
+	case forge.ActionClose:
+		return h.svc.ClosePullRequest(ctx, repository, event.PullRequestID)
+	case forge.ActionReopen:
+		return h.svc.ReopenPullRequest(ctx, repository, event.PullRequestID)
+	default:
+		return h.svc.MarkReadyForReview(ctx, repository, event.PullRequestID)
+	}
+}
+
+func isNotFound(err error) bool {
+	var appErr *domain.AppError
+	return errors.As(err, &appErr) && appErr.Code == domain.ErrCodeNotFound
+}
+
+func (h handler) createForgePullRequest(ctx context.Context, forgeName, repository string, event forge.Event) (domain.PullRequest, error) {
+	authorID, err := h.svc.ResolveForgeLogin(ctx, forgeName, event.AuthorLogin)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	name := event.Title
+	if name == "" {
+		name = event.PullRequestID
+	}
+	pr, err := h.svc.CreatePullRequest(ctx, service.CreatePullRequestInput{
+		PullRequestID:   event.PullRequestID,
+		PullRequestName: name,
+		AuthorID:        authorID,
+		Draft:           event.Draft,
+		Repository:      repository,
+	})
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) && appErr.Code == domain.ErrCodePRExists {
+		return h.svc.GetPullRequest(ctx, repository, event.PullRequestID)
+	}
+	return pr, err
+}
+
+func respondForgeError(c *gin.Context, err error) {
+	if errors.Is(err, forge.ErrInvalidSignature) {
+		writeError(c, nethttp.StatusUnauthorized, domain.ErrCodeUnauthorized, err.Error())
+		return
+	}
+	respondValidationError(c, err)
+}
+
+func (h handler) linkForgeAccount(c *gin.Context) {
+	var req forgeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if req.UserID == "" {
+		respondValidationError(c, errors.New("user_id is required"))
+		return
+	}
+	if !slices.Contains(domain.Forges, req.Forge) {
+		respondValidationError(c, fmt.Errorf("unknown forge %q", req.Forge))
+		return
+	}
+	account, err := h.svc.LinkForgeAccount(c.Request.Context(), domain.ForgeAccount{Forge: req.Forge, Login: req.Login, UserID: req.UserID})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"account": account})
+}
+
+func (h handler) unlinkForgeAccount(c *gin.Context) {
+	var req forgeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := h.svc.UnlinkForgeAccount(c.Request.Context(), req.Forge, req.Login); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"forge": req.Forge, "login": req.Login})
+}
+
+func (h handler) listForgeAccounts(c *gin.Context) {
+	accounts, err := h.svc.ListForgeAccounts(c.Request.Context(), c.Query("forge"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"accounts": accounts})
+}
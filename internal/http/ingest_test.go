@@ -0,0 +1,246 @@
+package transport
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge/github"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge/gitlab"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	githubSecret = "github-secret"
+	gitlabToken  = "gitlab-token"
+)
+
+func newForgeServer(t *testing.T) (*gin.Engine, *service.Service) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	svc := service.New(memory.New(), nil)
+	engine := NewServer(svc, WithAdminToken("root"), WithForge(github.New(githubSecret)), WithForge(gitlab.New(gitlabToken)))
+
+	rec := do(engine, nethttp.MethodPost, "/team/add", "root",
+		`{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Carol","is_active":true}]}`)
+	if rec.Code != nethttp.StatusCreated {
+		t.Fatalf("create team: %d %s", rec.Code, rec.Body)
+	}
+	return engine, svc
+}
+
+func postGitHub(t *testing.T, engine *gin.Engine, event, fixture, secret string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("forge", "github", "testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	req := httptest.NewRequest(nethttp.MethodPost, "/forge/github", strings.NewReader(string(body)))
+	req.Header.Set(github.EventHeader, event)
+	req.Header.Set(github.SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func postGitLab(t *testing.T, engine *gin.Engine, fixture, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("forge", "gitlab", "testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	req := httptest.NewRequest(nethttp.MethodPost, "/forge/gitlab", strings.NewReader(string(body)))
+	req.Header.Set(gitlab.EventHeader, gitlab.MergeRequestEvent)
+	req.Header.Set(gitlab.TokenHeader, token)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func prStatus(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return resp.PR.Status
+}
+
+func TestGitHubWebhookDrivesPullRequestLifecycle(t *testing.T) {
+	engine, svc := newForgeServer(t)
+	if rec := do(engine, nethttp.MethodPost, "/forge/link", "root", `{"forge":"github","login":"alice-dev","user_id":"u1"}`); rec.Code != nethttp.StatusOK {
+		t.Fatalf("link account: %d %s", rec.Code, rec.Body)
+	}
+
+	rec := postGitHub(t, engine, "pull_request", "pull_request_opened.json", githubSecret)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusOpen {
+		t.Fatalf("opened: %d %s", rec.Code, rec.Body)
+	}
+	pr, err := svc.GetPullRequest(context.Background(), "", "octo-org/reviewer#42")
+	if err != nil || pr.AuthorID != "u1" || pr.PullRequestName != "Add search endpoint" || len(pr.Assigned) != 2 {
+		t.Fatalf("unexpected PR: %+v, %v", pr, err)
+	}
+
+	if rec := postGitHub(t, engine, "pull_request", "pull_request_opened.json", githubSecret); rec.Code != nethttp.StatusOK {
+		t.Fatalf("redelivered opened: %d %s", rec.Code, rec.Body)
+	}
+	if rec := postGitHub(t, engine, "pull_request", "pull_request_labeled.json", githubSecret); rec.Code != nethttp.StatusOK {
+		t.Fatalf("labeled: %d %s", rec.Code, rec.Body)
+	}
+	rec = postGitHub(t, engine, "pull_request", "pull_request_closed_merged.json", githubSecret)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusMerged {
+		t.Fatalf("merged: %d %s", rec.Code, rec.Body)
+	}
+
+	events, err := svc.GetPullRequestHistory(context.Background(), "", "octo-org/reviewer#42")
+	if err != nil || events[len(events)-1].Reason != domain.AuditMerged || events[len(events)-1].Actor != "github:bob-ops" {
+		t.Fatalf("unexpected history: %+v, %v", events, err)
+	}
+}
+
+func TestForgeWebhookUsesRegisteredRepository(t *testing.T) {
+	engine, svc := newForgeServer(t)
+	ctx := context.Background()
+	if _, err := svc.LinkForgeAccount(ctx, domain.ForgeAccount{Forge: domain.ForgeGitHub, Login: "alice-dev", UserID: "u1"}); err != nil {
+		t.Fatalf("LinkForgeAccount: %v", err)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", `{"team_name":"frontend","members":[]}`); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("create team: %d %s", rec.Code, rec.Body)
+	}
+	if _, err := svc.CreateRepository(ctx, domain.Repository{Name: "octo-org/reviewer", Teams: []string{"frontend"}}); err != nil {
+		t.Fatalf("CreateRepository: %v", err)
+	}
+
+	rec := postGitHub(t, engine, "pull_request", "pull_request_opened.json", githubSecret)
+	if rec.Code != nethttp.StatusConflict || errorCode(t, rec) != domain.ErrCodeTeamNotLinked {
+		t.Fatalf("author's team not linked: %d %s", rec.Code, rec.Body)
+	}
+
+	if _, err := svc.SetRepositoryTeams(ctx, "octo-org/reviewer", []string{"backend"}); err != nil {
+		t.Fatalf("SetRepositoryTeams: %v", err)
+	}
+	if rec := postGitHub(t, engine, "pull_request", "pull_request_opened.json", githubSecret); rec.Code != nethttp.StatusOK {
+		t.Fatalf("opened: %d %s", rec.Code, rec.Body)
+	}
+	if rec := postGitHub(t, engine, "pull_request", "pull_request_closed_merged.json", githubSecret); rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusMerged {
+		t.Fatalf("merged: %d %s", rec.Code, rec.Body)
+	}
+	pr, err := svc.GetPullRequest(ctx, "octo-org/reviewer", "octo-org/reviewer#42")
+	if err != nil || pr.Repository != "octo-org/reviewer" || pr.Status != domain.StatusMerged {
+		t.Fatalf("unexpected PR: %+v, %v", pr, err)
+	}
+	if _, err := svc.GetPullRequest(ctx, "", "octo-org/reviewer#42"); err == nil {
+		t.Fatalf("PR of a registered repository created in the default one")
+	}
+}
+
+func TestForgeWebhookFindsPullRequestsOpenedBeforeRegistration(t *testing.T) {
+	engine, svc := newForgeServer(t)
+	ctx := context.Background()
+	if _, err := svc.LinkForgeAccount(ctx, domain.ForgeAccount{Forge: domain.ForgeGitHub, Login: "alice-dev", UserID: "u1"}); err != nil {
+		t.Fatalf("LinkForgeAccount: %v", err)
+	}
+	if rec := postGitHub(t, engine, "pull_request", "pull_request_opened.json", githubSecret); rec.Code != nethttp.StatusOK {
+		t.Fatalf("opened: %d %s", rec.Code, rec.Body)
+	}
+	if _, err := svc.CreateRepository(ctx, domain.Repository{Name: "octo-org/reviewer"}); err != nil {
+		t.Fatalf("CreateRepository: %v", err)
+	}
+
+	rec := postGitHub(t, engine, "pull_request", "pull_request_closed_merged.json", githubSecret)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusMerged {
+		t.Fatalf("merged: %d %s", rec.Code, rec.Body)
+	}
+	if pr, err := svc.GetPullRequest(ctx, "", "octo-org/reviewer#42"); err != nil || pr.Status != domain.StatusMerged {
+		t.Fatalf("unexpected PR: %+v, %v", pr, err)
+	}
+}
+
+func TestGitHubWebhookClosesWithoutMerge(t *testing.T) {
+	engine, svc := newForgeServer(t)
+	if _, err := svc.LinkForgeAccount(context.Background(), domain.ForgeAccount{Forge: domain.ForgeGitHub, Login: "Alice-Dev", UserID: "u1"}); err != nil {
+		t.Fatalf("LinkForgeAccount: %v", err)
+	}
+
+	rec := postGitHub(t, engine, "pull_request", "pull_request_opened_draft.json", githubSecret)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusDraft {
+		t.Fatalf("opened draft: %d %s", rec.Code, rec.Body)
+	}
+	rec = postGitHub(t, engine, "pull_request", "pull_request_closed.json", githubSecret)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusClosed {
+		t.Fatalf("closed: %d %s", rec.Code, rec.Body)
+	}
+	rec = postGitHub(t, engine, "pull_request", "pull_request_reopened.json", githubSecret)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusOpen {
+		t.Fatalf("reopened: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestGitHubWebhookRejections(t *testing.T) {
+	engine, _ := newForgeServer(t)
+
+	rec := postGitHub(t, engine, "pull_request", "pull_request_opened.json", "wrong")
+	if rec.Code != nethttp.StatusUnauthorized || errorCode(t, rec) != domain.ErrCodeUnauthorized {
+		t.Fatalf("bad signature: %d %s", rec.Code, rec.Body)
+	}
+	rec = postGitHub(t, engine, "pull_request", "pull_request_opened.json", githubSecret)
+	if rec.Code != nethttp.StatusNotFound || errorCode(t, rec) != domain.ErrCodeNotFound {
+		t.Fatalf("unlinked author: %d %s", rec.Code, rec.Body)
+	}
+	if rec := postGitHub(t, engine, "ping", "ping.json", githubSecret); rec.Code != nethttp.StatusOK {
+		t.Fatalf("ping: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestGitLabWebhookDrivesMergeRequestLifecycle(t *testing.T) {
+	engine, svc := newForgeServer(t)
+	if rec := do(engine, nethttp.MethodPost, "/forge/link", "root", `{"forge":"gitlab","login":"51","user_id":"u1"}`); rec.Code != nethttp.StatusOK {
+		t.Fatalf("link account: %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := postGitLab(t, engine, "merge_request_open.json", "wrong"); rec.Code != nethttp.StatusUnauthorized {
+		t.Fatalf("bad token: %d %s", rec.Code, rec.Body)
+	}
+	rec := postGitLab(t, engine, "merge_request_open_draft.json", gitlabToken)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusDraft {
+		t.Fatalf("opened draft: %d %s", rec.Code, rec.Body)
+	}
+	pr, err := svc.GetPullRequest(context.Background(), "", "platform/reviewer!17")
+	if err != nil || pr.AuthorID != "u1" || len(pr.Assigned) != 0 {
+		t.Fatalf("unexpected PR: %+v, %v", pr, err)
+	}
+
+	if rec := postGitLab(t, engine, "merge_request_approved.json", gitlabToken); rec.Code != nethttp.StatusOK {
+		t.Fatalf("approved: %d %s", rec.Code, rec.Body)
+	}
+	rec = postGitLab(t, engine, "merge_request_update_ready.json", gitlabToken)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusOpen {
+		t.Fatalf("marked ready: %d %s", rec.Code, rec.Body)
+	}
+	rec = postGitLab(t, engine, "merge_request_merge.json", gitlabToken)
+	if rec.Code != nethttp.StatusOK || prStatus(t, rec) != domain.StatusMerged {
+		t.Fatalf("merged: %d %s", rec.Code, rec.Body)
+	}
+
+	events, err := svc.GetPullRequestHistory(context.Background(), "", "platform/reviewer!17")
+	if err != nil || events[len(events)-1].Actor != "gitlab:bob.ops" {
+		t.Fatalf("unexpected history: %+v, %v", events, err)
+	}
+}
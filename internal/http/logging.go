@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	nethttp "net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestIDHeader carries the request id from the caller and back to it.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds ids accepted from callers; longer ones are
+// replaced.
+const maxRequestIDLength = 128
+
+// logRequests gives every request an id, puts a logger tagged with it and
+// with the trace id into the request context, and logs the finished request.
+// Requests to infrastructure routes are logged at debug level.
+func logRequests(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		reqLogger := logger.With(slog.String("request_id", requestID))
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			reqLogger = reqLogger.With(slog.String("trace_id", sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), reqLogger))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if code, ok := c.Get(errorCodeContextKey); ok {
+			attrs = append(attrs, slog.String("error_code", string(code.(domain.ErrorCode))))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= nethttp.StatusInternalServerError:
+			level = slog.LevelError
+		case infraRoutes[route]:
+			level = slog.LevelDebug
+		}
+		reqLogger.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// recoverPanics turns a panic into an INTERNAL error response and logs it
+// with the request logger.
+func recoverPanics() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error("panic recovered",
+			slog.Any("panic", recovered), slog.String("stack", string(debug.Stack())))
+		writeError(c, nethttp.StatusInternalServerError, domain.ErrCodeInternal, "internal error")
+		c.Abort()
+	})
+}
+
+// validRequestID accepts non-empty printable ASCII ids without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
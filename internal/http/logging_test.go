@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLogsCarryRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	engine := NewServer(service.New(memory.New(), nil), WithAdminToken("root"), WithLogger(logging.New(&out, logging.FormatJSON, "info")))
+
+	team := `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("create team: %d %s", rec.Code, rec.Body)
+	}
+	out.Reset()
+
+	send := func(method, path, requestID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer root")
+		req.Header.Set("Content-Type", "application/json")
+		if requestID != "" {
+			req.Header.Set(requestIDHeader, requestID)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(nethttp.MethodPost, "/pullRequest/create", "req-42", `{"pull_request_id":"pr-1","pull_request_name":"PR","author_id":"u1"}`)
+	if rec.Code != nethttp.StatusCreated || rec.Header().Get(requestIDHeader) != "req-42" {
+		t.Fatalf("create PR: %d %q %s", rec.Code, rec.Header().Get(requestIDHeader), rec.Body)
+	}
+	entries := decodeLogs(t, &out)
+	if len(entries) != 2 {
+		t.Fatalf("want service and request entries, got %v", entries)
+	}
+	if entries[0]["msg"] != "pull request created" || entries[0]["request_id"] != "req-42" || entries[0]["pr_id"] != "pr-1" {
+		t.Fatalf("unexpected service entry %v", entries[0])
+	}
+	if entries[1]["msg"] != "http request" || entries[1]["request_id"] != "req-42" ||
+		entries[1]["route"] != "/pullRequest/create" || entries[1]["status"] != float64(nethttp.StatusCreated) {
+		t.Fatalf("unexpected request entry %v", entries[1])
+	}
+	if _, ok := entries[1]["error_code"]; ok {
+		t.Fatalf("successful request logged an error code: %v", entries[1])
+	}
+
+	rec = send(nethttp.MethodGet, "/team/get?team_name=frontend", "bad id", "")
+	generated := rec.Header().Get(requestIDHeader)
+	if generated == "" || generated == "bad id" {
+		t.Fatalf("invalid request id was not replaced: %q", generated)
+	}
+	entries = decodeLogs(t, &out)
+	if len(entries) != 1 || entries[0]["request_id"] != generated || entries[0]["error_code"] != "NOT_FOUND" || entries[0]["level"] != "INFO" {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+}
+
+func decodeLogs(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	out.Reset()
+	return entries
+}
@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// errorCodeContextKey holds the code of the error response written for the
+// request.
+const errorCodeContextKey = "error_code"
+
+// unmatchedRoute labels requests that matched no registered route.
+const unmatchedRoute = "unmatched"
+
+// observeRequests records the latency of every request under its registered
+// route and counts error responses by code.
+func observeRequests(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+		if code, ok := c.Get(errorCodeContextKey); ok {
+			m.CountError(code.(domain.ErrorCode))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package transport
+
+import (
+	nethttp "net/http"
+	"strings"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/metrics"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+	svc := service.New(memory.New(), nil, service.WithMetrics(m))
+	engine := NewServer(svc, WithAdminToken("root"), WithMetrics(m))
+
+	team := `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("create team: %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/pullRequest/create", "root", `{"pull_request_id":"pr-1","pull_request_name":"PR","author_id":"u1"}`); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("create PR: %d %s", rec.Code, rec.Body)
+	}
+	do(engine, nethttp.MethodGet, "/team/get?team_name=frontend", "root", "")
+	do(engine, nethttp.MethodGet, "/team/get?team_name=backend", "", "")
+	do(engine, nethttp.MethodGet, "/no/such/route", "root", "")
+
+	rec := do(engine, nethttp.MethodGet, "/metrics", "", "")
+	if rec.Code != nethttp.StatusOK {
+		t.Fatalf("/metrics: %d %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`pr_reviewer_http_request_duration_seconds_count{method="POST",route="/pullRequest/create",status="201"} 1`,
+		`pr_reviewer_http_request_duration_seconds_count{method="GET",route="/team/get",status="404"} 1`,
+		`pr_reviewer_http_request_duration_seconds_count{method="GET",route="/team/get",status="401"} 1`,
+		`pr_reviewer_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+		`pr_reviewer_http_errors_total{code="NOT_FOUND"} 1`,
+		`pr_reviewer_http_errors_total{code="UNAUTHORIZED"} 1`,
+		`pr_reviewer_pull_requests_created_total 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics lack %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"errors"
+	nethttp "net/http"
+	"slices"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxChangedFiles bounds the file list of a single pull request.
+const maxChangedFiles = 3000
+
+type createOwnershipRuleRequest struct {
+	Repository string   `json:"repository" binding:"required"`
+	Pattern    string   `json:"pattern" binding:"required"`
+	TeamName   string   `json:"team_name"`
+	UserIDs    []string `json:"user_ids"`
+}
+
+type ownershipRuleIDRequest struct {
+	RuleID int64 `json:"rule_id" binding:"required"`
+}
+
+func (h handler) createOwnershipRule(c *gin.Context) {
+	var req createOwnershipRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateOwnershipRule(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	rule, err := h.svc.CreateOwnershipRule(c.Request.Context(), domain.OwnershipRule{
+		Repository: req.Repository,
+		Pattern:    req.Pattern,
+		TeamName:   req.TeamName,
+		UserIDs:    slices.Compact(slices.Sorted(slices.Values(req.UserIDs))),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusCreated, gin.H{"rule": rule})
+}
+
+func (h handler) listOwnershipRules(c *gin.Context) {
+	rules, err := h.svc.ListOwnershipRules(c.Request.Context(), c.Query("repository"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"rules": rules})
+}
+
+func (h handler) deleteOwnershipRule(c *gin.Context) {
+	var req ownershipRuleIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := h.svc.DeleteOwnershipRule(c.Request.Context(), req.RuleID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"rule_id": req.RuleID})
+}
+
+// validateOwnershipRule checks the pattern and that the rule names either a
+// team or users, but not both.
+func validateOwnershipRule(req createOwnershipRuleRequest) error {
+	if err := service.ValidatePattern(req.Pattern); err != nil {
+		return err
+	}
+	if (req.TeamName == "") == (len(req.UserIDs) == 0) {
+		return errors.New("exactly one of team_name and user_ids is required")
+	}
+	if slices.Contains(req.UserIDs, "") {
+		return errors.New("user_ids must not contain empty ids")
+	}
+	return nil
+}
+
+// validateChangedFiles checks the file list of a new pull request; ownership
+// rules are looked up by repository, so files need one.
+func validateChangedFiles(repository string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if repository == "" {
+		return errors.New("repository is required with changed_files")
+	}
+	if len(files) > maxChangedFiles {
+		return errors.New("changed_files is too long")
+	}
+	if slices.Contains(files, "") {
+		return errors.New("changed_files must not contain empty paths")
+	}
+	return nil
+}
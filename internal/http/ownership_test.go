@@ -0,0 +1,40 @@
+package transport
+
+import (
+	nethttp "net/http"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+func TestOwnershipRuleValidation(t *testing.T) {
+	engine, _ := newTestServer(t)
+	team := `{"team_name":"infra","members":[{"user_id":"i1","username":"Ivan","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(engine, nethttp.MethodPost, "/repository/add", "root", `{"name":"acme/shop"}`); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("repository/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for name, body := range map[string]string{
+		"no owner":   `{"repository":"acme/shop","pattern":"deploy/**"}`,
+		"both":       `{"repository":"acme/shop","pattern":"deploy/**","team_name":"infra","user_ids":["i1"]}`,
+		"bad glob":   `{"repository":"acme/shop","pattern":"deploy/[a","team_name":"infra"}`,
+		"empty path": `{"repository":"acme/shop","pattern":"/","team_name":"infra"}`,
+	} {
+		if rec := do(engine, nethttp.MethodPost, "/ownership/add", "root", body); rec.Code != nethttp.StatusBadRequest {
+			t.Errorf("%s: got %d %s", name, rec.Code, rec.Body)
+		}
+	}
+
+	rec := do(engine, nethttp.MethodPost, "/ownership/add", "root", `{"repository":"acme/shop","pattern":"deploy/**","team_name":"infra"}`)
+	if rec.Code != nethttp.StatusCreated {
+		t.Fatalf("ownership/add: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(engine, nethttp.MethodPost, "/pullRequest/create", "root", `{"pull_request_id":"pr-1","pull_request_name":"PR","author_id":"i1","changed_files":["deploy/app.yaml"]}`)
+	if rec.Code != nethttp.StatusBadRequest || errorCode(t, rec) != domain.ErrCodeNotFound {
+		t.Fatalf("changed_files without repository: %d %s", rec.Code, rec.Body)
+	}
+}
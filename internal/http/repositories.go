@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"errors"
+	nethttp "net/http"
+	"slices"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type repositoryRequest struct {
+	Name  string   `json:"name" binding:"required"`
+	Teams []string `json:"teams"`
+}
+
+type repositoryTeamsRequest struct {
+	Name  string   `json:"name" binding:"required"`
+	Teams []string `json:"teams" binding:"required"`
+}
+
+type repositoryNameRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h handler) createRepository(c *gin.Context) {
+	var req repositoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateRepository(req.Name, req.Teams); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	repo, err := h.svc.CreateRepository(c.Request.Context(), domain.Repository{Name: req.Name, Teams: req.Teams})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusCreated, gin.H{"repository": repo})
+}
+
+func (h handler) getRepository(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		respondValidationError(c, errors.New("name is required"))
+		return
+	}
+	repo, err := h.svc.GetRepository(c.Request.Context(), name)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"repository": repo})
+}
+
+func (h handler) listRepositories(c *gin.Context) {
+	repos, err := h.svc.ListRepositories(c.Request.Context(), c.Query("team_name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"repositories": repos})
+}
+
+func (h handler) updateRepository(c *gin.Context) {
+	var req repositoryTeamsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateRepository(req.Name, req.Teams); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	repo, err := h.svc.SetRepositoryTeams(c.Request.Context(), req.Name, req.Teams)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"repository": repo})
+}
+
+func (h handler) deleteRepository(c *gin.Context) {
+	var req repositoryNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := h.svc.DeleteRepository(c.Request.Context(), req.Name); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"name": req.Name})
+}
+
+// validateRepository rejects names with surrounding spaces, which would
+// silently never match the repository of a pull request, and empty team names.
+func validateRepository(name string, teams []string) error {
+	if strings.TrimSpace(name) != name {
+		return errors.New("name must not have leading or trailing spaces")
+	}
+	if slices.Contains(teams, "") {
+		return errors.New("teams must not contain empty names")
+	}
+	return nil
+}
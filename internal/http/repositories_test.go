@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+func TestRepositoryScopesPullRequestIDs(t *testing.T) {
+	engine, _ := newTestServer(t)
+	team := `{"team_name":"core","members":[{"user_id":"a1","username":"Ann","is_active":true},{"user_id":"b1","username":"Bob","is_active":true}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for name, body := range map[string]string{
+		"no name":    `{"teams":["core"]}`,
+		"spaces":     `{"name":" acme/shop"}`,
+		"empty team": `{"name":"acme/shop","teams":[""]}`,
+		"bad json":   `{"name":`,
+	} {
+		if rec := do(engine, nethttp.MethodPost, "/repository/add", "root", body); rec.Code != nethttp.StatusBadRequest {
+			t.Errorf("%s: got %d %s", name, rec.Code, rec.Body)
+		}
+	}
+	if rec := do(engine, nethttp.MethodPost, "/repository/add", "root", `{"name":"acme/shop","teams":["core","core"]}`); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("repository/add: %d %s", rec.Code, rec.Body)
+	}
+	rec := do(engine, nethttp.MethodPost, "/repository/add", "root", `{"name":"acme/shop"}`)
+	if rec.Code != nethttp.StatusConflict || errorCode(t, rec) != domain.ErrCodeRepositoryExists {
+		t.Fatalf("duplicate repository: %d %s", rec.Code, rec.Body)
+	}
+
+	for _, body := range []string{
+		`{"pull_request_id":"pr-1","pull_request_name":"Shop","author_id":"a1","repository":"acme/shop"}`,
+		`{"pull_request_id":"pr-1","pull_request_name":"Default","author_id":"a1"}`,
+	} {
+		if rec := do(engine, nethttp.MethodPost, "/pullRequest/create", "root", body); rec.Code != nethttp.StatusCreated {
+			t.Fatalf("pullRequest/create: %d %s", rec.Code, rec.Body)
+		}
+	}
+	rec = do(engine, nethttp.MethodPost, "/pullRequest/merge", "root", `{"repository":"acme/shop","pull_request_id":"pr-1"}`)
+	if rec.Code != nethttp.StatusOK {
+		t.Fatalf("pullRequest/merge: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(engine, nethttp.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "root", "")
+	var pr domain.PullRequest
+	if err := json.Unmarshal(rec.Body.Bytes(), &pr); err != nil || pr.Repository != "" || pr.Status != domain.StatusOpen {
+		t.Fatalf("default repository PR changed: %s %v", rec.Body, err)
+	}
+
+	rec = do(engine, nethttp.MethodGet, "/repository/list?team_name=core", "root", "")
+	var list struct {
+		Repositories []domain.Repository `json:"repositories"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Repositories) != 1 || len(list.Repositories[0].Teams) != 1 {
+		t.Fatalf("repository/list: %s %v", rec.Body, err)
+	}
+
+	rec = do(engine, nethttp.MethodPost, "/repository/delete", "root", `{"name":"acme/shop"}`)
+	if rec.Code != nethttp.StatusConflict || errorCode(t, rec) != domain.ErrCodeRepositoryInUse {
+		t.Fatalf("deleting a repository with pull requests: %d %s", rec.Code, rec.Body)
+	}
+}
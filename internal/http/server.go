@@ -1,44 +1,196 @@
 package transport
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	nethttp "net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/http/forge"
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+	"github.com/GolovachevS/pr-reviewer-service/internal/metrics"
 	"github.com/GolovachevS/pr-reviewer-service/internal/service"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// NewServer wires routes and returns a configured gin.Engine.
-func NewServer(svc *service.Service) *gin.Engine {
+// Option configures the server built by NewServer.
+type Option func(*options)
+
+type options struct {
+	adminToken     string
+	forges         []forge.Adapter
+	metrics        *metrics.Metrics
+	tracerProvider trace.TracerProvider
+	logger         *slog.Logger
+	readiness      []readinessCheck
+}
+
+// WithAdminToken accepts token as an admin credential in addition to stored
+// API keys. It lets operators create the first keys.
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
+
+// WithForge serves the adapter's webhooks at /forge/<name>. The adapter
+// authenticates them instead of an API token.
+func WithForge(adapter forge.Adapter) Option {
+	return func(o *options) {
+		o.forges = append(o.forges, adapter)
+	}
+}
+
+// WithMetrics serves m at /metrics and records request latency and error
+// codes in it.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithTracerProvider records request spans with tp instead of the global
+// provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithLogger logs requests with logger instead of the default logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithReadinessCheck makes /readyz report the named dependency as unavailable
+// while check fails.
+func WithReadinessCheck(name string, check func(context.Context) error) Option {
+	return func(o *options) {
+		o.readiness = append(o.readiness, readinessCheck{name: name, check: check})
+	}
+}
+
+// NewServer wires routes and returns a configured gin.Engine. Every route
+// except the probes, /metrics and the signed forge webhooks requires an API
+// token. The user role may create, merge and reassign pull requests and read
+// data; everything else is marked with the admin middleware.
+func NewServer(svc *service.Service, opts ...Option) *gin.Engine {
+	o := options{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	engine := gin.New()
-	engine.Use(gin.Logger(), gin.Recovery())
+	if o.metrics != nil {
+		engine.Use(observeRequests(o.metrics))
+		engine.GET("/metrics", gin.WrapH(o.metrics.Handler()))
+	}
+	engine.Use(traceRequests(o.tracerProvider), logRequests(o.logger), recoverPanics())
 
 	h := handler{svc: svc}
 
-	engine.GET("/health", func(c *gin.Context) {
-		c.JSON(nethttp.StatusOK, gin.H{"status": "ok"})
-	})
+	engine.GET("/health", live)
+	engine.GET("/livez", live)
+	engine.GET("/readyz", ready(o.readiness))
+
+	for _, adapter := range o.forges {
+		engine.POST("/forge/"+adapter.Forge(), h.forgeWebhook(adapter))
+	}
+
+	api := engine.Group("", authenticate(svc, o.adminToken), requireRole(domain.RoleUser))
+	admin := requireRole(domain.RoleAdmin)
 
-	team := engine.Group("/team")
+	team := api.Group("/team")
 	{
-		team.POST("/add", h.createTeam)
+		team.POST("/add", admin, h.createTeam)
 		team.GET("/get", h.getTeam)
+		team.POST("/update", admin, h.updateTeam)
+		team.POST("/deactivate", admin, h.deactivateUsers)
+		team.POST("/addMembers", admin, h.addTeamMembers)
+		team.POST("/removeMembers", admin, h.removeTeamMembers)
+		team.POST("/moveMember", admin, h.moveTeamMember)
 	}
 
-	users := engine.Group("/users")
+	users := api.Group("/users")
 	{
-		users.POST("/setIsActive", h.setUserActive)
+		users.POST("/setIsActive", admin, h.setUserActive)
+		users.POST("/setTags", admin, h.setUserTags)
+		users.POST("/setMaxOpenReviews", admin, h.setUserMaxOpenReviews)
+		users.POST("/availability/add", admin, h.createUnavailability)
+		users.GET("/availability/list", h.listUnavailability)
+		users.POST("/availability/delete", admin, h.deleteUnavailability)
 		users.GET("/getReview", h.getUserReviews)
+		users.GET("/history", h.getUserHistory)
 	}
 
-	pull := engine.Group("/pullRequest")
+	pull := api.Group("/pullRequest")
 	{
+		pull.GET("/list", h.listPullRequests)
+		pull.GET("/get", h.getPullRequest)
+		pull.GET("/history", h.getPullRequestHistory)
 		pull.POST("/create", h.createPullRequest)
 		pull.POST("/merge", h.mergePullRequest)
+		pull.POST("/close", admin, h.closePullRequest)
+		pull.POST("/reopen", admin, h.reopenPullRequest)
+		pull.POST("/ready", admin, h.markReadyForReview)
 		pull.POST("/reassign", h.reassignReviewer)
 	}
 
+	webhooks := api.Group("/webhook", admin)
+	{
+		webhooks.POST("/add", h.createWebhook)
+		webhooks.GET("/list", h.listWebhooks)
+		webhooks.POST("/delete", h.deleteWebhook)
+		webhooks.GET("/deliveries", h.listWebhookDeliveries)
+	}
+
+	keys := api.Group("/apiKey", admin)
+	{
+		keys.POST("/create", h.createAPIKey)
+		keys.GET("/list", h.listAPIKeys)
+		keys.POST("/revoke", h.revokeAPIKey)
+	}
+
+	forgeAccounts := api.Group("/forge", admin)
+	{
+		forgeAccounts.POST("/link", h.linkForgeAccount)
+		forgeAccounts.POST("/unlink", h.unlinkForgeAccount)
+		forgeAccounts.GET("/accounts", h.listForgeAccounts)
+	}
+
+	repositories := api.Group("/repository")
+	{
+		repositories.POST("/add", admin, h.createRepository)
+		repositories.GET("/get", h.getRepository)
+		repositories.GET("/list", h.listRepositories)
+		repositories.POST("/update", admin, h.updateRepository)
+		repositories.POST("/delete", admin, h.deleteRepository)
+	}
+
+	ownership := api.Group("/ownership", admin)
+	{
+		ownership.POST("/add", h.createOwnershipRule)
+		ownership.GET("/list", h.listOwnershipRules)
+		ownership.POST("/delete", h.deleteOwnershipRule)
+	}
+
+	stats := api.Group("/stats", admin)
+	{
+		stats.GET("/reviewers", h.getReviewerStats)
+		stats.GET("/teams", h.getTeamStats)
+		stats.GET("/pullRequest", h.getPullRequestStats)
+	}
+
 	return engine
 }
 
@@ -47,8 +199,36 @@ type handler struct {
 }
 
 type createTeamRequest struct {
+	TeamName          string              `json:"team_name" binding:"required"`
+	RequiredReviewers *int                `json:"required_reviewers"`
+	MinReviewers      *int                `json:"min_reviewers"`
+	MaxOpenReviews    *int                `json:"max_open_reviews"`
+	Members           []domain.TeamMember `json:"members"`
+}
+
+// updateTeamRequest changes the settings that are present and keeps the rest.
+type updateTeamRequest struct {
+	TeamName          string `json:"team_name" binding:"required"`
+	RequiredReviewers *int   `json:"required_reviewers"`
+	MinReviewers      *int   `json:"min_reviewers"`
+	MaxOpenReviews    *int   `json:"max_open_reviews"`
+}
+
+type addMembersRequest struct {
 	TeamName string              `json:"team_name" binding:"required"`
-	Members  []domain.TeamMember `json:"members"`
+	Members  []domain.TeamMember `json:"members" binding:"required"`
+}
+
+type removeMembersRequest struct {
+	TeamName        string   `json:"team_name" binding:"required"`
+	UserIDs         []string `json:"user_ids" binding:"required"`
+	ReassignReviews bool     `json:"reassign_reviews"`
+}
+
+type moveMemberRequest struct {
+	UserID          string `json:"user_id" binding:"required"`
+	TeamName        string `json:"team_name" binding:"required"`
+	ReassignReviews bool   `json:"reassign_reviews"`
 }
 
 type setActiveRequest struct {
@@ -56,21 +236,51 @@ type setActiveRequest struct {
 	IsActive *bool  `json:"is_active" binding:"required"`
 }
 
+type deactivateRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
 type createPRRequest struct {
-	PullRequestID   string `json:"pull_request_id" binding:"required"`
-	PullRequestName string `json:"pull_request_name" binding:"required"`
-	AuthorID        string `json:"author_id" binding:"required"`
+	PullRequestID   string   `json:"pull_request_id" binding:"required"`
+	PullRequestName string   `json:"pull_request_name" binding:"required"`
+	AuthorID        string   `json:"author_id" binding:"required"`
+	Draft           bool     `json:"draft"`
+	Repository      string   `json:"repository"`
+	ChangedFiles    []string `json:"changed_files"`
+	RequiredTags    []string `json:"required_tags"`
 }
 
-type mergePRRequest struct {
+type pullRequestIDRequest struct {
+	Repository    string `json:"repository"`
 	PullRequestID string `json:"pull_request_id" binding:"required"`
 }
 
 type reassignRequest struct {
+	Repository    string `json:"repository"`
 	PullRequestID string `json:"pull_request_id" binding:"required"`
 	OldUserID     string `json:"old_user_id" binding:"required"`
 }
 
+type createWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	Secret     string   `json:"secret" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+	Role string `json:"role" binding:"required"`
+}
+
+type apiKeyIDRequest struct {
+	KeyID int64 `json:"key_id" binding:"required"`
+}
+
+type webhookIDRequest struct {
+	WebhookID int64 `json:"webhook_id" binding:"required"`
+}
+
 func (h handler) createTeam(c *gin.Context) {
 	var req createTeamRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -87,11 +297,32 @@ func (h handler) createTeam(c *gin.Context) {
 			respondValidationError(c, errMissingMemberFields)
 			return
 		}
+		if err := validateTags(member.Tags); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if err := validateMaxOpenReviews(member.MaxOpenReviews); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+	}
+
+	required, minimum, err := reviewerSettings(req.RequiredReviewers, req.MinReviewers)
+	if err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateMaxOpenReviews(req.MaxOpenReviews); err != nil {
+		respondValidationError(c, err)
+		return
 	}
 
 	team, err := h.svc.CreateTeam(c.Request.Context(), domain.Team{
-		TeamName: req.TeamName,
-		Members:  req.Members,
+		TeamName:          req.TeamName,
+		RequiredReviewers: required,
+		MinReviewers:      minimum,
+		MaxOpenReviews:    valueOrZero(req.MaxOpenReviews),
+		Members:           req.Members,
 	})
 	if err != nil {
 		respondError(c, err)
@@ -117,6 +348,138 @@ func (h handler) getTeam(c *gin.Context) {
 	c.JSON(nethttp.StatusOK, team)
 }
 
+func (h handler) updateTeam(c *gin.Context) {
+	var req updateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateSettingsUpdate(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	team, err := h.svc.UpdateTeamSettings(c.Request.Context(), req.TeamName, service.TeamSettingsUpdate{
+		RequiredReviewers: req.RequiredReviewers,
+		MinReviewers:      req.MinReviewers,
+		MaxOpenReviews:    req.MaxOpenReviews,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"team": team})
+}
+
+func (h handler) deactivateUsers(c *gin.Context) {
+	var req deactivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if req.TeamName == "" && len(req.UserIDs) == 0 {
+		respondValidationError(c, errors.New("team_name or user_ids is required"))
+		return
+	}
+	for _, id := range req.UserIDs {
+		if id == "" {
+			respondValidationError(c, errors.New("user_ids must not contain empty values"))
+			return
+		}
+	}
+
+	result, err := h.svc.DeactivateUsers(c.Request.Context(), service.DeactivateUsersInput{
+		TeamName: req.TeamName,
+		UserIDs:  req.UserIDs,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, result)
+}
+
+func (h handler) addTeamMembers(c *gin.Context) {
+	var req addMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if len(req.Members) == 0 {
+		respondValidationError(c, errors.New("members must not be empty"))
+		return
+	}
+	for _, member := range req.Members {
+		if member.UserID == "" || member.Username == "" {
+			respondValidationError(c, errMissingMemberFields)
+			return
+		}
+		if err := validateTags(member.Tags); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if err := validateMaxOpenReviews(member.MaxOpenReviews); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+	}
+
+	team, err := h.svc.AddTeamMembers(c.Request.Context(), req.TeamName, req.Members)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"team": team})
+}
+
+func (h handler) removeTeamMembers(c *gin.Context) {
+	var req removeMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if len(req.UserIDs) == 0 {
+		respondValidationError(c, errors.New("user_ids must not be empty"))
+		return
+	}
+	for _, id := range req.UserIDs {
+		if id == "" {
+			respondValidationError(c, errors.New("user_ids must not contain empty values"))
+			return
+		}
+	}
+
+	result, err := h.svc.RemoveTeamMembers(c.Request.Context(), service.RemoveTeamMembersInput{
+		TeamName:        req.TeamName,
+		UserIDs:         req.UserIDs,
+		ReassignReviews: req.ReassignReviews,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, result)
+}
+
+func (h handler) moveTeamMember(c *gin.Context) {
+	var req moveMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.svc.MoveTeamMember(c.Request.Context(), service.MoveTeamMemberInput{
+		UserID:          req.UserID,
+		TeamName:        req.TeamName,
+		ReassignReviews: req.ReassignReviews,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, result)
+}
+
 func (h handler) setUserActive(c *gin.Context) {
 	var req setActiveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -141,10 +504,22 @@ func (h handler) createPullRequest(c *gin.Context) {
 		respondValidationError(c, err)
 		return
 	}
+	if err := validateChangedFiles(req.Repository, req.ChangedFiles); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateTags(req.RequiredTags); err != nil {
+		respondValidationError(c, err)
+		return
+	}
 	pr, err := h.svc.CreatePullRequest(c.Request.Context(), service.CreatePullRequestInput{
 		PullRequestID:   req.PullRequestID,
 		PullRequestName: req.PullRequestName,
 		AuthorID:        req.AuthorID,
+		Draft:           req.Draft,
+		Repository:      req.Repository,
+		ChangedFiles:    req.ChangedFiles,
+		RequiredTags:    req.RequiredTags,
 	})
 	if err != nil {
 		respondError(c, err)
@@ -153,13 +528,59 @@ func (h handler) createPullRequest(c *gin.Context) {
 	c.JSON(nethttp.StatusCreated, gin.H{"pr": pr})
 }
 
+func (h handler) listPullRequests(c *gin.Context) {
+	filter, err := pullRequestFilter(c)
+	if err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	prs, err := h.svc.ListPullRequests(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, prs)
+}
+
+func (h handler) getPullRequest(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		respondValidationError(c, errors.New("pull_request_id is required"))
+		return
+	}
+	pr, err := h.svc.GetPullRequest(c.Request.Context(), c.Query("repository"), prID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, pr)
+}
+
 func (h handler) mergePullRequest(c *gin.Context) {
-	var req mergePRRequest
+	h.changePullRequestStatus(c, h.svc.MergePullRequest)
+}
+
+func (h handler) closePullRequest(c *gin.Context) {
+	h.changePullRequestStatus(c, h.svc.ClosePullRequest)
+}
+
+func (h handler) reopenPullRequest(c *gin.Context) {
+	h.changePullRequestStatus(c, h.svc.ReopenPullRequest)
+}
+
+func (h handler) markReadyForReview(c *gin.Context) {
+	h.changePullRequestStatus(c, h.svc.MarkReadyForReview)
+}
+
+// changePullRequestStatus handles the status endpoints that share the
+// {repository, pull_request_id} request and {pr} response shapes.
+func (h handler) changePullRequestStatus(c *gin.Context, change func(context.Context, string, string) (domain.PullRequest, error)) {
+	var req pullRequestIDRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondValidationError(c, err)
 		return
 	}
-	pr, err := h.svc.MergePullRequest(c.Request.Context(), req.PullRequestID)
+	pr, err := change(c.Request.Context(), req.Repository, req.PullRequestID)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -173,7 +594,7 @@ func (h handler) reassignReviewer(c *gin.Context) {
 		respondValidationError(c, err)
 		return
 	}
-	pr, replaced, err := h.svc.ReassignReviewer(c.Request.Context(), req.PullRequestID, req.OldUserID)
+	pr, replaced, err := h.svc.ReassignReviewer(c.Request.Context(), req.Repository, req.PullRequestID, req.OldUserID)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -187,7 +608,12 @@ func (h handler) getUserReviews(c *gin.Context) {
 		respondValidationError(c, errors.New("user_id is required"))
 		return
 	}
-	reviews, err := h.svc.GetUserReviews(c.Request.Context(), userID)
+	filter, err := userReviewsFilter(c)
+	if err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	reviews, err := h.svc.GetUserReviews(c.Request.Context(), userID, filter)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -195,8 +621,351 @@ func (h handler) getUserReviews(c *gin.Context) {
 	c.JSON(nethttp.StatusOK, reviews)
 }
 
+func (h handler) getPullRequestHistory(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		respondValidationError(c, errors.New("pull_request_id is required"))
+		return
+	}
+	events, err := h.svc.GetPullRequestHistory(c.Request.Context(), c.Query("repository"), prID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"pull_request_id": prID, "events": events})
+}
+
+func (h handler) getUserHistory(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		respondValidationError(c, errors.New("user_id is required"))
+		return
+	}
+	events, err := h.svc.GetUserHistory(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"user_id": userID, "events": events})
+}
+
+func (h handler) createWebhook(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateWebhook(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	hook, err := h.svc.CreateWebhook(c.Request.Context(), domain.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusCreated, gin.H{"webhook": hook})
+}
+
+func (h handler) listWebhooks(c *gin.Context) {
+	hooks, err := h.svc.ListWebhooks(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"webhooks": hooks})
+}
+
+func (h handler) deleteWebhook(c *gin.Context) {
+	var req webhookIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := h.svc.DeleteWebhook(c.Request.Context(), req.WebhookID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"webhook_id": req.WebhookID})
+}
+
+func (h handler) listWebhookDeliveries(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Query("webhook_id"), 10, 64)
+	if err != nil || webhookID < 1 {
+		respondValidationError(c, errors.New("webhook_id must be a positive integer"))
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		respondValidationError(c, fmt.Errorf("unknown delivery status %q", status))
+		return
+	}
+	limit, err := limitQuery(c)
+	if err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	deliveries, err := h.svc.ListWebhookDeliveries(c.Request.Context(), service.WebhookDeliveryFilter{
+		WebhookID: webhookID,
+		Status:    status,
+		Limit:     limit,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"webhook_id": webhookID, "deliveries": deliveries})
+}
+
+func (h handler) createAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if req.Role != domain.RoleAdmin && req.Role != domain.RoleUser {
+		respondValidationError(c, fmt.Errorf("role must be %s or %s", domain.RoleAdmin, domain.RoleUser))
+		return
+	}
+	key, token, err := h.svc.CreateAPIKey(c.Request.Context(), req.Name, req.Role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusCreated, gin.H{"api_key": key, "token": token})
+}
+
+func (h handler) listAPIKeys(c *gin.Context) {
+	keys, err := h.svc.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"api_keys": keys})
+}
+
+func (h handler) revokeAPIKey(c *gin.Context) {
+	var req apiKeyIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	key, err := h.svc.RevokeAPIKey(c.Request.Context(), req.KeyID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"api_key": key})
+}
+
+func (h handler) getReviewerStats(c *gin.Context) {
+	stats, err := h.svc.GetReviewerStats(c.Request.Context(), c.Query("team_name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"reviewers": stats})
+}
+
+func (h handler) getTeamStats(c *gin.Context) {
+	stats, err := h.svc.GetTeamStats(c.Request.Context(), c.Query("team_name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"teams": stats})
+}
+
+func (h handler) getPullRequestStats(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		respondValidationError(c, errors.New("pull_request_id is required"))
+		return
+	}
+	stats, err := h.svc.GetPullRequestStats(c.Request.Context(), c.Query("repository"), prID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, stats)
+}
+
+// userReviewsFilter reads the optional status, created_from, created_to,
+// cursor and limit query parameters of /users/getReview.
+func userReviewsFilter(c *gin.Context) (service.UserReviewsFilter, error) {
+	var filter service.UserReviewsFilter
+	var err error
+	if filter.Statuses, err = statusesQuery(c); err != nil {
+		return service.UserReviewsFilter{}, err
+	}
+	if filter.CreatedFrom, err = timeQuery(c, "created_from"); err != nil {
+		return service.UserReviewsFilter{}, err
+	}
+	if filter.CreatedTo, err = timeQuery(c, "created_to"); err != nil {
+		return service.UserReviewsFilter{}, err
+	}
+	if filter.After, err = cursorQuery(c); err != nil {
+		return service.UserReviewsFilter{}, err
+	}
+	if filter.Limit, err = limitQuery(c); err != nil {
+		return service.UserReviewsFilter{}, err
+	}
+	return filter, nil
+}
+
+// pullRequestFilter reads the query parameters of /pullRequest/list.
+func pullRequestFilter(c *gin.Context) (service.PullRequestFilter, error) {
+	filter := service.PullRequestFilter{
+		Repository:   c.Query("repository"),
+		AuthorID:     c.Query("author_id"),
+		TeamName:     c.Query("team_name"),
+		ReviewerID:   c.Query("reviewer_id"),
+		NameContains: c.Query("name"),
+		Order:        c.Query("order"),
+	}
+	switch filter.Order {
+	case "", service.OrderNewest, service.OrderOldest:
+	default:
+		return service.PullRequestFilter{}, fmt.Errorf("order must be %s or %s", service.OrderNewest, service.OrderOldest)
+	}
+
+	var err error
+	if filter.Statuses, err = statusesQuery(c); err != nil {
+		return service.PullRequestFilter{}, err
+	}
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	} {
+		if *bound.target, err = timeQuery(c, bound.name); err != nil {
+			return service.PullRequestFilter{}, err
+		}
+	}
+	if filter.After, err = cursorQuery(c); err != nil {
+		return service.PullRequestFilter{}, err
+	}
+	if filter.Limit, err = limitQuery(c); err != nil {
+		return service.PullRequestFilter{}, err
+	}
+	return filter, nil
+}
+
+// statusesQuery reads pull request statuses given either comma-separated or
+// as a repeated status parameter.
+func statusesQuery(c *gin.Context) ([]string, error) {
+	var statuses []string
+	for _, value := range c.QueryArray("status") {
+		for _, status := range strings.Split(value, ",") {
+			switch status {
+			case domain.StatusDraft, domain.StatusOpen, domain.StatusMerged, domain.StatusClosed:
+				statuses = append(statuses, status)
+			default:
+				return nil, fmt.Errorf("unknown status %q", status)
+			}
+		}
+	}
+	return statuses, nil
+}
+
+func timeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &parsed, nil
+}
+
+func cursorQuery(c *gin.Context) (*service.Cursor, error) {
+	value := c.Query("cursor")
+	if value == "" {
+		return nil, nil
+	}
+	cursor, err := service.DecodeCursor(value)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// limitQuery returns the requested page size or 0 when the service default applies.
+func limitQuery(c *gin.Context) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return limit, nil
+}
+
+// validateWebhook checks that the target is an absolute http(s) URL and that
+// every event type is known.
+func validateWebhook(req createWebhookRequest) error {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if len(req.EventTypes) == 0 {
+		return errors.New("event_types must not be empty")
+	}
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
 var errMissingMemberFields = errors.New("member.user_id and member.username are required")
 
+// reviewerSettings applies defaults to optional team reviewer counts and checks their bounds.
+func reviewerSettings(required, minimum *int) (int, int, error) {
+	requiredValue, minValue := domain.DefaultRequiredReviewers, 0
+	if required != nil {
+		requiredValue = *required
+	}
+	if minimum != nil {
+		minValue = *minimum
+	}
+
+	if requiredValue < 1 || requiredValue > domain.MaxRequiredReviewers {
+		return 0, 0, fmt.Errorf("required_reviewers must be between 1 and %d", domain.MaxRequiredReviewers)
+	}
+	if minValue < 0 || minValue > requiredValue {
+		return 0, 0, errors.New("min_reviewers must be between 0 and required_reviewers")
+	}
+	return requiredValue, minValue, nil
+}
+
+// validateSettingsUpdate checks the fields present in the request; the store
+// checks min_reviewers against required_reviewers after merging.
+func validateSettingsUpdate(req updateTeamRequest) error {
+	if req.RequiredReviewers != nil && (*req.RequiredReviewers < 1 || *req.RequiredReviewers > domain.MaxRequiredReviewers) {
+		return fmt.Errorf("required_reviewers must be between 1 and %d", domain.MaxRequiredReviewers)
+	}
+	if req.MinReviewers != nil && *req.MinReviewers < 0 {
+		return errors.New("min_reviewers must not be negative")
+	}
+	return validateMaxOpenReviews(req.MaxOpenReviews)
+}
+
 func respondValidationError(c *gin.Context, err error) {
 	writeError(c, nethttp.StatusBadRequest, domain.ErrCodeNotFound, err.Error())
 }
@@ -207,10 +976,12 @@ func respondError(c *gin.Context, err error) {
 		writeError(c, appErr.Status, appErr.Code, appErr.Message)
 		return
 	}
+	logging.FromContext(c.Request.Context()).Error("request failed", slog.String("error", err.Error()))
 	writeError(c, nethttp.StatusInternalServerError, domain.ErrCodeInternal, "internal error")
 }
 
 func writeError(c *gin.Context, status int, code domain.ErrorCode, message string) {
+	c.Set(errorCodeContextKey, code)
 	c.JSON(status, gin.H{
 		"error": gin.H{
 			"code":    code,
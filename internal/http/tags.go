@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTags bounds the skill tags of a user or a pull request.
+const maxTags = 20
+
+type setTagsRequest struct {
+	UserID string   `json:"user_id" binding:"required"`
+	Tags   []string `json:"tags" binding:"required"`
+}
+
+func (h handler) setUserTags(c *gin.Context) {
+	var req setTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateTags(req.Tags); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	user, err := h.svc.SetUserTags(c.Request.Context(), req.UserID, req.Tags)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"user": user})
+}
+
+// validateTags rejects empty tags and tags with whitespace, which usually
+// mean a list was sent as one string.
+func validateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return errors.New("too many tags")
+	}
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsFunc(tag, unicode.IsSpace) {
+			return errors.New("tags must be non-empty and contain no spaces")
+		}
+	}
+	return nil
+}
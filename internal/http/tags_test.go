@@ -0,0 +1,54 @@
+package transport
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"slices"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequiredTagsPickTaggedReviewer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := NewServer(service.New(memory.New(), service.NewTagCoveragePicker(service.NewRandomPicker())), WithAdminToken("root"))
+	team := `{"team_name":"web","required_reviewers":1,"members":[{"user_id":"a1","username":"Ann","is_active":true},{"user_id":"b1","username":"Bob","is_active":true},{"user_id":"c1","username":"Cid","is_active":true,"tags":["Frontend"]}]}`
+	if rec := do(engine, nethttp.MethodPost, "/team/add", "root", team); rec.Code != nethttp.StatusCreated {
+		t.Fatalf("team/add: %d %s", rec.Code, rec.Body)
+	}
+
+	for name, body := range map[string]string{
+		"no tags":   `{"user_id":"b1"}`,
+		"empty tag": `{"user_id":"b1","tags":[""]}`,
+		"space":     `{"user_id":"b1","tags":["go sql"]}`,
+	} {
+		if rec := do(engine, nethttp.MethodPost, "/users/setTags", "root", body); rec.Code != nethttp.StatusBadRequest {
+			t.Errorf("%s: got %d %s", name, rec.Code, rec.Body)
+		}
+	}
+	rec := do(engine, nethttp.MethodPost, "/users/setTags", "root", `{"user_id":"b1","tags":["SQL","go"]}`)
+	var set struct {
+		User domain.User `json:"user"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil || !slices.Equal(set.User.Tags, []string{"go", "sql"}) {
+		t.Fatalf("users/setTags: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(engine, nethttp.MethodPost, "/pullRequest/create", "root", `{"pull_request_id":"pr-0","pull_request_name":"UI","author_id":"a1","required_tags":["rust"]}`)
+	if rec.Code != nethttp.StatusCreated {
+		t.Fatalf("a tag nobody has is skipped: %d %s", rec.Code, rec.Body)
+	}
+	for i := 0; i < 5; i++ {
+		body := `{"pull_request_id":"pr-` + string(rune('1'+i)) + `","pull_request_name":"UI","author_id":"a1","required_tags":["frontend"]}`
+		rec = do(engine, nethttp.MethodPost, "/pullRequest/create", "root", body)
+		var created struct {
+			PR domain.PullRequest `json:"pr"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || !slices.Equal(created.PR.Assigned, []string{"c1"}) {
+			t.Fatalf("the frontend reviewer must be assigned: %d %s", rec.Code, rec.Body)
+		}
+	}
+}
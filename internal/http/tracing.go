@@ -0,0 +1,29 @@
+package transport
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// serverName is the name request spans are recorded under.
+const serverName = "pr-reviewer-service"
+
+// infraRoutes are polled by infrastructure; they are not traced and are logged
+// at debug level only, as they would only add noise.
+var infraRoutes = map[string]bool{"/health": true, "/livez": true, "/readyz": true, "/metrics": true}
+
+// traceRequests starts a span per request, continuing the trace of the caller
+// when the request carries W3C trace context. A nil tp means the global
+// provider.
+func traceRequests(tp trace.TracerProvider) gin.HandlerFunc {
+	opts := []otelgin.Option{
+		otelgin.WithGinFilter(func(c *gin.Context) bool {
+			return !infraRoutes[c.FullPath()]
+		}),
+	}
+	if tp != nil {
+		opts = append(opts, otelgin.WithTracerProvider(tp))
+	}
+	return otelgin.Middleware(serverName, opts...)
+}
@@ -0,0 +1,37 @@
+package transport
+
+import (
+	nethttp "net/http"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+	"github.com/gin-gonic/gin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestSpansParentServiceSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	svc := service.New(memory.New(), nil, service.WithTracerProvider(tp))
+	engine := NewServer(svc, WithAdminToken("root"), WithTracerProvider(tp))
+
+	do(engine, nethttp.MethodGet, "/health", "", "")
+	if rec := do(engine, nethttp.MethodGet, "/team/get?team_name=backend", "root", ""); rec.Code != nethttp.StatusNotFound {
+		t.Fatalf("get team: %d %s", rec.Code, rec.Body)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want the service span and the request span", len(spans))
+	}
+	serviceSpan, requestSpan := spans[0], spans[1]
+	if requestSpan.Name() != "GET /team/get" || serviceSpan.Name() != "service.GetTeam" {
+		t.Fatalf("unexpected span names %q, %q", requestSpan.Name(), serviceSpan.Name())
+	}
+	if serviceSpan.Parent().SpanID() != requestSpan.SpanContext().SpanID() {
+		t.Fatalf("service span is not a child of the request span")
+	}
+}
@@ -0,0 +1,127 @@
+// Package metrics exposes service metrics in the Prometheus text format.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_reviewer"
+
+// LatencyBuckets bound request durations in seconds. 0.3 matches the latency
+// SLI, so the share of fast requests is read off a single bucket.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.5, 1, 2.5, 5}
+
+// Metrics holds the service collectors in a registry of its own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	errors          *prometheus.CounterVec
+	prsCreated      prometheus.Counter
+	prsMerged       prometheus.Counter
+	reassignments   *prometheus.CounterVec
+	noCandidate     prometheus.Counter
+}
+
+var _ service.Metrics = (*Metrics)(nil)
+
+// New returns metrics registered together with the Go runtime and process
+// collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by route, method and status code.",
+			Buckets:   LatencyBuckets,
+		}, []string{"route", "method", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "errors_total",
+			Help:      "Error responses by error code.",
+		}, []string{"code"}),
+		prsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pull_requests_created_total",
+			Help:      "Pull requests created.",
+		}),
+		prsMerged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pull_requests_merged_total",
+			Help:      "Pull requests merged.",
+		}),
+		reassignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_reassignments_total",
+			Help:      "Reviewers replaced, by what caused the replacement.",
+		}, []string{"source"}),
+		noCandidate: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "no_candidate_total",
+			Help:      "Operations that failed because no reviewer could be picked.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.errors,
+		m.prsCreated,
+		m.prsMerged,
+		m.reassignments,
+		m.noCandidate,
+	)
+	for _, source := range []string{service.ReassignManual, service.ReassignDeactivation, service.ReassignMembership, service.ReassignAvailability} {
+		m.reassignments.WithLabelValues(source)
+	}
+	return m
+}
+
+// Handler serves the registry.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records one handled request. route is the registered path
+// pattern, not the requested URL, so the label set stays bounded.
+func (m *Metrics) ObserveRequest(route, method string, status int, elapsed time.Duration) {
+	m.requestDuration.WithLabelValues(route, method, strconv.Itoa(status)).Observe(elapsed.Seconds())
+}
+
+// CountError records an error response with the given code.
+func (m *Metrics) CountError(code domain.ErrorCode) {
+	m.errors.WithLabelValues(string(code)).Inc()
+}
+
+func (m *Metrics) PullRequestCreated() {
+	m.prsCreated.Inc()
+}
+
+func (m *Metrics) PullRequestMerged() {
+	m.prsMerged.Inc()
+}
+
+func (m *Metrics) ReviewersReassigned(source string, n int) {
+	m.reassignments.WithLabelValues(source).Add(float64(n))
+}
+
+func (m *Metrics) NoCandidate() {
+	m.noCandidate.Inc()
+}
+
+// RegisterPool exports connection statistics of pool.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	m.registry.MustRegister(newPoolCollector(pool.Stat))
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMetricsExposition(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/pullRequest/create", http.MethodPost, http.StatusCreated, 250*time.Millisecond)
+	m.ObserveRequest("/pullRequest/create", http.MethodPost, http.StatusCreated, 400*time.Millisecond)
+	m.CountError(domain.ErrCodeNoCandidate)
+	m.PullRequestCreated()
+	m.PullRequestMerged()
+	m.ReviewersReassigned(service.ReassignDeactivation, 3)
+	m.NoCandidate()
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`pr_reviewer_http_request_duration_seconds_bucket{method="POST",route="/pullRequest/create",status="201",le="0.3"} 1`,
+		`pr_reviewer_http_request_duration_seconds_count{method="POST",route="/pullRequest/create",status="201"} 2`,
+		`pr_reviewer_http_errors_total{code="NO_CANDIDATE"} 1`,
+		`pr_reviewer_pull_requests_created_total 1`,
+		`pr_reviewer_pull_requests_merged_total 1`,
+		`pr_reviewer_reviewer_reassignments_total{source="deactivation"} 3`,
+		`pr_reviewer_reviewer_reassignments_total{source="manual"} 0`,
+		`pr_reviewer_no_candidate_total 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("exposition lacks %q:\n%s", want, body)
+		}
+	}
+}
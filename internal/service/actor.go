@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the identity of whoever requested the
+// change. Repositories record it in the audit log.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or an empty string.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
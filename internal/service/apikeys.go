@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// tokenPrefix marks tokens issued by the service so they are easy to spot in
+// configs and logs.
+const tokenPrefix = "prs_"
+
+// HashToken returns the form of an API token kept in storage.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey issues a key with the given role and returns it together with
+// its token. The token cannot be recovered later.
+func (s *Service) CreateAPIKey(ctx context.Context, name, role string) (domain.APIKey, string, error) {
+	ctx, span := s.startSpan(ctx, "CreateAPIKey")
+	defer span.End()
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return domain.APIKey{}, "", s.finish(span, err)
+	}
+	token := tokenPrefix + hex.EncodeToString(raw)
+
+	key, err := s.repo.CreateAPIKey(ctx, domain.APIKey{Name: name, Role: role}, HashToken(token))
+	if err != nil {
+		return domain.APIKey{}, "", s.finish(span, err)
+	}
+	return key, token, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	ctx, span := s.startSpan(ctx, "ListAPIKeys")
+	defer span.End()
+
+	keys, err := s.repo.ListAPIKeys(ctx)
+	return keys, s.finish(span, err)
+}
+
+// RevokeAPIKey disables the key; requests with its token are rejected from
+// then on. Revoking a revoked key changes nothing.
+func (s *Service) RevokeAPIKey(ctx context.Context, id int64) (domain.APIKey, error) {
+	ctx, span := s.startSpan(ctx, "RevokeAPIKey")
+	defer span.End()
+
+	key, err := s.repo.RevokeAPIKey(ctx, id, time.Now().UTC())
+	return key, s.finish(span, err)
+}
+
+// Authenticate returns the active key the token belongs to.
+func (s *Service) Authenticate(ctx context.Context, token string) (domain.APIKey, error) {
+	ctx, span := s.startSpan(ctx, "Authenticate")
+	defer span.End()
+
+	if token == "" {
+		return domain.APIKey{}, domain.NewUnauthorizedError()
+	}
+	key, err := s.repo.GetAPIKeyByHash(ctx, HashToken(token))
+	if err != nil {
+		var appErr *domain.AppError
+		if errors.As(err, &appErr) && appErr.Code == domain.ErrCodeNotFound {
+			return domain.APIKey{}, domain.NewUnauthorizedError()
+		}
+		return domain.APIKey{}, s.finish(span, err)
+	}
+	if key.RevokedAt != nil {
+		return domain.APIKey{}, domain.NewUnauthorizedError()
+	}
+	return key, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// CreateUnavailability stores a period during which the user is not picked
+// as a reviewer.
+func (s *Service) CreateUnavailability(ctx context.Context, period domain.Unavailability) (domain.Unavailability, error) {
+	ctx, span := s.startSpan(ctx, "CreateUnavailability", AttrUserID.String(period.UserID))
+	defer span.End()
+
+	created, err := s.repo.CreateUnavailability(ctx, period)
+	return created, s.finish(span, err)
+}
+
+// ListUnavailability returns the periods of the user, or of every user when
+// userID is empty, ordered by start.
+func (s *Service) ListUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	ctx, span := s.startSpan(ctx, "ListUnavailability", AttrUserID.String(userID))
+	defer span.End()
+
+	periods, err := s.repo.ListUnavailability(ctx, userID)
+	return periods, s.finish(span, err)
+}
+
+func (s *Service) DeleteUnavailability(ctx context.Context, id int64) error {
+	ctx, span := s.startSpan(ctx, "DeleteUnavailability")
+	defer span.End()
+
+	return s.finish(span, s.repo.DeleteUnavailability(ctx, id))
+}
+
+// ReassignUnavailableReviewers moves the OPEN reviews of users whose
+// unavailability has started by now to available teammates. Every period is
+// handled once.
+func (s *Service) ReassignUnavailableReviewers(ctx context.Context, now time.Time) ([]domain.ReviewerReplacement, error) {
+	ctx, span := s.startSpan(ctx, "ReassignUnavailableReviewers")
+	defer span.End()
+
+	replacements, err := s.repo.ReassignUnavailableReviewers(ctx, now, s.picker.PickOne)
+	if err != nil {
+		return nil, s.finish(span, err)
+	}
+	s.reassigned(ctx, ReassignAvailability, len(replacements))
+	return replacements, nil
+}
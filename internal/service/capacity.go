@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// SetUserMaxOpenReviews overrides the team cap on OPEN reviews for the user;
+// nil restores the team default and 0 lifts the cap.
+func (s *Service) SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error) {
+	ctx, span := s.startSpan(ctx, "SetUserMaxOpenReviews", AttrUserID.String(userID))
+	defer span.End()
+
+	user, err := s.repo.SetUserMaxOpenReviews(ctx, userID, maxOpenReviews)
+	return user, s.finish(span, err)
+}
+
+// WithinCapacity returns the candidates below their open review cap,
+// preserving order, and the ids of those left out for reaching it.
+func WithinCapacity(candidates []Candidate) ([]Candidate, []string) {
+	var available []Candidate
+	var skipped []string
+	for _, c := range candidates {
+		if c.MaxOpenReviews > 0 && c.OpenReviews >= c.MaxOpenReviews {
+			skipped = append(skipped, c.UserID)
+			continue
+		}
+		available = append(available, c)
+	}
+	return available, skipped
+}
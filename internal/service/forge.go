@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// LinkForgeAccount maps a platform login to a user, replacing the previous
+// mapping of that login. Logins are case-insensitive on the platforms, so they
+// are stored and looked up in lower case.
+func (s *Service) LinkForgeAccount(ctx context.Context, account domain.ForgeAccount) (domain.ForgeAccount, error) {
+	ctx, span := s.startSpan(ctx, "LinkForgeAccount", AttrForge.String(account.Forge), AttrUserID.String(account.UserID))
+	defer span.End()
+
+	account.Login = strings.ToLower(account.Login)
+	linked, err := s.repo.LinkForgeAccount(ctx, account)
+	return linked, s.finish(span, err)
+}
+
+func (s *Service) UnlinkForgeAccount(ctx context.Context, forge, login string) error {
+	ctx, span := s.startSpan(ctx, "UnlinkForgeAccount", AttrForge.String(forge))
+	defer span.End()
+
+	return s.finish(span, s.repo.UnlinkForgeAccount(ctx, forge, strings.ToLower(login)))
+}
+
+// ListForgeAccounts returns login mappings of the platform; an empty forge
+// means every platform.
+func (s *Service) ListForgeAccounts(ctx context.Context, forge string) ([]domain.ForgeAccount, error) {
+	ctx, span := s.startSpan(ctx, "ListForgeAccounts", AttrForge.String(forge))
+	defer span.End()
+
+	accounts, err := s.repo.ListForgeAccounts(ctx, forge)
+	return accounts, s.finish(span, err)
+}
+
+// ResolveForgeLogin returns the id of the user the platform login is linked to.
+func (s *Service) ResolveForgeLogin(ctx context.Context, forge, login string) (string, error) {
+	ctx, span := s.startSpan(ctx, "ResolveForgeLogin", AttrForge.String(forge))
+	defer span.End()
+
+	userID, err := s.repo.ResolveForgeLogin(ctx, forge, strings.ToLower(login))
+	return userID, s.finish(span, err)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// Sources of reviewer reassignments reported to Metrics.
+const (
+	ReassignManual       = "manual"
+	ReassignDeactivation = "deactivation"
+	ReassignMembership   = "membership"
+	ReassignAvailability = "availability"
+)
+
+// Metrics receives counts of domain events. Calls that fail report nothing
+// except NoCandidate.
+type Metrics interface {
+	PullRequestCreated()
+	PullRequestMerged()
+	ReviewersReassigned(source string, n int)
+	NoCandidate()
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithMetrics reports domain events to m.
+func WithMetrics(m Metrics) Option {
+	return func(s *Service) {
+		s.metrics = m
+	}
+}
+
+type noMetrics struct{}
+
+func (noMetrics) PullRequestCreated()             {}
+func (noMetrics) PullRequestMerged()              {}
+func (noMetrics) ReviewersReassigned(string, int) {}
+func (noMetrics) NoCandidate()                    {}
+
+// observe reports a NO_CANDIDATE or REVIEW_CAP_REACHED failure and returns
+// err unchanged.
+func (s *Service) observe(err error) error {
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) && (appErr.Code == domain.ErrCodeNoCandidate || appErr.Code == domain.ErrCodeCapReached) {
+		s.metrics.NoCandidate()
+	}
+	return err
+}
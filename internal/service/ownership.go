@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// OwnerCandidates are the reviewers able to satisfy a matched ownership rule.
+type OwnerCandidates struct {
+	Rule       domain.OwnershipRule
+	Candidates []Candidate
+}
+
+// ValidatePattern checks that pattern is a usable ownership pattern.
+func ValidatePattern(pattern string) error {
+	segments := patternSegments(pattern)
+	if len(segments) == 0 {
+		return errors.New("pattern must not be empty")
+	}
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return errors.New("pattern is malformed")
+		}
+	}
+	return nil
+}
+
+// MatchPath reports whether the repository-relative file path matches a
+// CODEOWNERS-style pattern:
+//
+//   - "*", "?" and "[...]" match within one path segment and "**" matches any
+//     number of segments;
+//   - a pattern without a slash, such as "*.go", matches at any depth, while
+//     one with a slash is relative to the repository root;
+//   - a trailing slash, or a last segment without wildcards, matches the
+//     directory and everything below it, so "deploy" and "deploy/" both
+//     match "deploy/k8s/app.yaml".
+func MatchPath(pattern, filePath string) bool {
+	segments := patternSegments(pattern)
+	if len(segments) == 0 {
+		return false
+	}
+	if !strings.Contains(strings.Trim(pattern, "/"), "/") && !strings.HasPrefix(pattern, "/") {
+		segments = append([]string{"**"}, segments...)
+	}
+	last := segments[len(segments)-1]
+	if strings.HasSuffix(pattern, "/") || !strings.ContainsAny(last, "*?[") {
+		segments = append(segments, "**")
+	}
+	return matchSegments(segments, strings.Split(strings.Trim(filePath, "/"), "/"))
+}
+
+// MatchingRules returns the rules matching at least one of the files, in the
+// order given.
+func MatchingRules(rules []domain.OwnershipRule, files []string) []domain.OwnershipRule {
+	var matched []domain.OwnershipRule
+	for _, rule := range rules {
+		if slices.ContainsFunc(files, func(file string) bool { return MatchPath(rule.Pattern, file) }) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// PickWithOwners picks one reviewer for every matched rule not already
+// covered by an earlier pick, then tops the PR up to limit with teammates of
+// the author. Owners count towards limit but are kept even beyond it. The
+// required tags no owner has are passed on to pick, which decides whether to
+// cover them. It fails if a rule has no candidate at all.
+func PickWithOwners(owners []OwnerCandidates, teammates []Candidate, tags []string, limit int, pick func([]Candidate, []string, int) []string) ([]string, error) {
+	var chosen []string
+	chosenTags := make(map[string]bool)
+	for _, owner := range owners {
+		if slices.ContainsFunc(owner.Candidates, func(c Candidate) bool { return slices.Contains(chosen, c.UserID) }) {
+			continue
+		}
+		ids := pick(owner.Candidates, nil, 1)
+		if len(ids) == 0 {
+			return nil, domain.NewNoOwnerError(owner.Rule.Pattern)
+		}
+		chosen = append(chosen, ids[0])
+		for _, c := range owner.Candidates {
+			if c.UserID == ids[0] {
+				for _, tag := range c.Tags {
+					chosenTags[tag] = true
+				}
+			}
+		}
+	}
+
+	uncovered := slices.DeleteFunc(slices.Clone(tags), func(tag string) bool { return chosenTags[tag] })
+	rest := slices.DeleteFunc(slices.Clone(teammates), func(c Candidate) bool { return slices.Contains(chosen, c.UserID) })
+	return append(chosen, pick(rest, uncovered, max(limit-len(chosen), 0))...), nil
+}
+
+func patternSegments(pattern string) []string {
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// CreateOwnershipRule stores a rule; its team or users must exist.
+func (s *Service) CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error) {
+	ctx, span := s.startSpan(ctx, "CreateOwnershipRule", AttrRepository.String(rule.Repository))
+	defer span.End()
+
+	created, err := s.repo.CreateOwnershipRule(ctx, rule)
+	return created, s.finish(span, err)
+}
+
+// ListOwnershipRules returns the rules of the repository in the order they
+// are applied; an empty repository means every repository.
+func (s *Service) ListOwnershipRules(ctx context.Context, repository string) ([]domain.OwnershipRule, error) {
+	ctx, span := s.startSpan(ctx, "ListOwnershipRules", AttrRepository.String(repository))
+	defer span.End()
+
+	rules, err := s.repo.ListOwnershipRules(ctx, repository)
+	return rules, s.finish(span, err)
+}
+
+func (s *Service) DeleteOwnershipRule(ctx context.Context, id int64) error {
+	ctx, span := s.startSpan(ctx, "DeleteOwnershipRule", AttrRuleID.Int64(id))
+	defer span.End()
+
+	return s.finish(span, s.repo.DeleteOwnershipRule(ctx, id))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"deploy/**", "deploy/k8s/app.yaml", true},
+		{"deploy/**", "services/deploy/app.yaml", false},
+		{"/deploy", "deploy/app.yaml", true},
+		{"deploy/", "services/deploy/app.yaml", true},
+		{"deploy", "deployment.md", false},
+		{"*.go", "internal/service/service.go", true},
+		{"*.go", "go.mod", false},
+		{"docs/*", "docs/index.md", true},
+		{"docs/*", "docs/api/index.md", false},
+		{"apps/**/migrations", "apps/billing/db/migrations/001.sql", true},
+		{"apps/*/Dockerfile", "apps/web/Dockerfile", true},
+		{"apps/*/Dockerfile", "apps/Dockerfile", false},
+		{"Makefile", "tools/Makefile", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, pattern := range []string{"", "/", "deploy/[a"} {
+		if ValidatePattern(pattern) == nil {
+			t.Errorf("pattern %q must be rejected", pattern)
+		}
+	}
+	if err := ValidatePattern("deploy/**"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPickWithOwners(t *testing.T) {
+	pickFirst := func(candidates []Candidate, _ []string, limit int) []string {
+		return candidateIDs(candidates)[:min(limit, len(candidates))]
+	}
+	infra := OwnerCandidates{Rule: domain.OwnershipRule{Pattern: "deploy/**"}, Candidates: []Candidate{{UserID: "i1"}, {UserID: "u2"}}}
+	security := OwnerCandidates{Rule: domain.OwnershipRule{Pattern: "auth/**"}, Candidates: []Candidate{{UserID: "s1"}, {UserID: "i1"}}}
+	teammates := []Candidate{{UserID: "i1"}, {UserID: "u1"}, {UserID: "u2"}}
+
+	got, err := PickWithOwners([]OwnerCandidates{infra, security}, teammates, nil, 2, pickFirst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(got, []string{"i1", "u1"}) {
+		t.Fatalf("i1 covers both rules and a teammate tops up: got %v", got)
+	}
+
+	got, err = PickWithOwners([]OwnerCandidates{infra, {Rule: security.Rule, Candidates: []Candidate{{UserID: "s1"}}}}, teammates, nil, 1, pickFirst)
+	if err != nil || !slices.Equal(got, []string{"i1", "s1"}) {
+		t.Fatalf("owners are kept beyond the limit: got %v, %v", got, err)
+	}
+
+	_, err = PickWithOwners([]OwnerCandidates{{Rule: security.Rule}}, teammates, nil, 2, pickFirst)
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrCodeNoCandidate {
+		t.Fatalf("expected NO_CANDIDATE for a rule without candidates, got %v", err)
+	}
+}
+
+func TestPickWithOwnersPassesUncoveredTags(t *testing.T) {
+	var passed [][]string
+	pickFirst := func(candidates []Candidate, tags []string, limit int) []string {
+		passed = append(passed, tags)
+		return candidateIDs(candidates)[:min(limit, len(candidates))]
+	}
+	infra := OwnerCandidates{Rule: domain.OwnershipRule{Pattern: "deploy/**"}, Candidates: []Candidate{{UserID: "i1", Tags: []string{"go"}}}}
+	teammates := []Candidate{{UserID: "b1", Tags: []string{"go", "sql"}}, {UserID: "f1", Tags: []string{"frontend"}}}
+
+	got, err := PickWithOwners([]OwnerCandidates{infra}, teammates, []string{"go", "sql"}, 2, pickFirst)
+	if err != nil || !slices.Equal(got, []string{"i1", "b1"}) {
+		t.Fatalf("unexpected pick: got %v, %v", got, err)
+	}
+	if len(passed) != 2 || passed[0] != nil || !slices.Equal(passed[1], []string{"sql"}) {
+		t.Fatalf("owners are picked without tags and teammates get the tags the owner lacks: %v", passed)
+	}
+
+	passed = nil
+	if _, err := PickWithOwners(nil, teammates, []string{"rust"}, 2, pickFirst); err != nil {
+		t.Fatalf("a tag nobody has is left to the picker: %v", err)
+	}
+	if len(passed) != 1 || !slices.Equal(passed[0], []string{"rust"}) {
+		t.Fatalf("unexpected tags passed: %v", passed)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Page size limits for paginated lists.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// Orders of pull request lists by creation time.
+const (
+	OrderNewest = "newest"
+	OrderOldest = "oldest"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last item of a page of pull requests. The next page
+// starts right after it.
+type Cursor struct {
+	CreatedAt  time.Time `json:"t"`
+	Repository string    `json:"r,omitempty"`
+	ID         string    `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Follows reports whether an item created at createdAt with the given
+// repository and id comes after the cursor. Items are ordered by creation
+// time, then by repository and id, newest first unless ascending is set.
+func (c Cursor) Follows(createdAt time.Time, repository, id string, ascending bool) bool {
+	if !createdAt.Equal(c.CreatedAt) {
+		return createdAt.After(c.CreatedAt) == ascending
+	}
+	if repository != c.Repository {
+		return (repository > c.Repository) == ascending
+	}
+	if id == c.ID {
+		return false
+	}
+	return (id > c.ID) == ascending
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode.
+func DecodeCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, errInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return Cursor{}, errInvalidCursor
+	}
+	return c, nil
+}
+
+// pageSize applies the default and the cap to a requested page size.
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{CreatedAt: time.Date(2025, 10, 24, 12, 34, 56, 789000, time.UTC), Repository: "shop", ID: "pr-1"}
+
+	got, err := DecodeCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor returned error: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.Repository != want.Repository || got.ID != want.ID {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, value := range []string{"", "not base64!", Cursor{ID: "pr-1"}.Encode(), Cursor{CreatedAt: time.Now()}.Encode()} {
+		if _, err := DecodeCursor(value); err == nil {
+			t.Fatalf("DecodeCursor(%q) accepted invalid cursor", value)
+		}
+	}
+}
+
+func TestCursorFollows(t *testing.T) {
+	now := time.Now()
+	c := Cursor{CreatedAt: now, ID: "pr-2"}
+
+	tests := []struct {
+		name       string
+		createdAt  time.Time
+		repository string
+		id         string
+		ascending  bool
+		want       bool
+	}{
+		{"OlderWhenNewestFirst", now.Add(-time.Second), "", "pr-9", false, true},
+		{"NewerWhenNewestFirst", now.Add(time.Second), "", "pr-1", false, false},
+		{"OlderWhenOldestFirst", now.Add(-time.Second), "", "pr-9", true, false},
+		{"NewerWhenOldestFirst", now.Add(time.Second), "", "pr-1", true, true},
+		{"TieLowerIDWhenNewestFirst", now, "", "pr-1", false, true},
+		{"TieHigherIDWhenNewestFirst", now, "", "pr-3", false, false},
+		{"TieHigherIDWhenOldestFirst", now, "", "pr-3", true, true},
+		{"TieHigherRepositoryWhenNewestFirst", now, "shop", "pr-1", false, false},
+		{"TieHigherRepositoryWhenOldestFirst", now, "shop", "pr-1", true, true},
+		{"CursorItemItself", now, "", "pr-2", false, false},
+		{"CursorItemItselfAscending", now, "", "pr-2", true, false},
+	}
+	for _, tt := range tests {
+		if got := c.Follows(tt.createdAt, tt.repository, tt.id, tt.ascending); got != tt.want {
+			t.Fatalf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
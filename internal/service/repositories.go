@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// CreateRepository registers a repository; its teams must exist.
+func (s *Service) CreateRepository(ctx context.Context, repo domain.Repository) (domain.Repository, error) {
+	ctx, span := s.startSpan(ctx, "CreateRepository", AttrRepository.String(repo.Name))
+	defer span.End()
+
+	repo.Teams = uniqueSorted(repo.Teams)
+	created, err := s.repo.CreateRepository(ctx, repo)
+	return created, s.finish(span, err)
+}
+
+func (s *Service) GetRepository(ctx context.Context, name string) (domain.Repository, error) {
+	ctx, span := s.startSpan(ctx, "GetRepository", AttrRepository.String(name))
+	defer span.End()
+
+	repo, err := s.repo.GetRepository(ctx, name)
+	return repo, s.finish(span, err)
+}
+
+// ListRepositories returns repositories ordered by name; a non-empty teamName
+// keeps only the repositories the team works in.
+func (s *Service) ListRepositories(ctx context.Context, teamName string) ([]domain.Repository, error) {
+	ctx, span := s.startSpan(ctx, "ListRepositories", AttrTeamName.String(teamName))
+	defer span.End()
+
+	repos, err := s.repo.ListRepositories(ctx, teamName)
+	return repos, s.finish(span, err)
+}
+
+// SetRepositoryTeams replaces the teams linked to the repository.
+func (s *Service) SetRepositoryTeams(ctx context.Context, name string, teams []string) (domain.Repository, error) {
+	ctx, span := s.startSpan(ctx, "SetRepositoryTeams", AttrRepository.String(name))
+	defer span.End()
+
+	repo, err := s.repo.SetRepositoryTeams(ctx, name, uniqueSorted(teams))
+	return repo, s.finish(span, err)
+}
+
+// DeleteRepository removes a repository without pull requests together with
+// its ownership rules.
+func (s *Service) DeleteRepository(ctx context.Context, name string) error {
+	ctx, span := s.startSpan(ctx, "DeleteRepository", AttrRepository.String(name))
+	defer span.End()
+
+	return s.finish(span, s.repo.DeleteRepository(ctx, name))
+}
+
+func uniqueSorted(values []string) []string {
+	return slices.Compact(slices.Sorted(slices.Values(values)))
+}
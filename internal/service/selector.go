@@ -1,15 +1,55 @@
 package service
 
 import (
+	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 )
 
+// Supported reviewer selection strategies.
+const (
+	StrategyRandom      = "random"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyTagCoverage = "tag_coverage"
+)
+
+// Candidate is an eligible reviewer together with its current review load
+// and skill tags. MaxOpenReviews is the effective cap of the user, 0 means none.
+type Candidate struct {
+	UserID         string
+	OpenReviews    int
+	MaxOpenReviews int
+	Tags           []string
+}
+
 // ReviewerPicker defines selection helpers used by the repository layer.
 type ReviewerPicker interface {
-	Pick(ids []string, limit int) []string
-	PickOne(ids []string) (string, bool)
+	Pick(candidates []Candidate, limit int) []string
+	PickOne(candidates []Candidate) (string, bool)
+}
+
+// TagPicker is a ReviewerPicker that also takes the required skill tags of
+// a PR into account when assigning its reviewers.
+type TagPicker interface {
+	ReviewerPicker
+	PickCovering(candidates []Candidate, tags []string, limit int) []string
+}
+
+// NewPicker builds a picker for the named strategy.
+func NewPicker(strategy string) (ReviewerPicker, error) {
+	switch strategy {
+	case "", StrategyRandom:
+		return NewRandomPicker(), nil
+	case StrategyLeastLoaded:
+		return NewLeastLoadedPicker(), nil
+	case StrategyTagCoverage:
+		return NewTagCoveragePicker(NewRandomPicker()), nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q", strategy)
+	}
 }
 
 // RandomPicker randomly shuffles candidates and picks deterministic subset.
@@ -24,29 +64,137 @@ func NewRandomPicker() *RandomPicker {
 }
 
 // Pick returns up to "limit" unique ids randomly.
-func (p *RandomPicker) Pick(ids []string, limit int) []string {
+func (p *RandomPicker) Pick(candidates []Candidate, limit int) []string {
+	if limit <= 0 || len(candidates) == 0 {
+		return nil
+	}
+
+	shuffled := p.shuffle(candidates)
+	if len(shuffled) > limit {
+		shuffled = shuffled[:limit]
+	}
+
+	return candidateIDs(shuffled)
+}
+
+// PickOne returns a single random id.
+func (p *RandomPicker) PickOne(candidates []Candidate) (string, bool) {
+	return firstID(p.Pick(candidates, 1))
+}
+
+func (p *RandomPicker) shuffle(candidates []Candidate) []Candidate {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if limit <= 0 || len(ids) == 0 {
+	copied := append([]Candidate(nil), candidates...)
+	p.rand.Shuffle(len(copied), func(i, j int) {
+		copied[i], copied[j] = copied[j], copied[i]
+	})
+	return copied
+}
+
+// LeastLoadedPicker prefers candidates with the fewest OPEN reviews and breaks
+// ties randomly.
+type LeastLoadedPicker struct {
+	random *RandomPicker
+}
+
+// NewLeastLoadedPicker returns a load-aware picker seeded with current time.
+func NewLeastLoadedPicker() *LeastLoadedPicker {
+	return &LeastLoadedPicker{random: NewRandomPicker()}
+}
+
+// Pick returns up to "limit" ids ordered by ascending review load.
+func (p *LeastLoadedPicker) Pick(candidates []Candidate, limit int) []string {
+	if limit <= 0 || len(candidates) == 0 {
 		return nil
 	}
 
-	copyIDs := append([]string(nil), ids...)
-	p.rand.Shuffle(len(copyIDs), func(i, j int) {
-		copyIDs[i], copyIDs[j] = copyIDs[j], copyIDs[i]
+	ordered := p.random.shuffle(candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].OpenReviews < ordered[j].OpenReviews
 	})
 
-	if len(copyIDs) > limit {
-		copyIDs = copyIDs[:limit]
+	if len(ordered) > limit {
+		ordered = ordered[:limit]
 	}
 
-	return copyIDs
+	return candidateIDs(ordered)
 }
 
-// PickOne returns a single random id.
-func (p *RandomPicker) PickOne(ids []string) (string, bool) {
-	ids = p.Pick(ids, 1)
+// PickOne returns the least loaded candidate.
+func (p *LeastLoadedPicker) PickOne(candidates []Candidate) (string, bool) {
+	return firstID(p.Pick(candidates, 1))
+}
+
+// TagCoveragePicker first picks a holder for every required tag not yet
+// covered and leaves the remaining slots to the base picker.
+type TagCoveragePicker struct {
+	base ReviewerPicker
+}
+
+// NewTagCoveragePicker returns a tag-aware picker on top of base.
+func NewTagCoveragePicker(base ReviewerPicker) *TagCoveragePicker {
+	return &TagCoveragePicker{base: base}
+}
+
+// Pick delegates to the base picker.
+func (p *TagCoveragePicker) Pick(candidates []Candidate, limit int) []string {
+	return p.base.Pick(candidates, limit)
+}
+
+// PickOne delegates to the base picker.
+func (p *TagCoveragePicker) PickOne(candidates []Candidate) (string, bool) {
+	return p.base.PickOne(candidates)
+}
+
+// PickCovering returns up to "limit" ids so that every tag with a holder
+// among the candidates is covered while slots remain. Tags nobody has are
+// skipped.
+func (p *TagCoveragePicker) PickCovering(candidates []Candidate, tags []string, limit int) []string {
+	if limit <= 0 || len(candidates) == 0 {
+		return nil
+	}
+
+	var chosen []string
+	covered := make(map[string]bool)
+	rest := slices.Clone(candidates)
+	for _, tag := range tags {
+		if len(chosen) == limit {
+			break
+		}
+		if covered[tag] {
+			continue
+		}
+		holders := slices.DeleteFunc(slices.Clone(rest), func(c Candidate) bool { return !slices.Contains(c.Tags, tag) })
+		id, ok := p.base.PickOne(holders)
+		if !ok {
+			continue
+		}
+		chosen = append(chosen, id)
+		rest = slices.DeleteFunc(rest, func(c Candidate) bool {
+			if c.UserID != id {
+				return false
+			}
+			for _, t := range c.Tags {
+				covered[t] = true
+			}
+			return true
+		})
+	}
+
+	return append(chosen, p.base.Pick(rest, limit-len(chosen))...)
+}
+
+func candidateIDs(candidates []Candidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.UserID)
+	}
+	return ids
+}
+
+func firstID(ids []string) (string, bool) {
 	if len(ids) == 0 {
 		return "", false
 	}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -9,8 +10,9 @@ func TestRandomPickerPick(t *testing.T) {
 	picker := NewRandomPicker()
 	picker.rand = rand.New(rand.NewSource(1))
 
-	ids := []string{"u1", "u2", "u3"}
-	result := picker.Pick(ids, 2)
+	candidates := []Candidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+	ids := candidateIDs(candidates)
+	result := picker.Pick(candidates, 2)
 
 	if len(result) != 2 {
 		t.Fatalf("expected 2 ids, got %v", result)
@@ -21,8 +23,8 @@ func TestRandomPickerPick(t *testing.T) {
 	if !contains(ids, result[0]) || !contains(ids, result[1]) {
 		t.Fatalf("unexpected reviewers %v", result)
 	}
-	if ids[0] != "u1" || ids[1] != "u2" || ids[2] != "u3" {
-		t.Fatalf("original slice mutated: %v", ids)
+	if candidates[0].UserID != "u1" || candidates[1].UserID != "u2" || candidates[2].UserID != "u3" {
+		t.Fatalf("original slice mutated: %v", candidates)
 	}
 }
 
@@ -30,8 +32,7 @@ func TestRandomPickerPickOne(t *testing.T) {
 	picker := NewRandomPicker()
 	picker.rand = rand.New(rand.NewSource(5))
 
-	ids := []string{"u1"}
-	value, ok := picker.PickOne(ids)
+	value, ok := picker.PickOne([]Candidate{{UserID: "u1"}})
 	if !ok || value != "u1" {
 		t.Fatalf("unexpected pick result: %v %v", value, ok)
 	}
@@ -41,6 +42,110 @@ func TestRandomPickerPickOne(t *testing.T) {
 	}
 }
 
+func TestLeastLoadedPickerPrefersLowestLoad(t *testing.T) {
+	picker := NewLeastLoadedPicker()
+	picker.random.rand = rand.New(rand.NewSource(1))
+
+	candidates := []Candidate{
+		{UserID: "busy", OpenReviews: 7},
+		{UserID: "idle", OpenReviews: 0},
+		{UserID: "some", OpenReviews: 2},
+		{UserID: "loaded", OpenReviews: 5},
+	}
+
+	result := picker.Pick(candidates, 2)
+	if len(result) != 2 || result[0] != "idle" || result[1] != "some" {
+		t.Fatalf("expected least loaded reviewers first, got %v", result)
+	}
+
+	value, ok := picker.PickOne(candidates)
+	if !ok || value != "idle" {
+		t.Fatalf("unexpected pick result: %v %v", value, ok)
+	}
+}
+
+func TestLeastLoadedPickerBreaksTiesRandomly(t *testing.T) {
+	picker := NewLeastLoadedPicker()
+	picker.random.rand = rand.New(rand.NewSource(1))
+
+	candidates := []Candidate{
+		{UserID: "a", OpenReviews: 1},
+		{UserID: "b", OpenReviews: 1},
+		{UserID: "c", OpenReviews: 3},
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		value, _ := picker.PickOne(candidates)
+		if value == "c" {
+			t.Fatalf("more loaded candidate picked over tied ones")
+		}
+		seen[value] = true
+	}
+	if !seen["a"] || !seen["b"] {
+		t.Fatalf("expected both tied candidates to be picked eventually, got %v", seen)
+	}
+}
+
+func TestNewPicker(t *testing.T) {
+	if p, err := NewPicker(""); err != nil {
+		t.Fatalf("default strategy returned error: %v", err)
+	} else if _, ok := p.(*RandomPicker); !ok {
+		t.Fatalf("expected random picker by default, got %T", p)
+	}
+	if p, err := NewPicker(StrategyLeastLoaded); err != nil {
+		t.Fatalf("least_loaded strategy returned error: %v", err)
+	} else if _, ok := p.(*LeastLoadedPicker); !ok {
+		t.Fatalf("expected least loaded picker, got %T", p)
+	}
+	if p, err := NewPicker(StrategyTagCoverage); err != nil {
+		t.Fatalf("tag_coverage strategy returned error: %v", err)
+	} else if _, ok := p.(TagPicker); !ok {
+		t.Fatalf("expected tag-aware picker, got %T", p)
+	}
+	if _, err := NewPicker("round_robin"); err == nil {
+		t.Fatalf("expected error for unknown strategy")
+	}
+}
+
+func TestTagCoveragePickerCoversTags(t *testing.T) {
+	picker := NewTagCoveragePicker(NewLeastLoadedPicker())
+	candidates := []Candidate{
+		{UserID: "b1", OpenReviews: 0, Tags: []string{"go"}},
+		{UserID: "b2", OpenReviews: 1, Tags: []string{"go", "sql"}},
+		{UserID: "f1", OpenReviews: 5, Tags: []string{"frontend"}},
+		{UserID: "f2", OpenReviews: 3, Tags: []string{"frontend"}},
+	}
+
+	if got := picker.PickCovering(candidates, []string{"frontend"}, 2); !slices.Equal(got, []string{"f2", "b1"}) {
+		t.Fatalf("the least loaded frontend holder comes first: %v", got)
+	}
+	if got := picker.PickCovering(candidates, []string{"sql", "go", "frontend"}, 2); !slices.Equal(got, []string{"b2", "f2"}) {
+		t.Fatalf("b2 covers sql and go, f2 covers frontend: %v", got)
+	}
+	if got := picker.PickCovering(candidates, []string{"rust", "frontend", "sql"}, 1); !slices.Equal(got, []string{"f2"}) {
+		t.Fatalf("tags nobody has are skipped and the limit holds: %v", got)
+	}
+	if got := picker.Pick(candidates, 1); !slices.Equal(got, []string{"b1"}) {
+		t.Fatalf("Pick ignores tags: %v", got)
+	}
+}
+
+func TestWithinCapacity(t *testing.T) {
+	candidates := []Candidate{
+		{UserID: "u1", OpenReviews: 3, MaxOpenReviews: 3},
+		{UserID: "u2", OpenReviews: 9},
+		{UserID: "u3", OpenReviews: 1, MaxOpenReviews: 2},
+	}
+	available, skipped := WithinCapacity(candidates)
+	if got := candidateIDs(available); len(got) != 2 || got[0] != "u2" || got[1] != "u3" || len(skipped) != 1 || skipped[0] != "u1" {
+		t.Fatalf("unexpected result: %v %v", got, skipped)
+	}
+	if _, skipped := WithinCapacity(available); len(skipped) != 0 {
+		t.Fatalf("no candidate is at its cap: %v", skipped)
+	}
+}
+
 func contains(list []string, candidate string) bool {
 	for _, item := range list {
 		if item == candidate {
@@ -2,68 +2,486 @@ package service
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// CreatePullRequestInput carries payload for PR creation.
+// CreatePullRequestInput carries payload for PR creation. A draft is created
+// without reviewers. PullRequestID must be unique within Repository, which
+// must be registered unless empty. ChangedFiles are matched against the
+// ownership rules of Repository whenever reviewers are assigned.
 type CreatePullRequestInput struct {
 	PullRequestID   string
 	PullRequestName string
 	AuthorID        string
+	Draft           bool
+	Repository      string
+	ChangedFiles    []string
+	RequiredTags    []string
+}
+
+// TeamSettingsUpdate lists the team settings to change; nil fields keep their
+// current value.
+type TeamSettingsUpdate struct {
+	RequiredReviewers *int
+	MinReviewers      *int
+	MaxOpenReviews    *int
+}
+
+// Transition is a pull request status change allowed only from the listed
+// statuses. Reason is recorded in the audit log when the status changes.
+type Transition struct {
+	To     string
+	From   []string
+	Reason string
+}
+
+// Pull request lifecycle. Marking a draft ready assigns reviewers; a reopened
+// PR keeps the reviewers it had when it was closed.
+var (
+	TransitionReady  = Transition{To: domain.StatusOpen, From: []string{domain.StatusDraft}, Reason: domain.AuditReadyForReview}
+	TransitionMerge  = Transition{To: domain.StatusMerged, From: []string{domain.StatusOpen}, Reason: domain.AuditMerged}
+	TransitionClose  = Transition{To: domain.StatusClosed, From: []string{domain.StatusDraft, domain.StatusOpen}, Reason: domain.AuditClosed}
+	TransitionReopen = Transition{To: domain.StatusOpen, From: []string{domain.StatusClosed}, Reason: domain.AuditReopened}
+)
+
+// Allows reports whether a pull request in status from may take the transition.
+// Repeating a transition the PR has already taken is allowed and changes nothing.
+func (t Transition) Allows(from string) bool {
+	if from == t.To {
+		return true
+	}
+	for _, status := range t.From {
+		if status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// UserReviewsFilter narrows and pages the reviews of a user. Without Statuses
+// every PR except CLOSED ones is returned. CreatedFrom is inclusive and
+// CreatedTo exclusive. Service.GetUserReviews sets Limit to a valid page size.
+type UserReviewsFilter struct {
+	Statuses    []string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	After       *Cursor
+	Limit       int
+}
+
+// PullRequestFilter narrows, orders and pages the pull request list. Empty
+// fields match every PR; TeamName matches the author's current team and
+// NameContains is case-insensitive. Time ranges include From and exclude To.
+// Service.ListPullRequests sets Order and Limit to valid values.
+type PullRequestFilter struct {
+	Repository   string
+	AuthorID     string
+	TeamName     string
+	ReviewerID   string
+	Statuses     []string
+	NameContains string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	MergedFrom   *time.Time
+	MergedTo     *time.Time
+	Order        string
+	After        *Cursor
+	Limit        int
+}
+
+// DeactivateUsersInput selects users for bulk deactivation: a whole team,
+// explicit user ids, or both.
+type DeactivateUsersInput struct {
+	TeamName string
+	UserIDs  []string
+}
+
+// RemoveTeamMembersInput detaches users from a team. With ReassignReviews their
+// OPEN reviews are handed to active members of the author's team.
+type RemoveTeamMembersInput struct {
+	TeamName        string
+	UserIDs         []string
+	ReassignReviews bool
+}
+
+// MoveTeamMemberInput moves a user into another team. With ReassignReviews the
+// user's OPEN reviews of PRs authored outside the new team are handed to active
+// members of the author's team.
+type MoveTeamMemberInput struct {
+	UserID          string
+	TeamName        string
+	ReassignReviews bool
+}
+
+// WebhookDeliveryFilter selects recent outbox entries of one webhook, newest
+// first. An empty Status matches every state.
+type WebhookDeliveryFilter struct {
+	WebhookID int64
+	Status    string
+	Limit     int
 }
 
 // Service orchestrates domain logic.
 type Service struct {
-	repo   Repository
-	picker ReviewerPicker
+	repo    Repository
+	picker  ReviewerPicker
+	metrics Metrics
+	tracer  trace.Tracer
 }
 
 // Repository defines required storage methods to satisfy business flows.
 type Repository interface {
 	CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error)
 	GetTeam(ctx context.Context, teamName string) (domain.Team, error)
+	UpdateTeamSettings(ctx context.Context, teamName string, update TeamSettingsUpdate) (domain.Team, error)
+	AddTeamMembers(ctx context.Context, teamName string, members []domain.TeamMember) (domain.Team, error)
+	RemoveTeamMembers(ctx context.Context, input RemoveTeamMembersInput, pick func([]Candidate) (string, bool)) (domain.MembershipChange, error)
+	MoveTeamMember(ctx context.Context, input MoveTeamMemberInput, pick func([]Candidate) (string, bool)) (domain.MembershipChange, error)
 	SetUserActive(ctx context.Context, userID string, isActive bool) (domain.User, error)
-	CreatePullRequest(ctx context.Context, input CreatePullRequestInput, pick func([]string, int) []string) (domain.PullRequest, error)
-	MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error)
-	ReassignReviewer(ctx context.Context, prID, oldUserID string, pick func([]string) (string, bool)) (domain.PullRequest, string, error)
-	GetUserReviews(ctx context.Context, userID string) (domain.UserReviews, error)
+	SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error)
+	SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error)
+	CreateUnavailability(ctx context.Context, period domain.Unavailability) (domain.Unavailability, error)
+	ListUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error)
+	DeleteUnavailability(ctx context.Context, id int64) error
+	ReassignUnavailableReviewers(ctx context.Context, now time.Time, pick func([]Candidate) (string, bool)) ([]domain.ReviewerReplacement, error)
+	CreatePullRequest(ctx context.Context, input CreatePullRequestInput, pick func([]Candidate, []string, int) []string) (domain.PullRequest, error)
+	ChangePullRequestStatus(ctx context.Context, repository, prID string, transition Transition, pick func([]Candidate, []string, int) []string) (domain.PullRequest, bool, error)
+	ReassignReviewer(ctx context.Context, repository, prID, oldUserID string, pick func([]Candidate) (string, bool)) (domain.PullRequest, string, error)
+	DeactivateUsers(ctx context.Context, input DeactivateUsersInput, pick func([]Candidate) (string, bool)) (domain.DeactivationResult, error)
+	GetUserReviews(ctx context.Context, userID string, filter UserReviewsFilter) (domain.UserReviews, error)
+	GetPullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error)
+	ListPullRequests(ctx context.Context, filter PullRequestFilter) (domain.PullRequestList, error)
+	GetPullRequestHistory(ctx context.Context, repository, prID string) ([]domain.AuditEvent, error)
+	GetUserHistory(ctx context.Context, userID string) ([]domain.AuditEvent, error)
+	CreateWebhook(ctx context.Context, hook domain.Webhook) (domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]domain.WebhookDelivery, error)
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64, at time.Time) error
+	FailWebhookDelivery(ctx context.Context, id int64, retryAt *time.Time, lastError string) error
+	CreateAPIKey(ctx context.Context, key domain.APIKey, tokenHash string) (domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, tokenHash string) (domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64, at time.Time) (domain.APIKey, error)
+	LinkForgeAccount(ctx context.Context, account domain.ForgeAccount) (domain.ForgeAccount, error)
+	UnlinkForgeAccount(ctx context.Context, forge, login string) error
+	ListForgeAccounts(ctx context.Context, forge string) ([]domain.ForgeAccount, error)
+	ResolveForgeLogin(ctx context.Context, forge, login string) (string, error)
+	CreateRepository(ctx context.Context, repo domain.Repository) (domain.Repository, error)
+	GetRepository(ctx context.Context, name string) (domain.Repository, error)
+	ListRepositories(ctx context.Context, teamName string) ([]domain.Repository, error)
+	SetRepositoryTeams(ctx context.Context, name string, teams []string) (domain.Repository, error)
+	DeleteRepository(ctx context.Context, name string) error
+	CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error)
+	ListOwnershipRules(ctx context.Context, repository string) ([]domain.OwnershipRule, error)
+	DeleteOwnershipRule(ctx context.Context, id int64) error
+	GetReviewerStats(ctx context.Context, teamName string) ([]domain.ReviewerStats, error)
+	GetTeamStats(ctx context.Context, teamName string) ([]domain.TeamStats, error)
+	GetPullRequestStats(ctx context.Context, repository, prID string) (domain.PullRequestStats, error)
 }
 
 // New returns a configured service.
-func New(repo Repository, picker ReviewerPicker) *Service {
+func New(repo Repository, picker ReviewerPicker, opts ...Option) *Service {
 	if picker == nil {
 		picker = NewRandomPicker()
 	}
-	return &Service{repo: repo, picker: picker}
+	s := &Service{
+		repo:    repo,
+		picker:  picker,
+		metrics: noMetrics{},
+		tracer:  otel.GetTracerProvider().Tracer(tracerName),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Service) CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error) {
-	return s.repo.CreateTeam(ctx, team)
+	ctx, span := s.startSpan(ctx, "CreateTeam", AttrTeamName.String(team.TeamName))
+	defer span.End()
+
+	if team.RequiredReviewers == 0 {
+		team.RequiredReviewers = domain.DefaultRequiredReviewers
+	}
+	team.Members = normalizeMemberTags(team.Members)
+	created, err := s.repo.CreateTeam(ctx, team)
+	return created, s.finish(span, err)
 }
 
 func (s *Service) GetTeam(ctx context.Context, teamName string) (domain.Team, error) {
-	return s.repo.GetTeam(ctx, teamName)
+	ctx, span := s.startSpan(ctx, "GetTeam", AttrTeamName.String(teamName))
+	defer span.End()
+
+	team, err := s.repo.GetTeam(ctx, teamName)
+	return team, s.finish(span, err)
+}
+
+// UpdateTeamSettings changes how many reviewers new PRs of the team receive
+// and the default cap on OPEN reviews of its members.
+func (s *Service) UpdateTeamSettings(ctx context.Context, teamName string, update TeamSettingsUpdate) (domain.Team, error) {
+	ctx, span := s.startSpan(ctx, "UpdateTeamSettings", AttrTeamName.String(teamName))
+	defer span.End()
+
+	team, err := s.repo.UpdateTeamSettings(ctx, teamName, update)
+	return team, s.finish(span, err)
+}
+
+// AddTeamMembers adds new users, or users without a team, to an existing team.
+func (s *Service) AddTeamMembers(ctx context.Context, teamName string, members []domain.TeamMember) (domain.Team, error) {
+	ctx, span := s.startSpan(ctx, "AddTeamMembers", AttrTeamName.String(teamName))
+	defer span.End()
+
+	team, err := s.repo.AddTeamMembers(ctx, teamName, normalizeMemberTags(members))
+	return team, s.finish(span, err)
+}
+
+func (s *Service) RemoveTeamMembers(ctx context.Context, input RemoveTeamMembersInput) (domain.MembershipChange, error) {
+	ctx, span := s.startSpan(ctx, "RemoveTeamMembers", AttrTeamName.String(input.TeamName), AttrUserIDs.StringSlice(input.UserIDs))
+	defer span.End()
+
+	change, err := s.repo.RemoveTeamMembers(ctx, input, s.picker.PickOne)
+	if err != nil {
+		return domain.MembershipChange{}, s.finish(span, err)
+	}
+	s.reassigned(ctx, ReassignMembership, len(change.Reassignments))
+	return change, nil
+}
+
+func (s *Service) MoveTeamMember(ctx context.Context, input MoveTeamMemberInput) (domain.MembershipChange, error) {
+	ctx, span := s.startSpan(ctx, "MoveTeamMember", AttrTeamName.String(input.TeamName), AttrUserID.String(input.UserID))
+	defer span.End()
+
+	change, err := s.repo.MoveTeamMember(ctx, input, s.picker.PickOne)
+	if err != nil {
+		return domain.MembershipChange{}, s.finish(span, err)
+	}
+	s.reassigned(ctx, ReassignMembership, len(change.Reassignments))
+	return change, nil
 }
 
 func (s *Service) SetUserActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
-	return s.repo.SetUserActive(ctx, userID, isActive)
+	ctx, span := s.startSpan(ctx, "SetUserActive", AttrUserID.String(userID))
+	defer span.End()
+
+	user, err := s.repo.SetUserActive(ctx, userID, isActive)
+	return user, s.finish(span, err)
+}
+
+// DeactivateUsers switches off users in bulk and moves their OPEN reviews to
+// other active teammates.
+func (s *Service) DeactivateUsers(ctx context.Context, input DeactivateUsersInput) (domain.DeactivationResult, error) {
+	ctx, span := s.startSpan(ctx, "DeactivateUsers", AttrTeamName.String(input.TeamName), AttrUserIDs.StringSlice(input.UserIDs))
+	defer span.End()
+
+	result, err := s.repo.DeactivateUsers(ctx, input, s.picker.PickOne)
+	if err != nil {
+		return domain.DeactivationResult{}, s.finish(span, err)
+	}
+	s.reassigned(ctx, ReassignDeactivation, len(result.Reassignments))
+	return result, nil
 }
 
 func (s *Service) CreatePullRequest(ctx context.Context, input CreatePullRequestInput) (domain.PullRequest, error) {
-	return s.repo.CreatePullRequest(ctx, input, func(ids []string, limit int) []string {
-		return s.picker.Pick(ids, limit)
-	})
+	ctx, span := s.startSpan(ctx, "CreatePullRequest", AttrPullRequestID.String(input.PullRequestID), AttrUserID.String(input.AuthorID), AttrRepository.String(input.Repository))
+	defer span.End()
+
+	input.ChangedFiles = uniqueSorted(input.ChangedFiles)
+	input.RequiredTags = normalizeTags(input.RequiredTags)
+	pr, err := s.repo.CreatePullRequest(ctx, input, s.pick)
+	if err != nil {
+		return domain.PullRequest{}, s.finish(span, err)
+	}
+	s.metrics.PullRequestCreated()
+	logging.FromContext(ctx).Info("pull request created",
+		slog.String("pr_id", pr.PullRequestID), slog.String("repository", pr.Repository), slog.Any("reviewers", pr.Assigned))
+	return pr, nil
+}
+
+// MergePullRequest merges an open PR. Merging a merged PR changes nothing and
+// is not counted as a merge.
+func (s *Service) MergePullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	pr, changed, err := s.changeStatus(ctx, "MergePullRequest", repository, prID, TransitionMerge)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	if changed {
+		s.metrics.PullRequestMerged()
+	}
+	return pr, nil
 }
 
-func (s *Service) MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	return s.repo.MergePullRequest(ctx, prID)
+// ClosePullRequest abandons a draft or open PR without merging it.
+func (s *Service) ClosePullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	pr, _, err := s.changeStatus(ctx, "ClosePullRequest", repository, prID, TransitionClose)
+	return pr, err
 }
 
-func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string) (domain.PullRequest, string, error) {
-	return s.repo.ReassignReviewer(ctx, prID, oldUserID, s.picker.PickOne)
+func (s *Service) ReopenPullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	pr, _, err := s.changeStatus(ctx, "ReopenPullRequest", repository, prID, TransitionReopen)
+	return pr, err
 }
 
-func (s *Service) GetUserReviews(ctx context.Context, userID string) (domain.UserReviews, error) {
-	return s.repo.GetUserReviews(ctx, userID)
+// MarkReadyForReview opens a draft and assigns reviewers to it.
+func (s *Service) MarkReadyForReview(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	pr, _, err := s.changeStatus(ctx, "MarkReadyForReview", repository, prID, TransitionReady)
+	return pr, err
+}
+
+// changeStatus also reports whether the PR status actually changed.
+func (s *Service) changeStatus(ctx context.Context, operation, repository, prID string, transition Transition) (domain.PullRequest, bool, error) {
+	ctx, span := s.startSpan(ctx, operation, AttrPullRequestID.String(prID), AttrRepository.String(repository))
+	defer span.End()
+
+	pr, changed, err := s.repo.ChangePullRequestStatus(ctx, repository, prID, transition, s.pick)
+	return pr, changed, s.finish(span, err)
+}
+
+// reassigned reports n reviewer replacements made for source.
+func (s *Service) reassigned(ctx context.Context, source string, n int) {
+	s.metrics.ReviewersReassigned(source, n)
+	if n > 0 {
+		logging.FromContext(ctx).Info("reviewers reassigned", slog.String("source", source), slog.Int("count", n))
+	}
+}
+
+func (s *Service) pick(candidates []Candidate, tags []string, limit int) []string {
+	if picker, ok := s.picker.(TagPicker); ok {
+		return picker.PickCovering(candidates, tags, limit)
+	}
+	return s.picker.Pick(candidates, limit)
+}
+
+func (s *Service) ReassignReviewer(ctx context.Context, repository, prID, oldUserID string) (domain.PullRequest, string, error) {
+	ctx, span := s.startSpan(ctx, "ReassignReviewer", AttrPullRequestID.String(prID), AttrRepository.String(repository), AttrUserID.String(oldUserID))
+	defer span.End()
+
+	pr, newReviewer, err := s.repo.ReassignReviewer(ctx, repository, prID, oldUserID, s.picker.PickOne)
+	if err != nil {
+		return domain.PullRequest{}, "", s.finish(span, err)
+	}
+	if newReviewer != "" {
+		s.reassigned(ctx, ReassignManual, 1)
+	}
+	return pr, newReviewer, nil
+}
+
+// GetUserReviews returns one page of the user's reviews, newest first.
+func (s *Service) GetUserReviews(ctx context.Context, userID string, filter UserReviewsFilter) (domain.UserReviews, error) {
+	ctx, span := s.startSpan(ctx, "GetUserReviews", AttrUserID.String(userID))
+	defer span.End()
+
+	filter.Limit = pageSize(filter.Limit)
+	reviews, err := s.repo.GetUserReviews(ctx, userID, filter)
+	return reviews, s.finish(span, err)
+}
+
+func (s *Service) GetPullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	ctx, span := s.startSpan(ctx, "GetPullRequest", AttrPullRequestID.String(prID), AttrRepository.String(repository))
+	defer span.End()
+
+	pr, err := s.repo.GetPullRequest(ctx, repository, prID)
+	return pr, s.finish(span, err)
+}
+
+// GetPullRequestHistory returns audit events of the PR, oldest first.
+func (s *Service) GetPullRequestHistory(ctx context.Context, repository, prID string) ([]domain.AuditEvent, error) {
+	ctx, span := s.startSpan(ctx, "GetPullRequestHistory", AttrPullRequestID.String(prID), AttrRepository.String(repository))
+	defer span.End()
+
+	events, err := s.repo.GetPullRequestHistory(ctx, repository, prID)
+	return events, s.finish(span, err)
+}
+
+// GetUserHistory returns audit events where the user is the subject, the old
+// or the new reviewer, oldest first.
+func (s *Service) GetUserHistory(ctx context.Context, userID string) ([]domain.AuditEvent, error) {
+	ctx, span := s.startSpan(ctx, "GetUserHistory", AttrUserID.String(userID))
+	defer span.End()
+
+	events, err := s.repo.GetUserHistory(ctx, userID)
+	return events, s.finish(span, err)
+}
+
+// ListPullRequests returns one page of pull requests matching the filter,
+// newest first by default.
+func (s *Service) ListPullRequests(ctx context.Context, filter PullRequestFilter) (domain.PullRequestList, error) {
+	ctx, span := s.startSpan(ctx, "ListPullRequests", AttrRepository.String(filter.Repository), AttrTeamName.String(filter.TeamName), AttrUserID.String(filter.AuthorID))
+	defer span.End()
+
+	if filter.Order == "" {
+		filter.Order = OrderNewest
+	}
+	filter.Limit = pageSize(filter.Limit)
+	list, err := s.repo.ListPullRequests(ctx, filter)
+	return list, s.finish(span, err)
+}
+
+// CreateWebhook subscribes an endpoint to the given event types.
+func (s *Service) CreateWebhook(ctx context.Context, hook domain.Webhook) (domain.Webhook, error) {
+	ctx, span := s.startSpan(ctx, "CreateWebhook")
+	defer span.End()
+
+	created, err := s.repo.CreateWebhook(ctx, hook)
+	return created, s.finish(span, err)
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	ctx, span := s.startSpan(ctx, "ListWebhooks")
+	defer span.End()
+
+	hooks, err := s.repo.ListWebhooks(ctx)
+	return hooks, s.finish(span, err)
+}
+
+// DeleteWebhook removes the subscription together with its pending deliveries.
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	ctx, span := s.startSpan(ctx, "DeleteWebhook", AttrWebhookID.Int64(id))
+	defer span.End()
+
+	return s.finish(span, s.repo.DeleteWebhook(ctx, id))
+}
+
+// ListWebhookDeliveries returns recent deliveries of a webhook, newest first.
+func (s *Service) ListWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	ctx, span := s.startSpan(ctx, "ListWebhookDeliveries", AttrWebhookID.Int64(filter.WebhookID))
+	defer span.End()
+
+	filter.Limit = pageSize(filter.Limit)
+	deliveries, err := s.repo.ListWebhookDeliveries(ctx, filter)
+	return deliveries, s.finish(span, err)
+}
+
+// GetReviewerStats returns review load per user; empty teamName means all teams.
+func (s *Service) GetReviewerStats(ctx context.Context, teamName string) ([]domain.ReviewerStats, error) {
+	ctx, span := s.startSpan(ctx, "GetReviewerStats", AttrTeamName.String(teamName))
+	defer span.End()
+
+	stats, err := s.repo.GetReviewerStats(ctx, teamName)
+	return stats, s.finish(span, err)
+}
+
+// GetTeamStats returns per-team aggregates; empty teamName means all teams.
+func (s *Service) GetTeamStats(ctx context.Context, teamName string) ([]domain.TeamStats, error) {
+	ctx, span := s.startSpan(ctx, "GetTeamStats", AttrTeamName.String(teamName))
+	defer span.End()
+
+	stats, err := s.repo.GetTeamStats(ctx, teamName)
+	return stats, s.finish(span, err)
+}
+
+// GetPullRequestStats returns the reviewer and reassignment counts of one PR.
+func (s *Service) GetPullRequestStats(ctx context.Context, repository, prID string) (domain.PullRequestStats, error) {
+	ctx, span := s.startSpan(ctx, "GetPullRequestStats", AttrPullRequestID.String(prID), AttrRepository.String(repository))
+	defer span.End()
+
+	stats, err := s.repo.GetPullRequestStats(ctx, repository, prID)
+	return stats, s.finish(span, err)
 }
@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNewUsesDefaultPickerWhenNil(t *testing.T) {
@@ -16,12 +24,38 @@ func TestNewUsesDefaultPickerWhenNil(t *testing.T) {
 		t.Fatalf("expected default picker to be injected when nil is provided")
 	}
 
-	ids := []string{"a", "b"}
-	if picked := svc.picker.Pick(ids, 1); len(ids) > 0 && len(picked) == 0 {
+	candidates := []Candidate{{UserID: "a"}, {UserID: "b"}}
+	if picked := svc.picker.Pick(candidates, 1); len(candidates) > 0 && len(picked) == 0 {
 		t.Fatalf("default picker should pick at least one id when available")
 	}
 }
 
+func TestServiceCreateTeamDefaultsRequiredReviewers(t *testing.T) {
+	ctx := context.Background()
+	var received domain.Team
+	repo := stubRepository{
+		createTeamFn: func(_ context.Context, team domain.Team) (domain.Team, error) {
+			received = team
+			return team, nil
+		},
+	}
+	svc := New(repo, nil)
+
+	if _, err := svc.CreateTeam(ctx, domain.Team{TeamName: "core"}); err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
+	if received.RequiredReviewers != domain.DefaultRequiredReviewers {
+		t.Fatalf("expected default required reviewers, got %d", received.RequiredReviewers)
+	}
+
+	if _, err := svc.CreateTeam(ctx, domain.Team{TeamName: "infra", RequiredReviewers: 3, MinReviewers: 1}); err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
+	if received.RequiredReviewers != 3 || received.MinReviewers != 1 {
+		t.Fatalf("explicit settings overwritten: %+v", received)
+	}
+}
+
 func TestServiceCreatePullRequestDelegatesPicker(t *testing.T) {
 	ctx := context.Background()
 	picker := &stubPicker{pickReturn: []string{"u1", "u2"}}
@@ -29,10 +63,10 @@ func TestServiceCreatePullRequestDelegatesPicker(t *testing.T) {
 	var receivedIDs []string
 	var receivedLimit int
 	repo := stubRepository{
-		createPullRequestFn: func(_ context.Context, _ CreatePullRequestInput, pick func([]string, int) []string) (domain.PullRequest, error) {
-			candidates := []string{"a", "b", "c"}
+		createPullRequestFn: func(_ context.Context, _ CreatePullRequestInput, pick func([]Candidate, []string, int) []string) (domain.PullRequest, error) {
+			candidates := []Candidate{{UserID: "a"}, {UserID: "b"}, {UserID: "c"}}
 			receivedLimit = 2
-			receivedIDs = pick(candidates, receivedLimit)
+			receivedIDs = pick(candidates, nil, receivedLimit)
 			return domain.PullRequest{PullRequestID: "pr-1"}, nil
 		},
 	}
@@ -66,22 +100,22 @@ func TestServiceReassignReviewerUsesPickOne(t *testing.T) {
 
 	var pickInput []string
 	repo := stubRepository{
-		reassignReviewerFn: func(_ context.Context, prID, oldUserID string, pick func([]string) (string, bool)) (domain.PullRequest, string, error) {
-			candidates := []string{"x", "y", "z"}
-			pickInput = append([]string(nil), candidates...)
+		reassignReviewerFn: func(_ context.Context, repository, prID, oldUserID string, pick func([]Candidate) (string, bool)) (domain.PullRequest, string, error) {
+			candidates := []Candidate{{UserID: "x"}, {UserID: "y"}, {UserID: "z"}}
+			pickInput = candidateIDs(candidates)
 			chosen, _ := pick(candidates)
-			return domain.PullRequest{PullRequestID: prID}, chosen, nil
+			return domain.PullRequest{PullRequestID: prID, Repository: repository}, chosen, nil
 		},
 	}
 
 	svc := New(repo, picker)
-	pr, replaced, err := svc.ReassignReviewer(ctx, "pr-42", "old")
+	pr, replaced, err := svc.ReassignReviewer(ctx, "shop", "pr-42", "old")
 	if err != nil {
 		t.Fatalf("ReassignReviewer returned error: %v", err)
 	}
 
-	if pr.PullRequestID != "pr-42" {
-		t.Fatalf("unexpected pull request id: %s", pr.PullRequestID)
+	if pr.PullRequestID != "pr-42" || pr.Repository != "shop" {
+		t.Fatalf("unexpected pull request: %s/%s", pr.Repository, pr.PullRequestID)
 	}
 
 	if replaced != picker.pickOneReturn {
@@ -93,6 +127,455 @@ func TestServiceReassignReviewerUsesPickOne(t *testing.T) {
 	}
 }
 
+func TestServiceDeactivateUsersUsesPickOne(t *testing.T) {
+	ctx := context.Background()
+	picker := &stubPicker{pickOneReturn: "u3", pickOneOK: true}
+
+	var received DeactivateUsersInput
+	repo := stubRepository{
+		deactivateUsersFn: func(_ context.Context, input DeactivateUsersInput, pick func([]Candidate) (string, bool)) (domain.DeactivationResult, error) {
+			received = input
+			chosen, _ := pick([]Candidate{{UserID: "u3"}, {UserID: "u4"}})
+			return domain.DeactivationResult{
+				Deactivated:   input.UserIDs,
+				Reassignments: []domain.ReviewerReplacement{{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: chosen}},
+			}, nil
+		},
+	}
+
+	result, err := New(repo, picker).DeactivateUsers(ctx, DeactivateUsersInput{TeamName: "core", UserIDs: []string{"u1"}})
+	if err != nil {
+		t.Fatalf("DeactivateUsers returned error: %v", err)
+	}
+	if received.TeamName != "core" || !reflect.DeepEqual(received.UserIDs, []string{"u1"}) {
+		t.Fatalf("input not propagated: %+v", received)
+	}
+	if !reflect.DeepEqual(picker.lastIDs, []string{"u3", "u4"}) {
+		t.Fatalf("picker PickOne saw %v", picker.lastIDs)
+	}
+	if len(result.Reassignments) != 1 || result.Reassignments[0].NewReviewerID != "u3" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestServiceMoveTeamMemberUsesPickOne(t *testing.T) {
+	ctx := context.Background()
+	picker := &stubPicker{pickOneReturn: "f2", pickOneOK: true}
+
+	var received MoveTeamMemberInput
+	repo := stubRepository{
+		moveMemberFn: func(_ context.Context, input MoveTeamMemberInput, pick func([]Candidate) (string, bool)) (domain.MembershipChange, error) {
+			received = input
+			chosen, _ := pick([]Candidate{{UserID: "f2"}})
+			return domain.MembershipChange{
+				Team:          domain.Team{TeamName: input.TeamName},
+				Reassignments: []domain.ReviewerReplacement{{PullRequestID: "pr-1", OldReviewerID: input.UserID, NewReviewerID: chosen}},
+			}, nil
+		},
+	}
+
+	input := MoveTeamMemberInput{UserID: "u1", TeamName: "frontend", ReassignReviews: true}
+	result, err := New(repo, picker).MoveTeamMember(ctx, input)
+	if err != nil {
+		t.Fatalf("MoveTeamMember returned error: %v", err)
+	}
+	if received != input {
+		t.Fatalf("input not propagated: %+v", received)
+	}
+	if result.Team.TeamName != "frontend" || len(result.Reassignments) != 1 || result.Reassignments[0].NewReviewerID != "f2" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestTransitionAllows(t *testing.T) {
+	tests := []struct {
+		name       string
+		transition Transition
+		from       string
+		want       bool
+	}{
+		{"ready from draft", TransitionReady, domain.StatusDraft, true},
+		{"ready from closed", TransitionReady, domain.StatusClosed, false},
+		{"merge from open", TransitionMerge, domain.StatusOpen, true},
+		{"merge repeated", TransitionMerge, domain.StatusMerged, true},
+		{"merge from draft", TransitionMerge, domain.StatusDraft, false},
+		{"merge from closed", TransitionMerge, domain.StatusClosed, false},
+		{"close from draft", TransitionClose, domain.StatusDraft, true},
+		{"close from open", TransitionClose, domain.StatusOpen, true},
+		{"close from merged", TransitionClose, domain.StatusMerged, false},
+		{"reopen from closed", TransitionReopen, domain.StatusClosed, true},
+		{"reopen from merged", TransitionReopen, domain.StatusMerged, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transition.Allows(tt.from); got != tt.want {
+				t.Fatalf("Allows(%s) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceLifecycleMethodsUseTransitions(t *testing.T) {
+	ctx := context.Background()
+	var received Transition
+	repo := stubRepository{
+		changeStatusFn: func(_ context.Context, _, prID string, transition Transition, _ func([]Candidate, []string, int) []string) (domain.PullRequest, bool, error) {
+			received = transition
+			return domain.PullRequest{PullRequestID: prID, Status: transition.To}, true, nil
+		},
+	}
+	svc := New(repo, nil)
+
+	calls := []struct {
+		name string
+		call func(context.Context, string, string) (domain.PullRequest, error)
+		want Transition
+	}{
+		{"merge", svc.MergePullRequest, TransitionMerge},
+		{"close", svc.ClosePullRequest, TransitionClose},
+		{"reopen", svc.ReopenPullRequest, TransitionReopen},
+		{"ready", svc.MarkReadyForReview, TransitionReady},
+	}
+	for _, c := range calls {
+		pr, err := c.call(ctx, "", "pr-1")
+		if err != nil {
+			t.Fatalf("%s returned error: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(received, c.want) || pr.Status != c.want.To {
+			t.Fatalf("%s used transition %+v, want %+v", c.name, received, c.want)
+		}
+	}
+}
+
+func TestServiceGetReviewerStatsPassesTeamFilter(t *testing.T) {
+	ctx := context.Background()
+	var receivedTeam string
+	repo := stubRepository{
+		getReviewerStatsFn: func(_ context.Context, teamName string) ([]domain.ReviewerStats, error) {
+			receivedTeam = teamName
+			return []domain.ReviewerStats{{UserID: "u1", OpenReviews: 2}}, nil
+		},
+	}
+
+	stats, err := New(repo, nil).GetReviewerStats(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetReviewerStats returned error: %v", err)
+	}
+	if receivedTeam != "backend" {
+		t.Fatalf("team filter not propagated, got %q", receivedTeam)
+	}
+	if len(stats) != 1 || stats[0].OpenReviews != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestServiceRepositoryTeamsAreDeduplicated(t *testing.T) {
+	ctx := context.Background()
+	var received [][]string
+	repo := stubRepository{
+		createRepoFn: func(_ context.Context, repo domain.Repository) (domain.Repository, error) {
+			received = append(received, repo.Teams)
+			return repo, nil
+		},
+		setRepoTeamsFn: func(_ context.Context, name string, teams []string) (domain.Repository, error) {
+			received = append(received, teams)
+			return domain.Repository{Name: name, Teams: teams}, nil
+		},
+	}
+	svc := New(repo, nil)
+
+	if _, err := svc.CreateRepository(ctx, domain.Repository{Name: "shop", Teams: []string{"web", "backend", "web"}}); err != nil {
+		t.Fatalf("CreateRepository returned error: %v", err)
+	}
+	if _, err := svc.SetRepositoryTeams(ctx, "shop", []string{"backend", "backend"}); err != nil {
+		t.Fatalf("SetRepositoryTeams returned error: %v", err)
+	}
+	want := [][]string{{"backend", "web"}, {"backend"}}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("teams passed to the repository: got %v, want %v", received, want)
+	}
+}
+
+func TestServiceTagsAreNormalized(t *testing.T) {
+	ctx := context.Background()
+	var received [][]string
+	repo := stubRepository{
+		setUserTagsFn: func(_ context.Context, userID string, tags []string) (domain.User, error) {
+			received = append(received, tags)
+			return domain.User{UserID: userID, Tags: tags}, nil
+		},
+		createPullRequestFn: func(_ context.Context, input CreatePullRequestInput, _ func([]Candidate, []string, int) []string) (domain.PullRequest, error) {
+			received = append(received, input.RequiredTags)
+			return domain.PullRequest{PullRequestID: input.PullRequestID}, nil
+		},
+		addMembersFn: func(_ context.Context, teamName string, members []domain.TeamMember) (domain.Team, error) {
+			received = append(received, members[0].Tags)
+			return domain.Team{TeamName: teamName, Members: members}, nil
+		},
+	}
+	svc := New(repo, nil)
+
+	if _, err := svc.SetUserTags(ctx, "u1", []string{"SQL", "go", "sql"}); err != nil {
+		t.Fatalf("SetUserTags returned error: %v", err)
+	}
+	if _, err := svc.CreatePullRequest(ctx, CreatePullRequestInput{PullRequestID: "pr-1", RequiredTags: []string{"Frontend"}}); err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if _, err := svc.AddTeamMembers(ctx, "web", []domain.TeamMember{{UserID: "u2", Tags: []string{"Go", "GO"}}}); err != nil {
+		t.Fatalf("AddTeamMembers returned error: %v", err)
+	}
+	want := [][]string{{"go", "sql"}, {"frontend"}, {"go"}}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("tags passed to the repository: got %v, want %v", received, want)
+	}
+}
+
+func TestServiceGetUserReviewsCapsPageSize(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: DefaultPageSize},
+		{limit: 10, want: 10},
+		{limit: MaxPageSize + 1, want: MaxPageSize},
+	}
+	for _, tt := range tests {
+		var received UserReviewsFilter
+		repo := stubRepository{
+			getUserReviewsFn: func(_ context.Context, _ string, filter UserReviewsFilter) (domain.UserReviews, error) {
+				received = filter
+				return domain.UserReviews{}, nil
+			},
+		}
+
+		if _, err := New(repo, nil).GetUserReviews(context.Background(), "u1", UserReviewsFilter{Limit: tt.limit}); err != nil {
+			t.Fatalf("GetUserReviews returned error: %v", err)
+		}
+		if received.Limit != tt.want {
+			t.Fatalf("limit %d: repository got %d, want %d", tt.limit, received.Limit, tt.want)
+		}
+	}
+}
+
+func TestServiceListPullRequestsAppliesDefaults(t *testing.T) {
+	var received PullRequestFilter
+	repo := stubRepository{
+		listPullRequestsFn: func(_ context.Context, filter PullRequestFilter) (domain.PullRequestList, error) {
+			received = filter
+			return domain.PullRequestList{}, nil
+		},
+	}
+	svc := New(repo, nil)
+
+	if _, err := svc.ListPullRequests(context.Background(), PullRequestFilter{TeamName: "backend"}); err != nil {
+		t.Fatalf("ListPullRequests returned error: %v", err)
+	}
+	if received.Order != OrderNewest || received.Limit != DefaultPageSize || received.TeamName != "backend" {
+		t.Fatalf("unexpected filter: %+v", received)
+	}
+
+	if _, err := svc.ListPullRequests(context.Background(), PullRequestFilter{Order: OrderOldest, Limit: 500}); err != nil {
+		t.Fatalf("ListPullRequests returned error: %v", err)
+	}
+	if received.Order != OrderOldest || received.Limit != MaxPageSize {
+		t.Fatalf("unexpected filter: %+v", received)
+	}
+}
+
+func TestServiceCreateAPIKeyStoresOnlyHash(t *testing.T) {
+	var stored domain.APIKey
+	var storedHash string
+	repo := stubRepository{
+		createAPIKeyFn: func(_ context.Context, key domain.APIKey, tokenHash string) (domain.APIKey, error) {
+			stored, storedHash = key, tokenHash
+			key.ID = 1
+			return key, nil
+		},
+	}
+	svc := New(repo, nil)
+
+	key, token, err := svc.CreateAPIKey(context.Background(), "ci", domain.RoleUser)
+	if err != nil {
+		t.Fatalf("CreateAPIKey returned error: %v", err)
+	}
+	if key.ID != 1 || stored.Name != "ci" || stored.Role != domain.RoleUser {
+		t.Fatalf("unexpected key: %+v, stored %+v", key, stored)
+	}
+	if !strings.HasPrefix(token, tokenPrefix) || storedHash != HashToken(token) || storedHash == token {
+		t.Fatalf("token %q stored as %q", token, storedHash)
+	}
+
+	_, other, _ := svc.CreateAPIKey(context.Background(), "ci", domain.RoleUser)
+	if other == token {
+		t.Fatal("tokens must be unique")
+	}
+}
+
+func TestServiceAuthenticate(t *testing.T) {
+	revokedAt := time.Now()
+	keys := map[string]domain.APIKey{
+		HashToken("active"):  {ID: 1, Name: "ci", Role: domain.RoleUser},
+		HashToken("revoked"): {ID: 2, Name: "old", Role: domain.RoleAdmin, RevokedAt: &revokedAt},
+	}
+	repo := stubRepository{
+		getAPIKeyFn: func(_ context.Context, tokenHash string) (domain.APIKey, error) {
+			if key, ok := keys[tokenHash]; ok {
+				return key, nil
+			}
+			return domain.APIKey{}, domain.NewNotFoundError("api key not found", nil)
+		},
+	}
+	svc := New(repo, nil)
+
+	key, err := svc.Authenticate(context.Background(), "active")
+	if err != nil || key.ID != 1 {
+		t.Fatalf("Authenticate(active) = %+v, %v", key, err)
+	}
+	for _, token := range []string{"", "unknown", "revoked"} {
+		_, err := svc.Authenticate(context.Background(), token)
+		var appErr *domain.AppError
+		if !errors.As(err, &appErr) || appErr.Code != domain.ErrCodeUnauthorized {
+			t.Fatalf("Authenticate(%q) error = %v, want UNAUTHORIZED", token, err)
+		}
+	}
+}
+
+func TestServiceForgeLoginsAreCaseInsensitive(t *testing.T) {
+	var linked domain.ForgeAccount
+	var resolved string
+	repo := stubRepository{
+		linkAccountFn: func(_ context.Context, account domain.ForgeAccount) (domain.ForgeAccount, error) {
+			linked = account
+			return account, nil
+		},
+		resolveLoginFn: func(_ context.Context, _, login string) (string, error) {
+			resolved = login
+			return "u1", nil
+		},
+	}
+	svc := New(repo, nil)
+
+	if _, err := svc.LinkForgeAccount(context.Background(), domain.ForgeAccount{Forge: domain.ForgeGitHub, Login: "Octo-Cat", UserID: "u1"}); err != nil {
+		t.Fatalf("LinkForgeAccount returned error: %v", err)
+	}
+	if _, err := svc.ResolveForgeLogin(context.Background(), domain.ForgeGitHub, "OCTO-cat"); err != nil {
+		t.Fatalf("ResolveForgeLogin returned error: %v", err)
+	}
+	if linked.Login != "octo-cat" || resolved != "octo-cat" {
+		t.Fatalf("logins not normalised: linked %q, resolved %q", linked.Login, resolved)
+	}
+}
+
+type countingMetrics struct {
+	created, merged, noCandidate int
+	reassigned                   map[string]int
+}
+
+func (m *countingMetrics) PullRequestCreated() { m.created++ }
+func (m *countingMetrics) PullRequestMerged()  { m.merged++ }
+func (m *countingMetrics) NoCandidate()        { m.noCandidate++ }
+func (m *countingMetrics) ReviewersReassigned(source string, n int) {
+	m.reassigned[source] += n
+}
+
+func TestServiceReportsMetrics(t *testing.T) {
+	ctx := context.Background()
+	statuses := map[string]string{}
+	repo := stubRepository{
+		createPullRequestFn: func(_ context.Context, input CreatePullRequestInput, _ func([]Candidate, []string, int) []string) (domain.PullRequest, error) {
+			switch input.PullRequestID {
+			case "dup":
+				return domain.PullRequest{}, domain.NewPRExistsError(nil)
+			case "capped":
+				return domain.PullRequest{}, domain.NewCapReachedError()
+			}
+			return domain.PullRequest{PullRequestID: input.PullRequestID}, nil
+		},
+		changeStatusFn: func(_ context.Context, _, prID string, transition Transition, _ func([]Candidate, []string, int) []string) (domain.PullRequest, bool, error) {
+			changed := statuses[prID] != transition.To
+			statuses[prID] = transition.To
+			return domain.PullRequest{PullRequestID: prID, Status: transition.To}, changed, nil
+		},
+		reassignReviewerFn: func(_ context.Context, _, prID, _ string, _ func([]Candidate) (string, bool)) (domain.PullRequest, string, error) {
+			switch prID {
+			case "pr-1":
+				return domain.PullRequest{PullRequestID: prID}, "u3", nil
+			case "pr-2":
+				return domain.PullRequest{PullRequestID: prID}, "", nil
+			}
+			return domain.PullRequest{}, "", domain.NewNoCandidateError()
+		},
+		deactivateUsersFn: func(context.Context, DeactivateUsersInput, func([]Candidate) (string, bool)) (domain.DeactivationResult, error) {
+			return domain.DeactivationResult{Reassignments: make([]domain.ReviewerReplacement, 2)}, nil
+		},
+		reassignAwayFn: func(context.Context, time.Time, func([]Candidate) (string, bool)) ([]domain.ReviewerReplacement, error) {
+			return make([]domain.ReviewerReplacement, 3), nil
+		},
+	}
+	m := &countingMetrics{reassigned: map[string]int{}}
+	svc := New(repo, nil, WithMetrics(m))
+
+	_, _ = svc.CreatePullRequest(ctx, CreatePullRequestInput{PullRequestID: "pr-1"})
+	_, _ = svc.CreatePullRequest(ctx, CreatePullRequestInput{PullRequestID: "dup"})
+	_, _ = svc.CreatePullRequest(ctx, CreatePullRequestInput{PullRequestID: "capped"})
+	_, _, _ = svc.ReassignReviewer(ctx, "", "pr-1", "u2")
+	_, _, _ = svc.ReassignReviewer(ctx, "", "pr-2", "u2")
+	_, _, _ = svc.ReassignReviewer(ctx, "", "missing", "u2")
+	_, _ = svc.MergePullRequest(ctx, "", "pr-1")
+	_, _ = svc.MergePullRequest(ctx, "", "pr-1")
+	_, _ = svc.ClosePullRequest(ctx, "", "pr-2")
+	_, _ = svc.DeactivateUsers(ctx, DeactivateUsersInput{TeamName: "backend"})
+	_, _ = svc.ReassignUnavailableReviewers(ctx, time.Now())
+
+	if m.created != 1 || m.merged != 1 || m.noCandidate != 2 {
+		t.Fatalf("unexpected counts: %+v", m)
+	}
+	if m.reassigned[ReassignDeactivation] != 2 || m.reassigned[ReassignAvailability] != 3 || m.reassigned[ReassignManual] != 1 {
+		t.Fatalf("unexpected reassignments: %v", m.reassigned)
+	}
+}
+
+func TestServiceRecordsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := stubRepository{
+		reassignReviewerFn: func(ctx context.Context, _, _, _ string, _ func([]Candidate) (string, bool)) (domain.PullRequest, string, error) {
+			if !trace.SpanContextFromContext(ctx).IsValid() {
+				t.Fatalf("repository called without the service span in context")
+			}
+			return domain.PullRequest{}, "", domain.NewNoCandidateError()
+		},
+	}
+
+	_, _, _ = New(repo, nil, WithTracerProvider(tp)).ReassignReviewer(context.Background(), "", "pr-1", "u2")
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "service.ReassignReviewer" {
+		t.Fatalf("unexpected spans: %v", spans)
+	}
+	span := spans[0]
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	if attrs[AttrPullRequestID] != "pr-1" || attrs[AttrUserID] != "u2" {
+		t.Fatalf("unexpected attributes: %v", attrs)
+	}
+	if span.Status().Code != codes.Error || len(span.Events()) == 0 {
+		t.Fatalf("error not recorded: %+v", span.Status())
+	}
+}
+
+func TestActorFromContext(t *testing.T) {
+	if actor := ActorFromContext(context.Background()); actor != "" {
+		t.Fatalf("unexpected actor without WithActor: %q", actor)
+	}
+	if actor := ActorFromContext(WithActor(context.Background(), "alice")); actor != "alice" {
+		t.Fatalf("got actor %q, want alice", actor)
+	}
+}
+
 type stubPicker struct {
 	pickReturn    []string
 	lastIDs       []string
@@ -101,25 +584,66 @@ type stubPicker struct {
 	pickOneOK     bool
 }
 
-func (s *stubPicker) Pick(ids []string, limit int) []string {
-	s.lastIDs = append([]string(nil), ids...)
+func (s *stubPicker) Pick(candidates []Candidate, limit int) []string {
+	s.lastIDs = candidateIDs(candidates)
 	s.lastLimit = limit
 	return append([]string(nil), s.pickReturn...)
 }
 
-func (s *stubPicker) PickOne(ids []string) (string, bool) {
-	s.lastIDs = append([]string(nil), ids...)
+func (s *stubPicker) PickOne(candidates []Candidate) (string, bool) {
+	s.lastIDs = candidateIDs(candidates)
 	return s.pickOneReturn, s.pickOneOK
 }
 
 type stubRepository struct {
 	createTeamFn        func(context.Context, domain.Team) (domain.Team, error)
 	getTeamFn           func(context.Context, string) (domain.Team, error)
+	updateTeamFn        func(context.Context, string, TeamSettingsUpdate) (domain.Team, error)
+	addMembersFn        func(context.Context, string, []domain.TeamMember) (domain.Team, error)
+	removeMembersFn     func(context.Context, RemoveTeamMembersInput, func([]Candidate) (string, bool)) (domain.MembershipChange, error)
+	moveMemberFn        func(context.Context, MoveTeamMemberInput, func([]Candidate) (string, bool)) (domain.MembershipChange, error)
 	setUserActiveFn     func(context.Context, string, bool) (domain.User, error)
-	createPullRequestFn func(context.Context, CreatePullRequestInput, func([]string, int) []string) (domain.PullRequest, error)
-	mergePullRequestFn  func(context.Context, string) (domain.PullRequest, error)
-	reassignReviewerFn  func(context.Context, string, string, func([]string) (string, bool)) (domain.PullRequest, string, error)
-	getUserReviewsFn    func(context.Context, string) (domain.UserReviews, error)
+	setUserTagsFn       func(context.Context, string, []string) (domain.User, error)
+	setUserCapFn        func(context.Context, string, *int) (domain.User, error)
+	createPeriodFn      func(context.Context, domain.Unavailability) (domain.Unavailability, error)
+	listPeriodsFn       func(context.Context, string) ([]domain.Unavailability, error)
+	deletePeriodFn      func(context.Context, int64) error
+	reassignAwayFn      func(context.Context, time.Time, func([]Candidate) (string, bool)) ([]domain.ReviewerReplacement, error)
+	createPullRequestFn func(context.Context, CreatePullRequestInput, func([]Candidate, []string, int) []string) (domain.PullRequest, error)
+	changeStatusFn      func(context.Context, string, string, Transition, func([]Candidate, []string, int) []string) (domain.PullRequest, bool, error)
+	reassignReviewerFn  func(context.Context, string, string, string, func([]Candidate) (string, bool)) (domain.PullRequest, string, error)
+	deactivateUsersFn   func(context.Context, DeactivateUsersInput, func([]Candidate) (string, bool)) (domain.DeactivationResult, error)
+	getUserReviewsFn    func(context.Context, string, UserReviewsFilter) (domain.UserReviews, error)
+	getPullRequestFn    func(context.Context, string, string) (domain.PullRequest, error)
+	listPullRequestsFn  func(context.Context, PullRequestFilter) (domain.PullRequestList, error)
+	prHistoryFn         func(context.Context, string, string) ([]domain.AuditEvent, error)
+	userHistoryFn       func(context.Context, string) ([]domain.AuditEvent, error)
+	createWebhookFn     func(context.Context, domain.Webhook) (domain.Webhook, error)
+	listWebhooksFn      func(context.Context) ([]domain.Webhook, error)
+	deleteWebhookFn     func(context.Context, int64) error
+	listDeliveriesFn    func(context.Context, WebhookDeliveryFilter) ([]domain.WebhookDelivery, error)
+	claimDeliveriesFn   func(context.Context, time.Time, time.Time, int) ([]domain.WebhookDelivery, error)
+	completeDeliveryFn  func(context.Context, int64, time.Time) error
+	failDeliveryFn      func(context.Context, int64, *time.Time, string) error
+	createAPIKeyFn      func(context.Context, domain.APIKey, string) (domain.APIKey, error)
+	getAPIKeyFn         func(context.Context, string) (domain.APIKey, error)
+	listAPIKeysFn       func(context.Context) ([]domain.APIKey, error)
+	revokeAPIKeyFn      func(context.Context, int64, time.Time) (domain.APIKey, error)
+	linkAccountFn       func(context.Context, domain.ForgeAccount) (domain.ForgeAccount, error)
+	unlinkAccountFn     func(context.Context, string, string) error
+	listAccountsFn      func(context.Context, string) ([]domain.ForgeAccount, error)
+	resolveLoginFn      func(context.Context, string, string) (string, error)
+	createRepoFn        func(context.Context, domain.Repository) (domain.Repository, error)
+	getRepoFn           func(context.Context, string) (domain.Repository, error)
+	listReposFn         func(context.Context, string) ([]domain.Repository, error)
+	setRepoTeamsFn      func(context.Context, string, []string) (domain.Repository, error)
+	deleteRepoFn        func(context.Context, string) error
+	createRuleFn        func(context.Context, domain.OwnershipRule) (domain.OwnershipRule, error)
+	listRulesFn         func(context.Context, string) ([]domain.OwnershipRule, error)
+	deleteRuleFn        func(context.Context, int64) error
+	getReviewerStatsFn  func(context.Context, string) ([]domain.ReviewerStats, error)
+	getTeamStatsFn      func(context.Context, string) ([]domain.TeamStats, error)
+	getPRStatsFn        func(context.Context, string, string) (domain.PullRequestStats, error)
 }
 
 func (s stubRepository) CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error) {
@@ -136,6 +660,34 @@ func (s stubRepository) GetTeam(ctx context.Context, teamName string) (domain.Te
 	return domain.Team{}, nil
 }
 
+func (s stubRepository) UpdateTeamSettings(ctx context.Context, teamName string, update TeamSettingsUpdate) (domain.Team, error) {
+	if s.updateTeamFn != nil {
+		return s.updateTeamFn(ctx, teamName, update)
+	}
+	return domain.Team{}, nil
+}
+
+func (s stubRepository) AddTeamMembers(ctx context.Context, teamName string, members []domain.TeamMember) (domain.Team, error) {
+	if s.addMembersFn != nil {
+		return s.addMembersFn(ctx, teamName, members)
+	}
+	return domain.Team{}, nil
+}
+
+func (s stubRepository) RemoveTeamMembers(ctx context.Context, input RemoveTeamMembersInput, pick func([]Candidate) (string, bool)) (domain.MembershipChange, error) {
+	if s.removeMembersFn != nil {
+		return s.removeMembersFn(ctx, input, pick)
+	}
+	return domain.MembershipChange{}, nil
+}
+
+func (s stubRepository) MoveTeamMember(ctx context.Context, input MoveTeamMemberInput, pick func([]Candidate) (string, bool)) (domain.MembershipChange, error) {
+	if s.moveMemberFn != nil {
+		return s.moveMemberFn(ctx, input, pick)
+	}
+	return domain.MembershipChange{}, nil
+}
+
 func (s stubRepository) SetUserActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
 	if s.setUserActiveFn != nil {
 		return s.setUserActiveFn(ctx, userID, isActive)
@@ -143,30 +695,289 @@ func (s stubRepository) SetUserActive(ctx context.Context, userID string, isActi
 	return domain.User{}, nil
 }
 
-func (s stubRepository) CreatePullRequest(ctx context.Context, input CreatePullRequestInput, pick func([]string, int) []string) (domain.PullRequest, error) {
+func (s stubRepository) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	if s.setUserTagsFn != nil {
+		return s.setUserTagsFn(ctx, userID, tags)
+	}
+	return domain.User{}, nil
+}
+
+func (s stubRepository) SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error) {
+	if s.setUserCapFn != nil {
+		return s.setUserCapFn(ctx, userID, maxOpenReviews)
+	}
+	return domain.User{}, nil
+}
+
+func (s stubRepository) CreateUnavailability(ctx context.Context, period domain.Unavailability) (domain.Unavailability, error) {
+	if s.createPeriodFn != nil {
+		return s.createPeriodFn(ctx, period)
+	}
+	return period, nil
+}
+
+func (s stubRepository) ListUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	if s.listPeriodsFn != nil {
+		return s.listPeriodsFn(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) DeleteUnavailability(ctx context.Context, id int64) error {
+	if s.deletePeriodFn != nil {
+		return s.deletePeriodFn(ctx, id)
+	}
+	return nil
+}
+
+func (s stubRepository) ReassignUnavailableReviewers(ctx context.Context, now time.Time, pick func([]Candidate) (string, bool)) ([]domain.ReviewerReplacement, error) {
+	if s.reassignAwayFn != nil {
+		return s.reassignAwayFn(ctx, now, pick)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) CreatePullRequest(ctx context.Context, input CreatePullRequestInput, pick func([]Candidate, []string, int) []string) (domain.PullRequest, error) {
 	if s.createPullRequestFn != nil {
 		return s.createPullRequestFn(ctx, input, pick)
 	}
 	return domain.PullRequest{}, nil
 }
 
-func (s stubRepository) MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	if s.mergePullRequestFn != nil {
-		return s.mergePullRequestFn(ctx, prID)
+func (s stubRepository) ChangePullRequestStatus(ctx context.Context, repository, prID string, transition Transition, pick func([]Candidate, []string, int) []string) (domain.PullRequest, bool, error) {
+	if s.changeStatusFn != nil {
+		return s.changeStatusFn(ctx, repository, prID, transition, pick)
 	}
-	return domain.PullRequest{}, nil
+	return domain.PullRequest{}, false, nil
 }
 
-func (s stubRepository) ReassignReviewer(ctx context.Context, prID, oldUserID string, pick func([]string) (string, bool)) (domain.PullRequest, string, error) {
+func (s stubRepository) ReassignReviewer(ctx context.Context, repository, prID, oldUserID string, pick func([]Candidate) (string, bool)) (domain.PullRequest, string, error) {
 	if s.reassignReviewerFn != nil {
-		return s.reassignReviewerFn(ctx, prID, oldUserID, pick)
+		return s.reassignReviewerFn(ctx, repository, prID, oldUserID, pick)
 	}
 	return domain.PullRequest{}, "", nil
 }
 
-func (s stubRepository) GetUserReviews(ctx context.Context, userID string) (domain.UserReviews, error) {
+func (s stubRepository) DeactivateUsers(ctx context.Context, input DeactivateUsersInput, pick func([]Candidate) (string, bool)) (domain.DeactivationResult, error) {
+	if s.deactivateUsersFn != nil {
+		return s.deactivateUsersFn(ctx, input, pick)
+	}
+	return domain.DeactivationResult{}, nil
+}
+
+func (s stubRepository) GetUserReviews(ctx context.Context, userID string, filter UserReviewsFilter) (domain.UserReviews, error) {
 	if s.getUserReviewsFn != nil {
-		return s.getUserReviewsFn(ctx, userID)
+		return s.getUserReviewsFn(ctx, userID, filter)
 	}
 	return domain.UserReviews{}, nil
 }
+
+func (s stubRepository) GetPullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	if s.getPullRequestFn != nil {
+		return s.getPullRequestFn(ctx, repository, prID)
+	}
+	return domain.PullRequest{}, nil
+}
+
+func (s stubRepository) ListPullRequests(ctx context.Context, filter PullRequestFilter) (domain.PullRequestList, error) {
+	if s.listPullRequestsFn != nil {
+		return s.listPullRequestsFn(ctx, filter)
+	}
+	return domain.PullRequestList{}, nil
+}
+
+func (s stubRepository) GetPullRequestHistory(ctx context.Context, repository, prID string) ([]domain.AuditEvent, error) {
+	if s.prHistoryFn != nil {
+		return s.prHistoryFn(ctx, repository, prID)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) GetUserHistory(ctx context.Context, userID string) ([]domain.AuditEvent, error) {
+	if s.userHistoryFn != nil {
+		return s.userHistoryFn(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) CreateWebhook(ctx context.Context, hook domain.Webhook) (domain.Webhook, error) {
+	if s.createWebhookFn != nil {
+		return s.createWebhookFn(ctx, hook)
+	}
+	return domain.Webhook{}, nil
+}
+
+func (s stubRepository) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if s.listWebhooksFn != nil {
+		return s.listWebhooksFn(ctx)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	if s.deleteWebhookFn != nil {
+		return s.deleteWebhookFn(ctx, id)
+	}
+	return nil
+}
+
+func (s stubRepository) ListWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	if s.listDeliveriesFn != nil {
+		return s.listDeliveriesFn(ctx, filter)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	if s.claimDeliveriesFn != nil {
+		return s.claimDeliveriesFn(ctx, now, leaseUntil, limit)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) CompleteWebhookDelivery(ctx context.Context, id int64, at time.Time) error {
+	if s.completeDeliveryFn != nil {
+		return s.completeDeliveryFn(ctx, id, at)
+	}
+	return nil
+}
+
+func (s stubRepository) FailWebhookDelivery(ctx context.Context, id int64, retryAt *time.Time, lastError string) error {
+	if s.failDeliveryFn != nil {
+		return s.failDeliveryFn(ctx, id, retryAt, lastError)
+	}
+	return nil
+}
+
+func (s stubRepository) CreateAPIKey(ctx context.Context, key domain.APIKey, tokenHash string) (domain.APIKey, error) {
+	if s.createAPIKeyFn != nil {
+		return s.createAPIKeyFn(ctx, key, tokenHash)
+	}
+	return domain.APIKey{}, nil
+}
+
+func (s stubRepository) GetAPIKeyByHash(ctx context.Context, tokenHash string) (domain.APIKey, error) {
+	if s.getAPIKeyFn != nil {
+		return s.getAPIKeyFn(ctx, tokenHash)
+	}
+	return domain.APIKey{}, nil
+}
+
+func (s stubRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	if s.listAPIKeysFn != nil {
+		return s.listAPIKeysFn(ctx)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) RevokeAPIKey(ctx context.Context, id int64, at time.Time) (domain.APIKey, error) {
+	if s.revokeAPIKeyFn != nil {
+		return s.revokeAPIKeyFn(ctx, id, at)
+	}
+	return domain.APIKey{}, nil
+}
+
+func (s stubRepository) LinkForgeAccount(ctx context.Context, account domain.ForgeAccount) (domain.ForgeAccount, error) {
+	if s.linkAccountFn != nil {
+		return s.linkAccountFn(ctx, account)
+	}
+	return domain.ForgeAccount{}, nil
+}
+
+func (s stubRepository) UnlinkForgeAccount(ctx context.Context, forge, login string) error {
+	if s.unlinkAccountFn != nil {
+		return s.unlinkAccountFn(ctx, forge, login)
+	}
+	return nil
+}
+
+func (s stubRepository) ListForgeAccounts(ctx context.Context, forge string) ([]domain.ForgeAccount, error) {
+	if s.listAccountsFn != nil {
+		return s.listAccountsFn(ctx, forge)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) ResolveForgeLogin(ctx context.Context, forge, login string) (string, error) {
+	if s.resolveLoginFn != nil {
+		return s.resolveLoginFn(ctx, forge, login)
+	}
+	return "", nil
+}
+
+func (s stubRepository) CreateRepository(ctx context.Context, repo domain.Repository) (domain.Repository, error) {
+	if s.createRepoFn != nil {
+		return s.createRepoFn(ctx, repo)
+	}
+	return domain.Repository{}, nil
+}
+
+func (s stubRepository) GetRepository(ctx context.Context, name string) (domain.Repository, error) {
+	if s.getRepoFn != nil {
+		return s.getRepoFn(ctx, name)
+	}
+	return domain.Repository{}, nil
+}
+
+func (s stubRepository) ListRepositories(ctx context.Context, teamName string) ([]domain.Repository, error) {
+	if s.listReposFn != nil {
+		return s.listReposFn(ctx, teamName)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) SetRepositoryTeams(ctx context.Context, name string, teams []string) (domain.Repository, error) {
+	if s.setRepoTeamsFn != nil {
+		return s.setRepoTeamsFn(ctx, name, teams)
+	}
+	return domain.Repository{}, nil
+}
+
+func (s stubRepository) DeleteRepository(ctx context.Context, name string) error {
+	if s.deleteRepoFn != nil {
+		return s.deleteRepoFn(ctx, name)
+	}
+	return nil
+}
+
+func (s stubRepository) CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error) {
+	if s.createRuleFn != nil {
+		return s.createRuleFn(ctx, rule)
+	}
+	return domain.OwnershipRule{}, nil
+}
+
+func (s stubRepository) ListOwnershipRules(ctx context.Context, repository string) ([]domain.OwnershipRule, error) {
+	if s.listRulesFn != nil {
+		return s.listRulesFn(ctx, repository)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) DeleteOwnershipRule(ctx context.Context, id int64) error {
+	if s.deleteRuleFn != nil {
+		return s.deleteRuleFn(ctx, id)
+	}
+	return nil
+}
+
+func (s stubRepository) GetReviewerStats(ctx context.Context, teamName string) ([]domain.ReviewerStats, error) {
+	if s.getReviewerStatsFn != nil {
+		return s.getReviewerStatsFn(ctx, teamName)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) GetTeamStats(ctx context.Context, teamName string) ([]domain.TeamStats, error) {
+	if s.getTeamStatsFn != nil {
+		return s.getTeamStatsFn(ctx, teamName)
+	}
+	return nil, nil
+}
+
+func (s stubRepository) GetPullRequestStats(ctx context.Context, repository, prID string) (domain.PullRequestStats, error) {
+	if s.getPRStatsFn != nil {
+		return s.getPRStatsFn(ctx, repository, prID)
+	}
+	return domain.PullRequestStats{}, nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// SetUserTags replaces the skill tags of the user.
+func (s *Service) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	ctx, span := s.startSpan(ctx, "SetUserTags", AttrUserID.String(userID))
+	defer span.End()
+
+	user, err := s.repo.SetUserTags(ctx, userID, normalizeTags(tags))
+	return user, s.finish(span, err)
+}
+
+// normalizeTags lower-cases tags, so "Go" and "go" are the same skill, and
+// drops duplicates.
+func normalizeTags(tags []string) []string {
+	lowered := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		lowered = append(lowered, strings.ToLower(tag))
+	}
+	return uniqueSorted(lowered)
+}
+
+func normalizeMemberTags(members []domain.TeamMember) []domain.TeamMember {
+	normalized := make([]domain.TeamMember, 0, len(members))
+	for _, member := range members {
+		member.Tags = normalizeTags(member.Tags)
+		normalized = append(normalized, member)
+	}
+	return normalized
+}
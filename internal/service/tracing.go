@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/GolovachevS/pr-reviewer-service/internal/service"
+
+// Span attributes naming the objects an operation works on.
+const (
+	AttrPullRequestID = attribute.Key("pr.id")
+	AttrTeamName      = attribute.Key("team.name")
+	AttrUserID        = attribute.Key("user.id")
+	AttrUserIDs       = attribute.Key("user.ids")
+	AttrWebhookID     = attribute.Key("webhook.id")
+	AttrForge         = attribute.Key("forge")
+	AttrRepository    = attribute.Key("repository")
+	AttrRuleID        = attribute.Key("ownership_rule.id")
+)
+
+// WithTracerProvider records spans with tp instead of the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Service) {
+		s.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts the span of a Service method; repository statements become
+// its children.
+func (s *Service) startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "service."+operation, trace.WithAttributes(attrs...))
+}
+
+// finish records err on the span and in the metrics and returns it unchanged.
+func (s *Service) finish(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return s.observe(err)
+}
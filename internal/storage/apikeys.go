@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, key domain.APIKey, tokenHash string) (domain.APIKey, error) {
+	row := s.pool.QueryRow(ctx, `INSERT INTO api_keys(name, role, token_hash) VALUES($1, $2, $3)
+		RETURNING `+apiKeyColumns, key.Name, key.Role, tokenHash)
+	return scanAPIKey(row)
+}
+
+// GetAPIKeyByHash returns the key, revoked or not, whose token has the given hash.
+func (s *Store) GetAPIKeyByHash(ctx context.Context, tokenHash string) (domain.APIKey, error) {
+	row := s.pool.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE token_hash=$1`, tokenHash)
+	return scanAPIKey(row)
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks the key revoked at the given time. A key keeps the time
+// it was first revoked.
+func (s *Store) RevokeAPIKey(ctx context.Context, id int64, at time.Time) (domain.APIKey, error) {
+	row := s.pool.QueryRow(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE id=$1
+		RETURNING `+apiKeyColumns, id, at)
+	return scanAPIKey(row)
+}
+
+const apiKeyColumns = `id, name, role, created_at, revoked_at`
+
+func scanAPIKey(row pgx.Row) (domain.APIKey, error) {
+	var key domain.APIKey
+	if err := row.Scan(&key.ID, &key.Name, &key.Role, &key.CreatedAt, &key.RevokedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.NewNotFoundError("api key not found", err)
+		}
+		return domain.APIKey{}, err
+	}
+	return key, nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+)
+
+// recordEventsTx appends events to the audit log and queues webhook
+// deliveries for them in one statement, keeping their order. The actor is
+// taken from ctx; empty ids are stored as NULL.
+func recordEventsTx(ctx context.Context, tx pgx.Tx, events ...domain.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	reasons := make([]string, 0, len(events))
+	repositories := make([]string, 0, len(events))
+	prIDs := make([]string, 0, len(events))
+	userIDs := make([]string, 0, len(events))
+	oldIDs := make([]string, 0, len(events))
+	newIDs := make([]string, 0, len(events))
+	var published, eventTypes []string
+	for _, e := range events {
+		reasons = append(reasons, e.Reason)
+		repositories = append(repositories, e.Repository)
+		prIDs = append(prIDs, e.PullRequestID)
+		userIDs = append(userIDs, e.UserID)
+		oldIDs = append(oldIDs, e.OldReviewerID)
+		newIDs = append(newIDs, e.NewReviewerID)
+		if eventType := domain.WebhookEventFor(e.Reason); eventType != "" {
+			published = append(published, e.Reason)
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	_, err := tx.Exec(ctx, `WITH inserted AS (
+		    INSERT INTO audit_events(actor, reason, repository, pull_request_id, user_id, old_reviewer_id, new_reviewer_id)
+		    SELECT $1, e.reason, e.repository, NULLIF(e.pr_id, ''), NULLIF(e.user_id, ''), NULLIF(e.old_id, ''), NULLIF(e.new_id, '')
+		    FROM unnest($2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[]) WITH ORDINALITY
+		        AS e(reason, repository, pr_id, user_id, old_id, new_id, ord)
+		    ORDER BY e.ord
+		    RETURNING *
+		)
+		INSERT INTO webhook_deliveries(webhook_id, event_type, payload)
+		SELECT w.id, m.event_type, jsonb_strip_nulls(jsonb_build_object(
+		           'event', m.event_type, 'id', i.id, 'occurred_at', i.occurred_at, 'actor', i.actor, 'reason', i.reason,
+		           'repository', NULLIF(i.repository, ''), 'pull_request_id', i.pull_request_id, 'user_id', i.user_id,
+		           'old_reviewer_id', i.old_reviewer_id, 'new_reviewer_id', i.new_reviewer_id))
+		FROM inserted i
+		JOIN (SELECT DISTINCT * FROM unnest($8::text[], $9::text[])) AS m(reason, event_type) ON m.reason = i.reason
+		JOIN webhooks w ON m.event_type = ANY(w.event_types)
+		ORDER BY i.id, w.id`,
+		service.ActorFromContext(ctx), reasons, repositories, prIDs, userIDs, oldIDs, newIDs, published, eventTypes)
+	return err
+}
+
+// GetPullRequestHistory returns audit events of the pull request, oldest first.
+func (s *Store) GetPullRequestHistory(ctx context.Context, repository, prID string) ([]domain.AuditEvent, error) {
+	var exists int
+	if err := s.pool.QueryRow(ctx, `SELECT 1 FROM pull_requests WHERE repository=$1 AND pull_request_id=$2`, repository, prID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("pull request not found", err)
+		}
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, auditEventColumns+` WHERE repository=$1 AND pull_request_id=$2 ORDER BY id`, repository, prID)
+	return scanAuditEvents(rows, err)
+}
+
+// GetUserHistory returns audit events where the user is the subject, the old
+// or the new reviewer, oldest first.
+func (s *Store) GetUserHistory(ctx context.Context, userID string) ([]domain.AuditEvent, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, auditEventColumns+`
+		WHERE user_id=$1 OR old_reviewer_id=$1 OR new_reviewer_id=$1
+		ORDER BY id`, userID)
+	return scanAuditEvents(rows, err)
+}
+
+const auditEventColumns = `SELECT id, occurred_at, actor, reason, repository,
+		COALESCE(pull_request_id, ''), COALESCE(user_id, ''), COALESCE(old_reviewer_id, ''), COALESCE(new_reviewer_id, '')
+		FROM audit_events`
+
+func scanAuditEvents(rows pgx.Rows, err error) ([]domain.AuditEvent, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.AuditEvent, 0)
+	for rows.Next() {
+		var e domain.AuditEvent
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.Reason, &e.Repository, &e.PullRequestID, &e.UserID, &e.OldReviewerID, &e.NewReviewerID); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// reviewerEvents describes replacements as audit events with the given reason.
+func reviewerEvents(reason string, replacements []domain.ReviewerReplacement) []domain.AuditEvent {
+	events := make([]domain.AuditEvent, 0, len(replacements))
+	for _, r := range replacements {
+		events = append(events, domain.AuditEvent{
+			Reason:        reason,
+			Repository:    r.Repository,
+			PullRequestID: r.PullRequestID,
+			OldReviewerID: r.OldReviewerID,
+			NewReviewerID: r.NewReviewerID,
+		})
+	}
+	return events
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+)
+
+const unavailabilityColumns = `id, user_id, starts_at, ends_at, reason, created_at`
+
+// CreateUnavailability stores the period; its user must exist.
+func (s *Store) CreateUnavailability(ctx context.Context, period domain.Unavailability) (domain.Unavailability, error) {
+	row := s.pool.QueryRow(ctx, `INSERT INTO user_unavailability(user_id, starts_at, ends_at, reason)
+		SELECT user_id, $2, $3, $4 FROM users WHERE user_id = $1
+		RETURNING `+unavailabilityColumns,
+		period.UserID, period.StartsAt, period.EndsAt, period.Reason)
+	created, err := scanUnavailability(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Unavailability{}, domain.NewNotFoundError("user not found", err)
+	}
+	return created, err
+}
+
+// ListUnavailability returns the periods of the user, or of every user when
+// userID is empty, ordered by start.
+func (s *Store) ListUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	if userID != "" {
+		if err := s.ensureUserExists(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT `+unavailabilityColumns+` FROM user_unavailability
+		WHERE ($1::text = '' OR user_id = $1)
+		ORDER BY starts_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := make([]domain.Unavailability, 0)
+	for rows.Next() {
+		period, err := scanUnavailability(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+	return periods, rows.Err()
+}
+
+func (s *Store) DeleteUnavailability(ctx context.Context, id int64) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM user_unavailability WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFoundError("unavailability not found", nil)
+	}
+	return nil
+}
+
+// ReassignUnavailableReviewers claims the periods covering now whose reviews
+// were not handed over yet and moves the OPEN reviews of their users to
+// available members of the reviewer's team. Periods locked by another replica
+// are skipped.
+func (s *Store) ReassignUnavailableReviewers(ctx context.Context, now time.Time, pick func([]service.Candidate) (string, bool)) ([]domain.ReviewerReplacement, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	userIDs, err := collectStrings(tx.Query(ctx, `UPDATE user_unavailability SET reviews_reassigned_at = $1
+		WHERE id IN (
+		    SELECT id FROM user_unavailability
+		    WHERE reviews_reassigned_at IS NULL AND starts_at <= $1 AND ends_at > $1
+		    FOR UPDATE SKIP LOCKED)
+		RETURNING user_id`, now))
+	if err != nil {
+		return nil, err
+	}
+	userIDs = slices.Compact(slices.Sorted(slices.Values(userIDs)))
+
+	replacements := []domain.ReviewerReplacement{}
+	if len(userIDs) > 0 {
+		assignments, err := listOpenAssignmentsTx(ctx, tx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		replacements, err = s.replaceReviewersTx(ctx, tx, assignments, pick)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordEventsTx(ctx, tx, reviewerEvents(domain.AuditReviewerUnavailable, replacements)...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return replacements, nil
+}
+
+func (s *Store) ensureUserExists(ctx context.Context, userID string) error {
+	var exists int
+	if err := s.pool.QueryRow(ctx, `SELECT 1 FROM users WHERE user_id=$1`, userID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("user not found", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func scanUnavailability(row pgx.Row) (domain.Unavailability, error) {
+	var period domain.Unavailability
+	err := row.Scan(&period.ID, &period.UserID, &period.StartsAt, &period.EndsAt, &period.Reason, &period.CreatedAt)
+	return period, err
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	migrate "github.com/GolovachevS/pr-reviewer-service/internal/db"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/storagetest"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ service.Repository = (*Store)(nil)
+
+// TestStoreConformance runs the shared repository contract against a real
+// PostgreSQL database pointed to by TEST_DATABASE_URL. The database is wiped
+// before every subtest.
+func TestStoreConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := migrate.Run(ctx, pool); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	storagetest.Run(t, func(t *testing.T) service.Repository {
+		if _, err := pool.Exec(ctx, `TRUNCATE teams, users, pull_requests, pull_request_reviewers, reviewer_reassignments, audit_events, webhooks, webhook_deliveries, api_keys, forge_accounts, ownership_rules, pull_request_files, repositories, repository_teams, user_unavailability RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return New(pool)
+	})
+}
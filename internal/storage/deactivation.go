@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+)
+
+type openAssignment struct {
+	repository string
+	prID       string
+	reviewerID string
+	authorID   string
+	teamName   string
+}
+
+// DeactivateUsers marks the selected users inactive and moves their OPEN reviews
+// to other active members of the reviewer's team within one transaction. A slot
+// is left empty when nobody is available. The number of queries does not depend
+// on the number of users or pull requests involved.
+func (s *Store) DeactivateUsers(ctx context.Context, input service.DeactivateUsersInput, pick func([]service.Candidate) (string, bool)) (domain.DeactivationResult, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.DeactivationResult{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if input.TeamName != "" {
+		if err := ensureTeamExistsTx(ctx, tx, input.TeamName); err != nil {
+			return domain.DeactivationResult{}, err
+		}
+	}
+
+	userIDs := input.UserIDs
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+
+	deactivated, err := collectStrings(tx.Query(ctx, `UPDATE users SET is_active=false, updated_at=NOW()
+		WHERE team_name=$1 OR user_id = ANY($2)
+		RETURNING user_id`, input.TeamName, userIDs))
+	if err != nil {
+		return domain.DeactivationResult{}, err
+	}
+
+	sort.Strings(deactivated)
+
+	found := make(map[string]struct{}, len(deactivated))
+	for _, id := range deactivated {
+		found[id] = struct{}{}
+	}
+	for _, id := range input.UserIDs {
+		if _, ok := found[id]; !ok {
+			return domain.DeactivationResult{}, domain.NewNotFoundError("user not found", nil)
+		}
+	}
+
+	events := make([]domain.AuditEvent, 0, len(deactivated))
+	for _, id := range deactivated {
+		events = append(events, domain.AuditEvent{Reason: domain.AuditUserDeactivated, UserID: id})
+	}
+	if err := recordEventsTx(ctx, tx, events...); err != nil {
+		return domain.DeactivationResult{}, err
+	}
+
+	result := domain.DeactivationResult{Deactivated: deactivated, Reassignments: []domain.ReviewerReplacement{}}
+	if len(deactivated) == 0 {
+		if err := tx.Commit(ctx); err != nil {
+			return domain.DeactivationResult{}, err
+		}
+		return result, nil
+	}
+
+	assignments, err := listOpenAssignmentsTx(ctx, tx, deactivated)
+	if err != nil {
+		return domain.DeactivationResult{}, err
+	}
+
+	result.Reassignments, err = s.replaceReviewersTx(ctx, tx, assignments, pick)
+	if err != nil {
+		return domain.DeactivationResult{}, err
+	}
+	if err := recordEventsTx(ctx, tx, reviewerEvents(domain.AuditReviewerDeactivated, result.Reassignments)...); err != nil {
+		return domain.DeactivationResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.DeactivationResult{}, err
+	}
+
+	return result, nil
+}
+
+// replaceReviewersTx removes every given assignment and, where pick finds one,
+// adds an active member of the assignment's team instead. Candidates already on
+// the PR and its author are never picked.
+func (s *Store) replaceReviewersTx(ctx context.Context, tx pgx.Tx, assignments []openAssignment, pick func([]service.Candidate) (string, bool)) ([]domain.ReviewerReplacement, error) {
+	replacements := []domain.ReviewerReplacement{}
+	if len(assignments) == 0 {
+		return replacements, nil
+	}
+
+	repositories := make([]string, 0, len(assignments))
+	prIDs := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		repositories = append(repositories, a.repository)
+		prIDs = append(prIDs, a.prID)
+	}
+	assigned, err := listAssignedByPRTx(ctx, tx, repositories, prIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	teamCandidates := make(map[string][]service.Candidate)
+	var removedRepos, removedPRs, removedUsers, addedRepos, addedPRs, addedUsers, swapOld []string
+	for _, a := range assignments {
+		candidates, ok := teamCandidates[a.teamName]
+		if !ok {
+			candidates, err = s.listActiveTeamMembersTx(ctx, tx, a.teamName, nil)
+			if err != nil {
+				return nil, err
+			}
+			teamCandidates[a.teamName] = candidates
+		}
+
+		key := prKey{a.repository, a.prID}
+		exclude := append([]string{a.reviewerID, a.authorID}, assigned[key]...)
+		replacement := domain.ReviewerReplacement{Repository: a.repository, PullRequestID: a.prID, OldReviewerID: a.reviewerID}
+
+		removedRepos = append(removedRepos, a.repository)
+		removedPRs = append(removedPRs, a.prID)
+		removedUsers = append(removedUsers, a.reviewerID)
+		available, _ := service.WithinCapacity(excludeCandidates(candidates, exclude))
+		if chosen, ok := pick(available); ok {
+			replacement.NewReviewerID = chosen
+			addedRepos = append(addedRepos, a.repository)
+			addedPRs = append(addedPRs, a.prID)
+			addedUsers = append(addedUsers, chosen)
+			swapOld = append(swapOld, a.reviewerID)
+			assigned[key] = append(assigned[key], chosen)
+			bumpLoad(candidates, chosen)
+		}
+		replacements = append(replacements, replacement)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM pull_request_reviewers r
+		USING unnest($1::text[], $2::text[], $3::text[]) AS d(repository, pull_request_id, reviewer_id)
+		WHERE r.repository = d.repository AND r.pull_request_id = d.pull_request_id AND r.reviewer_id = d.reviewer_id`,
+		removedRepos, removedPRs, removedUsers); err != nil {
+		return nil, err
+	}
+
+	if len(addedPRs) > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO pull_request_reviewers(repository, pull_request_id, reviewer_id)
+			SELECT * FROM unnest($1::text[], $2::text[], $3::text[])`, addedRepos, addedPRs, addedUsers); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO reviewer_reassignments(repository, pull_request_id, old_reviewer_id, new_reviewer_id)
+			SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[])`, addedRepos, addedPRs, swapOld, addedUsers); err != nil {
+			return nil, err
+		}
+	}
+
+	return replacements, nil
+}
+
+// listOpenAssignmentsTx locks OPEN pull requests reviewed by any of reviewerIDs
+// and returns the matching assignments.
+func listOpenAssignmentsTx(ctx context.Context, tx pgx.Tx, reviewerIDs []string) ([]openAssignment, error) {
+	rows, err := tx.Query(ctx, `SELECT r.repository, r.pull_request_id, r.reviewer_id, pr.author_id, COALESCE(u.team_name, '')
+		FROM pull_request_reviewers r
+		JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id
+		JOIN users u ON u.user_id = r.reviewer_id
+		WHERE r.reviewer_id = ANY($1) AND pr.status = 'OPEN'
+		ORDER BY r.repository, r.pull_request_id, r.reviewer_id
+		FOR UPDATE OF pr`, reviewerIDs)
+	return scanAssignments(rows, err)
+}
+
+// listForeignAssignmentsTx locks OPEN pull requests reviewed by any of
+// reviewerIDs whose author is in a different team than the reviewer. The
+// returned team is the author's one.
+func listForeignAssignmentsTx(ctx context.Context, tx pgx.Tx, reviewerIDs []string) ([]openAssignment, error) {
+	rows, err := tx.Query(ctx, `SELECT r.repository, r.pull_request_id, r.reviewer_id, pr.author_id, COALESCE(a.team_name, '')
+		FROM pull_request_reviewers r
+		JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id
+		JOIN users u ON u.user_id = r.reviewer_id
+		JOIN users a ON a.user_id = pr.author_id
+		WHERE r.reviewer_id = ANY($1) AND pr.status = 'OPEN' AND u.team_name IS DISTINCT FROM a.team_name
+		ORDER BY r.repository, r.pull_request_id, r.reviewer_id
+		FOR UPDATE OF pr`, reviewerIDs)
+	return scanAssignments(rows, err)
+}
+
+func scanAssignments(rows pgx.Rows, err error) ([]openAssignment, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []openAssignment
+	for rows.Next() {
+		var a openAssignment
+		if err := rows.Scan(&a.repository, &a.prID, &a.reviewerID, &a.authorID, &a.teamName); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+func listAssignedByPRTx(ctx context.Context, tx pgx.Tx, repositories, prIDs []string) (map[prKey][]string, error) {
+	rows, err := tx.Query(ctx, `SELECT DISTINCT r.repository, r.pull_request_id, r.reviewer_id
+		FROM pull_request_reviewers r
+		JOIN unnest($1::text[], $2::text[]) AS p(repository, pull_request_id)
+		  ON p.repository = r.repository AND p.pull_request_id = r.pull_request_id`, repositories, prIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assigned := make(map[prKey][]string)
+	for rows.Next() {
+		var key prKey
+		var reviewerID string
+		if err := rows.Scan(&key.repository, &key.id, &reviewerID); err != nil {
+			return nil, err
+		}
+		assigned[key] = append(assigned[key], reviewerID)
+	}
+	return assigned, rows.Err()
+}
+
+// bumpLoad accounts for a freshly assigned review so later picks in the same
+// batch see the updated load.
+func bumpLoad(candidates []service.Candidate, userID string) {
+	for i := range candidates {
+		if candidates[i].UserID == userID {
+			candidates[i].OpenReviews++
+			return
+		}
+	}
+}
+
+func collectStrings(rows pgx.Rows, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// LinkForgeAccount maps the login to the user, replacing its previous mapping.
+func (s *Store) LinkForgeAccount(ctx context.Context, account domain.ForgeAccount) (domain.ForgeAccount, error) {
+	tag, err := s.pool.Exec(ctx, `INSERT INTO forge_accounts(forge, login, user_id)
+		SELECT $1, $2, user_id FROM users WHERE user_id = $3
+		ON CONFLICT (forge, login) DO UPDATE SET user_id = EXCLUDED.user_id`,
+		account.Forge, account.Login, account.UserID)
+	if err != nil {
+		return domain.ForgeAccount{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ForgeAccount{}, domain.NewNotFoundError("user not found", nil)
+	}
+	return account, nil
+}
+
+func (s *Store) UnlinkForgeAccount(ctx context.Context, forge, login string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM forge_accounts WHERE forge=$1 AND login=$2`, forge, login)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFoundError("forge account not found", nil)
+	}
+	return nil
+}
+
+// ListForgeAccounts returns mappings ordered by forge and login; an empty
+// forge matches every platform.
+func (s *Store) ListForgeAccounts(ctx context.Context, forge string) ([]domain.ForgeAccount, error) {
+	rows, err := s.pool.Query(ctx, `SELECT forge, login, user_id FROM forge_accounts
+		WHERE ($1::text = '' OR forge = $1)
+		ORDER BY forge, login`, forge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]domain.ForgeAccount, 0)
+	for rows.Next() {
+		var a domain.ForgeAccount
+		if err := rows.Scan(&a.Forge, &a.Login, &a.UserID); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (s *Store) ResolveForgeLogin(ctx context.Context, forge, login string) (string, error) {
+	var userID string
+	err := s.pool.QueryRow(ctx, `SELECT user_id FROM forge_accounts WHERE forge=$1 AND login=$2`, forge, login).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.NewNotFoundError("forge login is not linked to a user", err)
+		}
+		return "", err
+	}
+	return userID, nil
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddTeamMembers adds users to an existing team. Unknown users are created,
+// users without a team are attached, and users of another team are rejected.
+func (s *Store) AddTeamMembers(ctx context.Context, teamName string, members []domain.TeamMember) (domain.Team, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.Team{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if err := ensureTeamExistsTx(ctx, tx, teamName); err != nil {
+		return domain.Team{}, err
+	}
+
+	for _, member := range members {
+		tag, execErr := tx.Exec(
+			ctx,
+			`INSERT INTO users(user_id, username, team_name, is_active, tags, max_open_reviews)
+			 VALUES($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (user_id)
+			 DO UPDATE SET username = EXCLUDED.username,
+			               team_name = EXCLUDED.team_name,
+			               is_active = EXCLUDED.is_active,
+			               tags = EXCLUDED.tags,
+			               max_open_reviews = EXCLUDED.max_open_reviews,
+			               updated_at = NOW()
+			 WHERE users.team_name IS NULL OR users.team_name = EXCLUDED.team_name`,
+			member.UserID,
+			member.Username,
+			teamName,
+			member.IsActive,
+			textArray(member.Tags),
+			member.MaxOpenReviews,
+		)
+		if execErr != nil {
+			return domain.Team{}, execErr
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.Team{}, domain.NewUserInTeamError()
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Team{}, err
+	}
+
+	return s.GetTeam(ctx, teamName)
+}
+
+// RemoveTeamMembers detaches users from the team. Users keep their history and
+// may be added to a team again later.
+func (s *Store) RemoveTeamMembers(ctx context.Context, input service.RemoveTeamMembersInput, pick func([]service.Candidate) (string, bool)) (domain.MembershipChange, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.MembershipChange{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if err := ensureTeamExistsTx(ctx, tx, input.TeamName); err != nil {
+		return domain.MembershipChange{}, err
+	}
+
+	removed, err := collectStrings(tx.Query(ctx, `UPDATE users SET team_name=NULL, updated_at=NOW()
+		WHERE team_name=$1 AND user_id = ANY($2)
+		RETURNING user_id`, input.TeamName, input.UserIDs))
+	if err != nil {
+		return domain.MembershipChange{}, err
+	}
+
+	found := make(map[string]struct{}, len(removed))
+	for _, id := range removed {
+		found[id] = struct{}{}
+	}
+	for _, id := range input.UserIDs {
+		if _, ok := found[id]; !ok {
+			return domain.MembershipChange{}, domain.NewNotFoundError("user is not a member of the team", nil)
+		}
+	}
+
+	return s.finishMembershipChangeTx(ctx, tx, input.TeamName, removed, input.ReassignReviews, pick)
+}
+
+// MoveTeamMember moves a user into another existing team.
+func (s *Store) MoveTeamMember(ctx context.Context, input service.MoveTeamMemberInput, pick func([]service.Candidate) (string, bool)) (domain.MembershipChange, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.MembershipChange{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if err := ensureTeamExistsTx(ctx, tx, input.TeamName); err != nil {
+		return domain.MembershipChange{}, err
+	}
+
+	var userID string
+	row := tx.QueryRow(ctx, `UPDATE users SET team_name=$2, updated_at=NOW() WHERE user_id=$1 RETURNING user_id`, input.UserID, input.TeamName)
+	if scanErr := row.Scan(&userID); scanErr != nil {
+		if errors.Is(scanErr, pgx.ErrNoRows) {
+			return domain.MembershipChange{}, domain.NewNotFoundError("user not found", scanErr)
+		}
+		return domain.MembershipChange{}, scanErr
+	}
+
+	return s.finishMembershipChangeTx(ctx, tx, input.TeamName, []string{userID}, input.ReassignReviews, pick)
+}
+
+// finishMembershipChangeTx optionally hands OPEN reviews of the moved users that
+// now fall outside the author's team to the author's teammates, commits and
+// returns the updated team.
+func (s *Store) finishMembershipChangeTx(ctx context.Context, tx pgx.Tx, teamName string, userIDs []string, reassign bool, pick func([]service.Candidate) (string, bool)) (domain.MembershipChange, error) {
+	replacements := []domain.ReviewerReplacement{}
+	if reassign && len(userIDs) > 0 {
+		assignments, err := listForeignAssignmentsTx(ctx, tx, userIDs)
+		if err != nil {
+			return domain.MembershipChange{}, err
+		}
+		replacements, err = s.replaceReviewersTx(ctx, tx, assignments, pick)
+		if err != nil {
+			return domain.MembershipChange{}, err
+		}
+		if err := recordEventsTx(ctx, tx, reviewerEvents(domain.AuditTeamChanged, replacements)...); err != nil {
+			return domain.MembershipChange{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.MembershipChange{}, err
+	}
+
+	team, err := s.GetTeam(ctx, teamName)
+	if err != nil {
+		return domain.MembershipChange{}, err
+	}
+	return domain.MembershipChange{Team: team, Reassignments: replacements}, nil
+}
+
+func ensureTeamExistsTx(ctx context.Context, tx pgx.Tx, teamName string) error {
+	var name string
+	if err := tx.QueryRow(ctx, "SELECT team_name FROM teams WHERE team_name=$1", teamName).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("team not found", err)
+		}
+		return err
+	}
+	return nil
+}
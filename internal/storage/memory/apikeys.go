@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+func (s *Store) CreateAPIKey(_ context.Context, key domain.APIKey, tokenHash string) (domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key.ID = int64(len(s.apiKeys) + 1)
+	key.CreatedAt = s.now()
+	key.RevokedAt = nil
+	s.apiKeys = append(s.apiKeys, apiKey{APIKey: key, tokenHash: tokenHash})
+	return key, nil
+}
+
+// GetAPIKeyByHash returns the key, revoked or not, whose token has the given hash.
+func (s *Store) GetAPIKeyByHash(_ context.Context, tokenHash string) (domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.apiKeys {
+		if key.tokenHash == tokenHash {
+			return key.APIKey, nil
+		}
+	}
+	return domain.APIKey{}, domain.NewNotFoundError("api key not found", nil)
+}
+
+func (s *Store) ListAPIKeys(_ context.Context) ([]domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]domain.APIKey, 0, len(s.apiKeys))
+	for _, key := range s.apiKeys {
+		keys = append(keys, key.APIKey)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks the key revoked at the given time. A key keeps the time
+// it was first revoked.
+func (s *Store) RevokeAPIKey(_ context.Context, id int64, at time.Time) (domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 1 || id > int64(len(s.apiKeys)) {
+		return domain.APIKey{}, domain.NewNotFoundError("api key not found", nil)
+	}
+	key := &s.apiKeys[id-1]
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return key.APIKey, nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// recordLocked appends events to the audit log on behalf of the actor in ctx
+// and queues webhook deliveries for them.
+func (s *Store) recordLocked(ctx context.Context, events ...domain.AuditEvent) {
+	now := s.now()
+	actor := service.ActorFromContext(ctx)
+	for _, e := range events {
+		s.lastEventID++
+		e.ID = s.lastEventID
+		e.OccurredAt = now
+		e.Actor = actor
+		s.events = append(s.events, e)
+		s.enqueueDeliveriesLocked(e)
+	}
+}
+
+// GetPullRequestHistory returns audit events of the pull request, oldest first.
+func (s *Store) GetPullRequestHistory(_ context.Context, repository, prID string) ([]domain.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.pullRequests[prKey{repository, prID}]; !ok {
+		return nil, domain.NewNotFoundError("pull request not found", nil)
+	}
+	events := make([]domain.AuditEvent, 0)
+	for _, e := range s.events {
+		if e.Repository == repository && e.PullRequestID == prID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// GetUserHistory returns audit events where the user is the subject, the old
+// or the new reviewer, oldest first.
+func (s *Store) GetUserHistory(_ context.Context, userID string) ([]domain.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, domain.NewNotFoundError("user not found", nil)
+	}
+	events := make([]domain.AuditEvent, 0)
+	for _, e := range s.events {
+		if e.UserID == userID || e.OldReviewerID == userID || e.NewReviewerID == userID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// reviewerEvents describes replacements as audit events with the given reason.
+func reviewerEvents(reason string, replacements []domain.ReviewerReplacement) []domain.AuditEvent {
+	events := make([]domain.AuditEvent, 0, len(replacements))
+	for _, r := range replacements {
+		events = append(events, domain.AuditEvent{
+			Reason:        reason,
+			Repository:    r.Repository,
+			PullRequestID: r.PullRequestID,
+			OldReviewerID: r.OldReviewerID,
+			NewReviewerID: r.NewReviewerID,
+		})
+	}
+	return events
+}
+
+func assignedEvents(pr prKey, reviewers []string) []domain.AuditEvent {
+	events := make([]domain.AuditEvent, 0, len(reviewers))
+	for _, id := range reviewers {
+		events = append(events, domain.AuditEvent{Reason: domain.AuditAssigned, Repository: pr.repository, PullRequestID: pr.id, NewReviewerID: id})
+	}
+	return events
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+type unavailability struct {
+	domain.Unavailability
+	reassigned bool
+}
+
+// CreateUnavailability stores the period; its user must exist.
+func (s *Store) CreateUnavailability(_ context.Context, period domain.Unavailability) (domain.Unavailability, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[period.UserID]; !ok {
+		return domain.Unavailability{}, domain.NewNotFoundError("user not found", nil)
+	}
+	s.lastPeriodID++
+	period.ID = s.lastPeriodID
+	period.CreatedAt = s.now()
+	s.unavailability = append(s.unavailability, &unavailability{Unavailability: period})
+	return period, nil
+}
+
+// ListUnavailability returns the periods of the user, or of every user when
+// userID is empty, ordered by start.
+func (s *Store) ListUnavailability(_ context.Context, userID string) ([]domain.Unavailability, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.users[userID]; userID != "" && !ok {
+		return nil, domain.NewNotFoundError("user not found", nil)
+	}
+
+	periods := make([]domain.Unavailability, 0)
+	for _, period := range s.unavailability {
+		if userID == "" || period.UserID == userID {
+			periods = append(periods, period.Unavailability)
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		if !periods[i].StartsAt.Equal(periods[j].StartsAt) {
+			return periods[i].StartsAt.Before(periods[j].StartsAt)
+		}
+		return periods[i].ID < periods[j].ID
+	})
+	return periods, nil
+}
+
+func (s *Store) DeleteUnavailability(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, period := range s.unavailability {
+		if period.ID == id {
+			s.unavailability = append(s.unavailability[:i], s.unavailability[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NewNotFoundError("unavailability not found", nil)
+}
+
+// ReassignUnavailableReviewers marks the periods covering now handled and
+// moves the OPEN reviews of their users to available members of the
+// reviewer's team.
+func (s *Store) ReassignUnavailableReviewers(ctx context.Context, now time.Time, pick func([]service.Candidate) (string, bool)) ([]domain.ReviewerReplacement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	away := make(map[string]bool)
+	for _, period := range s.unavailability {
+		if !period.reassigned && covers(period.Unavailability, now) {
+			period.reassigned = true
+			away[period.UserID] = true
+		}
+	}
+
+	replacements := []domain.ReviewerReplacement{}
+	for _, pr := range s.pullRequestsByKeyLocked() {
+		if pr.status != domain.StatusOpen {
+			continue
+		}
+		for _, reviewerID := range sortedCopy(pr.reviewers) {
+			if away[reviewerID] {
+				replacements = append(replacements, s.replaceReviewerLocked(pr, reviewerID, s.users[reviewerID].teamName, pick))
+			}
+		}
+	}
+	s.recordLocked(ctx, reviewerEvents(domain.AuditReviewerUnavailable, replacements)...)
+	return replacements, nil
+}
+
+// availableLocked reports whether u may be picked as a reviewer now.
+func (s *Store) availableLocked(u *user) bool {
+	if !u.isActive {
+		return false
+	}
+	now := s.now()
+	for _, period := range s.unavailability {
+		if period.UserID == u.id && covers(period.Unavailability, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func covers(period domain.Unavailability, at time.Time) bool {
+	return !period.StartsAt.After(at) && period.EndsAt.After(at)
+}
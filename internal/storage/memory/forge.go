@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// LinkForgeAccount maps the login to the user, replacing its previous mapping.
+func (s *Store) LinkForgeAccount(_ context.Context, account domain.ForgeAccount) (domain.ForgeAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[account.UserID]; !ok {
+		return domain.ForgeAccount{}, domain.NewNotFoundError("user not found", nil)
+	}
+	s.forgeAccounts[forgeLogin{forge: account.Forge, login: account.Login}] = account.UserID
+	return account, nil
+}
+
+func (s *Store) UnlinkForgeAccount(_ context.Context, forge, login string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := forgeLogin{forge: forge, login: login}
+	if _, ok := s.forgeAccounts[key]; !ok {
+		return domain.NewNotFoundError("forge account not found", nil)
+	}
+	delete(s.forgeAccounts, key)
+	return nil
+}
+
+// ListForgeAccounts returns mappings ordered by forge and login; an empty
+// forge matches every platform.
+func (s *Store) ListForgeAccounts(_ context.Context, forge string) ([]domain.ForgeAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accounts := make([]domain.ForgeAccount, 0)
+	for key, userID := range s.forgeAccounts {
+		if forge == "" || key.forge == forge {
+			accounts = append(accounts, domain.ForgeAccount{Forge: key.forge, Login: key.login, UserID: userID})
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Forge != accounts[j].Forge {
+			return accounts[i].Forge < accounts[j].Forge
+		}
+		return accounts[i].Login < accounts[j].Login
+	})
+	return accounts, nil
+}
+
+func (s *Store) ResolveForgeLogin(_ context.Context, forge, login string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	userID, ok := s.forgeAccounts[forgeLogin{forge: forge, login: login}]
+	if !ok {
+		return "", domain.NewNotFoundError("forge login is not linked to a user", nil)
+	}
+	return userID, nil
+}
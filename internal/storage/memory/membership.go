@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// AddTeamMembers adds users to an existing team. Unknown users are created,
+// users without a team are attached, and users of another team are rejected.
+func (s *Store) AddTeamMembers(_ context.Context, teamName string, members []domain.TeamMember) (domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[teamName]; !ok {
+		return domain.Team{}, domain.NewNotFoundError("team not found", nil)
+	}
+	for _, member := range members {
+		if u, ok := s.users[member.UserID]; ok && u.teamName != "" && u.teamName != teamName {
+			return domain.Team{}, domain.NewUserInTeamError()
+		}
+	}
+
+	for _, member := range members {
+		s.users[member.UserID] = &user{
+			id:             member.UserID,
+			username:       member.Username,
+			teamName:       teamName,
+			isActive:       member.IsActive,
+			tags:           sortedCopy(member.Tags),
+			maxOpenReviews: copyInt(member.MaxOpenReviews),
+		}
+	}
+
+	return s.teamLocked(teamName)
+}
+
+// RemoveTeamMembers detaches users from the team.
+func (s *Store) RemoveTeamMembers(ctx context.Context, input service.RemoveTeamMembersInput, pick func([]service.Candidate) (string, bool)) (domain.MembershipChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[input.TeamName]; !ok {
+		return domain.MembershipChange{}, domain.NewNotFoundError("team not found", nil)
+	}
+	for _, id := range input.UserIDs {
+		if u, ok := s.users[id]; !ok || u.teamName != input.TeamName {
+			return domain.MembershipChange{}, domain.NewNotFoundError("user is not a member of the team", nil)
+		}
+	}
+
+	for _, id := range input.UserIDs {
+		s.users[id].teamName = ""
+	}
+
+	return s.finishMembershipChangeLocked(ctx, input.TeamName, input.UserIDs, input.ReassignReviews, pick)
+}
+
+// MoveTeamMember moves a user into another existing team.
+func (s *Store) MoveTeamMember(ctx context.Context, input service.MoveTeamMemberInput, pick func([]service.Candidate) (string, bool)) (domain.MembershipChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[input.TeamName]; !ok {
+		return domain.MembershipChange{}, domain.NewNotFoundError("team not found", nil)
+	}
+	u, ok := s.users[input.UserID]
+	if !ok {
+		return domain.MembershipChange{}, domain.NewNotFoundError("user not found", nil)
+	}
+	u.teamName = input.TeamName
+
+	return s.finishMembershipChangeLocked(ctx, input.TeamName, []string{input.UserID}, input.ReassignReviews, pick)
+}
+
+// finishMembershipChangeLocked optionally hands OPEN reviews of the moved users
+// that now fall outside the author's team to the author's teammates.
+func (s *Store) finishMembershipChangeLocked(ctx context.Context, teamName string, userIDs []string, reassign bool, pick func([]service.Candidate) (string, bool)) (domain.MembershipChange, error) {
+	replacements := []domain.ReviewerReplacement{}
+	if reassign {
+		for _, pr := range s.pullRequestsByKeyLocked() {
+			if pr.status != domain.StatusOpen {
+				continue
+			}
+			authorTeam := s.users[pr.authorID].teamName
+			for _, reviewerID := range sortedCopy(pr.reviewers) {
+				if !containsID(userIDs, reviewerID) || s.users[reviewerID].teamName == authorTeam {
+					continue
+				}
+				replacements = append(replacements, s.replaceReviewerLocked(pr, reviewerID, authorTeam, pick))
+			}
+		}
+		s.recordLocked(ctx, reviewerEvents(domain.AuditTeamChanged, replacements)...)
+	}
+
+	team, err := s.teamLocked(teamName)
+	if err != nil {
+		return domain.MembershipChange{}, err
+	}
+	return domain.MembershipChange{Team: team, Reassignments: replacements}, nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// CreateOwnershipRule stores the rule; its team or every one of its users must
+// exist.
+func (s *Store) CreateOwnershipRule(_ context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.repositories[rule.Repository]; !ok {
+		return domain.OwnershipRule{}, domain.NewNotFoundError("repository not found", nil)
+	}
+	if rule.TeamName != "" {
+		if _, ok := s.teams[rule.TeamName]; !ok {
+			return domain.OwnershipRule{}, domain.NewNotFoundError("team not found", nil)
+		}
+	}
+	for _, userID := range rule.UserIDs {
+		if _, ok := s.users[userID]; !ok {
+			return domain.OwnershipRule{}, domain.NewNotFoundError("user not found", nil)
+		}
+	}
+
+	s.lastRuleID++
+	rule.ID = s.lastRuleID
+	rule.UserIDs = append([]string(nil), rule.UserIDs...)
+	rule.CreatedAt = s.now()
+	s.ownershipRules = append(s.ownershipRules, rule)
+	return rule, nil
+}
+
+// ListOwnershipRules returns rules ordered by repository and creation; an
+// empty repository matches every repository.
+func (s *Store) ListOwnershipRules(_ context.Context, repository string) ([]domain.OwnershipRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ownershipRulesLocked(repository), nil
+}
+
+func (s *Store) DeleteOwnershipRule(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, rule := range s.ownershipRules {
+		if rule.ID == id {
+			s.ownershipRules = append(s.ownershipRules[:i], s.ownershipRules[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NewNotFoundError("ownership rule not found", nil)
+}
+
+func (s *Store) ownershipRulesLocked(repository string) []domain.OwnershipRule {
+	rules := make([]domain.OwnershipRule, 0)
+	for _, rule := range s.ownershipRules {
+		if repository == "" || rule.Repository == repository {
+			rule.UserIDs = append([]string(nil), rule.UserIDs...)
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Repository < rules[j].Repository })
+	return rules
+}
+
+// ownerCandidatesLocked returns, for every rule of the PR's repository
+// matching one of its files, the active users below their open review cap
+// able to satisfy it other than the author.
+func (s *Store) ownerCandidatesLocked(pr *pullRequest) []service.OwnerCandidates {
+	if pr.repository == "" || len(pr.files) == 0 {
+		return nil
+	}
+
+	var owners []service.OwnerCandidates
+	for _, rule := range service.MatchingRules(s.ownershipRulesLocked(pr.repository), pr.files) {
+		candidates := s.activeTeamMembersLocked(rule.TeamName, []string{pr.authorID})
+		for _, userID := range rule.UserIDs {
+			u, ok := s.users[userID]
+			if !ok || !s.availableLocked(u) || userID == pr.authorID {
+				continue
+			}
+			candidates = append(candidates, s.candidateLocked(u))
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].UserID < candidates[j].UserID })
+		available, _ := service.WithinCapacity(candidates)
+		owners = append(owners, service.OwnerCandidates{Rule: rule, Candidates: available})
+	}
+	return owners
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// ListPullRequests returns a page of pull requests matching the filter.
+func (s *Store) ListPullRequests(_ context.Context, filter service.PullRequestFilter) (domain.PullRequestList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if filter.TeamName != "" {
+		if _, ok := s.teams[filter.TeamName]; !ok {
+			return domain.PullRequestList{}, domain.NewNotFoundError("team not found", nil)
+		}
+	}
+
+	prs := s.sortedPullRequestsLocked()
+	ascending := filter.Order == service.OrderOldest
+	if ascending {
+		for i, j := 0, len(prs)-1; i < j; i, j = i+1, j-1 {
+			prs[i], prs[j] = prs[j], prs[i]
+		}
+	}
+
+	result := domain.PullRequestList{PullRequests: make([]domain.PullRequest, 0)}
+	for _, pr := range prs {
+		if !s.matchesPullRequestFilterLocked(pr, filter) {
+			continue
+		}
+		if filter.After != nil && !filter.After.Follows(pr.createdAt, pr.repository, pr.id, ascending) {
+			continue
+		}
+		if len(result.PullRequests) == filter.Limit {
+			last := result.PullRequests[len(result.PullRequests)-1]
+			result.NextCursor = service.Cursor{CreatedAt: last.CreatedAt, Repository: last.Repository, ID: last.PullRequestID}.Encode()
+			break
+		}
+		result.PullRequests = append(result.PullRequests, pr.toDomain())
+	}
+	return result, nil
+}
+
+func (s *Store) matchesPullRequestFilterLocked(pr *pullRequest, filter service.PullRequestFilter) bool {
+	switch {
+	case filter.Repository != "" && pr.repository != filter.Repository:
+		return false
+	case filter.AuthorID != "" && pr.authorID != filter.AuthorID:
+		return false
+	case filter.TeamName != "" && s.users[pr.authorID].teamName != filter.TeamName:
+		return false
+	case filter.ReviewerID != "" && !containsID(pr.reviewers, filter.ReviewerID):
+		return false
+	case len(filter.Statuses) > 0 && !containsID(filter.Statuses, pr.status):
+		return false
+	case filter.NameContains != "" && !strings.Contains(strings.ToLower(pr.name), strings.ToLower(filter.NameContains)):
+		return false
+	}
+	return inRange(&pr.createdAt, filter.CreatedFrom, filter.CreatedTo) &&
+		(filter.MergedFrom == nil && filter.MergedTo == nil || inRange(pr.mergedAt, filter.MergedFrom, filter.MergedTo))
+}
+
+// inRange reports whether t is set and falls into [from, to); nil bounds are open.
+func inRange(t, from, to *time.Time) bool {
+	if t == nil {
+		return false
+	}
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	return to == nil || t.Before(*to)
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+type repository struct {
+	name      string
+	teams     []string
+	createdAt time.Time
+}
+
+// CreateRepository registers the repository and links it to its teams, which
+// must exist.
+func (s *Store) CreateRepository(_ context.Context, repo domain.Repository) (domain.Repository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.repositories[repo.Name]; exists {
+		return domain.Repository{}, domain.NewRepositoryExistsError(nil)
+	}
+	if err := s.ensureTeamsLocked(repo.Teams); err != nil {
+		return domain.Repository{}, err
+	}
+
+	r := &repository{name: repo.Name, teams: sortedCopy(repo.Teams), createdAt: s.now()}
+	s.repositories[repo.Name] = r
+	return r.toDomain(), nil
+}
+
+func (s *Store) GetRepository(_ context.Context, name string) (domain.Repository, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.repositories[name]
+	if !ok {
+		return domain.Repository{}, domain.NewNotFoundError("repository not found", nil)
+	}
+	return r.toDomain(), nil
+}
+
+// ListRepositories returns repositories ordered by name; a non-empty teamName
+// keeps the repositories linked to that team.
+func (s *Store) ListRepositories(_ context.Context, teamName string) ([]domain.Repository, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if teamName != "" {
+		if _, ok := s.teams[teamName]; !ok {
+			return nil, domain.NewNotFoundError("team not found", nil)
+		}
+	}
+
+	repos := make([]domain.Repository, 0)
+	for _, r := range s.repositories {
+		if teamName == "" || containsID(r.teams, teamName) {
+			repos = append(repos, r.toDomain())
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+	return repos, nil
+}
+
+// SetRepositoryTeams replaces the teams linked to the repository.
+func (s *Store) SetRepositoryTeams(_ context.Context, name string, teams []string) (domain.Repository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.repositories[name]
+	if !ok {
+		return domain.Repository{}, domain.NewNotFoundError("repository not found", nil)
+	}
+	if err := s.ensureTeamsLocked(teams); err != nil {
+		return domain.Repository{}, err
+	}
+	r.teams = sortedCopy(teams)
+	return r.toDomain(), nil
+}
+
+// DeleteRepository removes a repository that has no pull requests together
+// with its ownership rules.
+func (s *Store) DeleteRepository(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.repositories[name]; !ok {
+		return domain.NewNotFoundError("repository not found", nil)
+	}
+	for key := range s.pullRequests {
+		if key.repository == name {
+			return domain.NewRepositoryInUseError()
+		}
+	}
+
+	delete(s.repositories, name)
+	s.ownershipRules = slices.DeleteFunc(s.ownershipRules, func(rule domain.OwnershipRule) bool {
+		return rule.Repository == name
+	})
+	return nil
+}
+
+func (s *Store) ensureTeamsLocked(teams []string) error {
+	for _, teamName := range teams {
+		if _, ok := s.teams[teamName]; !ok {
+			return domain.NewNotFoundError("team not found", nil)
+		}
+	}
+	return nil
+}
+
+func (r *repository) toDomain() domain.Repository {
+	teams := sortedCopy(r.teams)
+	if teams == nil {
+		teams = []string{}
+	}
+	return domain.Repository{Name: r.name, Teams: teams, CreatedAt: r.createdAt}
+}
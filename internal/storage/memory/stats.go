@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// GetReviewerStats returns review load per user, optionally limited to a team.
+func (s *Store) GetReviewerStats(_ context.Context, teamName string) ([]domain.ReviewerStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if teamName != "" {
+		if _, ok := s.teams[teamName]; !ok {
+			return nil, domain.NewNotFoundError("team not found", nil)
+		}
+	}
+
+	stats := make([]domain.ReviewerStats, 0)
+	for _, u := range s.users {
+		if teamName != "" && u.teamName != teamName {
+			continue
+		}
+		item := domain.ReviewerStats{UserID: u.id, Username: u.username, TeamName: u.teamName, IsActive: u.isActive}
+		for _, pr := range s.pullRequests {
+			if !containsID(pr.reviewers, u.id) {
+				continue
+			}
+			item.AssignedTotal++
+			switch pr.status {
+			case domain.StatusOpen:
+				item.OpenReviews++
+			case domain.StatusMerged:
+				item.MergedReviews++
+			case domain.StatusClosed:
+				item.ClosedReviews++
+			}
+		}
+		for _, r := range s.reassignments {
+			if r.oldID == u.id {
+				item.ReassignedFrom++
+			}
+			if r.newID == u.id {
+				item.ReassignedTo++
+			}
+		}
+		stats = append(stats, item)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TeamName != stats[j].TeamName {
+			return stats[i].TeamName < stats[j].TeamName
+		}
+		return stats[i].Username < stats[j].Username
+	})
+	return stats, nil
+}
+
+// GetTeamStats returns aggregates per team, optionally limited to a single team.
+func (s *Store) GetTeamStats(_ context.Context, teamName string) ([]domain.TeamStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if teamName != "" {
+		if _, ok := s.teams[teamName]; !ok {
+			return nil, domain.NewNotFoundError("team not found", nil)
+		}
+	}
+
+	stats := make([]domain.TeamStats, 0)
+	for name := range s.teams {
+		if teamName != "" && name != teamName {
+			continue
+		}
+		item := domain.TeamStats{TeamName: name}
+		for _, u := range s.users {
+			if u.teamName != name {
+				continue
+			}
+			item.MembersTotal++
+			if u.isActive {
+				item.ActiveMembers++
+			}
+		}
+		for _, pr := range s.pullRequests {
+			if author := s.users[pr.authorID]; author != nil && author.teamName == name {
+				item.PullRequestsTotal++
+				switch pr.status {
+				case domain.StatusDraft:
+					item.DraftPullRequests++
+				case domain.StatusOpen:
+					item.OpenPullRequests++
+				case domain.StatusMerged:
+					item.MergedPullRequests++
+				case domain.StatusClosed:
+					item.ClosedPullRequests++
+				}
+			}
+			for _, reviewerID := range pr.reviewers {
+				if reviewer := s.users[reviewerID]; reviewer != nil && reviewer.teamName == name {
+					item.AssignmentsTotal++
+					if pr.status == domain.StatusOpen {
+						item.OpenAssignments++
+					}
+				}
+			}
+		}
+		for _, r := range s.reassignments {
+			if old := s.users[r.oldID]; old != nil && old.teamName == name {
+				item.Reassignments++
+			}
+		}
+		stats = append(stats, item)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TeamName < stats[j].TeamName })
+	return stats, nil
+}
+
+// GetPullRequestStats returns assignment counters for a single pull request.
+func (s *Store) GetPullRequestStats(_ context.Context, repository, prID string) (domain.PullRequestStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pr, ok := s.pullRequests[prKey{repository, prID}]
+	if !ok {
+		return domain.PullRequestStats{}, domain.NewNotFoundError("pull request not found", nil)
+	}
+
+	item := domain.PullRequestStats{
+		PullRequestID:   pr.id,
+		Repository:      pr.repository,
+		PullRequestName: pr.name,
+		AuthorID:        pr.authorID,
+		Status:          pr.status,
+		ReviewersCount:  len(pr.reviewers),
+	}
+	for _, r := range s.reassignments {
+		if r.pr == pr.key() {
+			item.Reassignments++
+		}
+	}
+	return item, nil
+}
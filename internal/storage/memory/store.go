@@ -0,0 +1,665 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// Store implements the service.Repository interface in process memory.
+// It mirrors the PostgreSQL store semantics and is meant for tests and local runs.
+type Store struct {
+	mu             sync.RWMutex
+	now            func() time.Time
+	teams          map[string]*team
+	users          map[string]*user
+	pullRequests   map[prKey]*pullRequest
+	reassignments  []reassignment
+	events         []domain.AuditEvent
+	lastEventID    int64
+	webhooks       []domain.Webhook
+	deliveries     []*domain.WebhookDelivery
+	lastHookID     int64
+	lastDeliveryID int64
+	apiKeys        []apiKey
+	forgeAccounts  map[forgeLogin]string
+	ownershipRules []domain.OwnershipRule
+	lastRuleID     int64
+	repositories   map[string]*repository
+	unavailability []*unavailability
+	lastPeriodID   int64
+}
+
+type team struct {
+	name              string
+	requiredReviewers int
+	minReviewers      int
+	maxOpenReviews    int
+}
+
+type user struct {
+	id             string
+	username       string
+	teamName       string
+	isActive       bool
+	tags           []string
+	maxOpenReviews *int
+}
+
+type pullRequest struct {
+	id         string
+	name       string
+	authorID   string
+	status     string
+	repository string
+	files      []string
+	tags       []string
+	reviewers  []string
+	createdAt  time.Time
+	mergedAt   *time.Time
+	closedAt   *time.Time
+}
+
+type apiKey struct {
+	domain.APIKey
+	tokenHash string
+}
+
+type forgeLogin struct {
+	forge string
+	login string
+}
+
+// prKey identifies a pull request; its id is unique within the repository.
+type prKey struct {
+	repository string
+	id         string
+}
+
+type reassignment struct {
+	pr    prKey
+	oldID string
+	newID string
+}
+
+// New returns an empty in-memory store.
+func New() *Store {
+	return &Store{
+		now:           func() time.Time { return time.Now().UTC() },
+		teams:         make(map[string]*team),
+		users:         make(map[string]*user),
+		pullRequests:  make(map[prKey]*pullRequest),
+		forgeAccounts: make(map[forgeLogin]string),
+		repositories:  make(map[string]*repository),
+	}
+}
+
+func (s *Store) CreateTeam(_ context.Context, t domain.Team) (domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.teams[t.TeamName]; exists {
+		return domain.Team{}, domain.NewTeamExistsError(nil)
+	}
+
+	s.teams[t.TeamName] = &team{name: t.TeamName, requiredReviewers: t.RequiredReviewers, minReviewers: t.MinReviewers, maxOpenReviews: t.MaxOpenReviews}
+	for _, member := range t.Members {
+		s.users[member.UserID] = &user{
+			id:             member.UserID,
+			username:       member.Username,
+			teamName:       t.TeamName,
+			isActive:       member.IsActive,
+			tags:           sortedCopy(member.Tags),
+			maxOpenReviews: copyInt(member.MaxOpenReviews),
+		}
+	}
+
+	return s.teamLocked(t.TeamName)
+}
+
+func (s *Store) GetTeam(_ context.Context, teamName string) (domain.Team, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.teamLocked(teamName)
+}
+
+func (s *Store) UpdateTeamSettings(_ context.Context, teamName string, update service.TeamSettingsUpdate) (domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.Team{}, domain.NewNotFoundError("team not found", nil)
+	}
+	required, minimum := t.requiredReviewers, t.minReviewers
+	if update.RequiredReviewers != nil {
+		required = *update.RequiredReviewers
+	}
+	if update.MinReviewers != nil {
+		minimum = *update.MinReviewers
+	}
+	if minimum > required {
+		return domain.Team{}, domain.NewInvalidTeamSettingsError(nil)
+	}
+	t.requiredReviewers, t.minReviewers = required, minimum
+	if update.MaxOpenReviews != nil {
+		t.maxOpenReviews = *update.MaxOpenReviews
+	}
+
+	return s.teamLocked(teamName)
+}
+
+func (s *Store) SetUserActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return domain.User{}, domain.NewNotFoundError("user not found", nil)
+	}
+	u.isActive = isActive
+	reason := domain.AuditUserDeactivated
+	if isActive {
+		reason = domain.AuditUserActivated
+	}
+	s.recordLocked(ctx, domain.AuditEvent{Reason: reason, UserID: userID})
+
+	return u.toDomain(), nil
+}
+
+// SetUserTags replaces the skill tags of the user.
+func (s *Store) SetUserTags(_ context.Context, userID string, tags []string) (domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return domain.User{}, domain.NewNotFoundError("user not found", nil)
+	}
+	u.tags = sortedCopy(tags)
+	return u.toDomain(), nil
+}
+
+// SetUserMaxOpenReviews overrides the team cap on OPEN reviews for the user;
+// nil restores the team default.
+func (s *Store) SetUserMaxOpenReviews(_ context.Context, userID string, maxOpenReviews *int) (domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return domain.User{}, domain.NewNotFoundError("user not found", nil)
+	}
+	u.maxOpenReviews = copyInt(maxOpenReviews)
+	return u.toDomain(), nil
+}
+
+func (s *Store) CreatePullRequest(ctx context.Context, input service.CreatePullRequestInput, pick func([]service.Candidate, []string, int) []string) (domain.PullRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	author, ok := s.users[input.AuthorID]
+	if !ok || author.teamName == "" {
+		return domain.PullRequest{}, domain.NewNotFoundError("author not found", nil)
+	}
+	if input.Repository != "" {
+		r, ok := s.repositories[input.Repository]
+		if !ok {
+			return domain.PullRequest{}, domain.NewNotFoundError("repository not found", nil)
+		}
+		if len(r.teams) > 0 && !slices.Contains(r.teams, author.teamName) {
+			return domain.PullRequest{}, domain.NewTeamNotLinkedError()
+		}
+	}
+	if _, exists := s.pullRequests[prKey{input.Repository, input.PullRequestID}]; exists {
+		return domain.PullRequest{}, domain.NewPRExistsError(nil)
+	}
+
+	pr := &pullRequest{
+		id:         input.PullRequestID,
+		name:       input.PullRequestName,
+		authorID:   input.AuthorID,
+		status:     domain.StatusDraft,
+		repository: input.Repository,
+		files:      append([]string(nil), input.ChangedFiles...),
+		tags:       sortedCopy(input.RequiredTags),
+		createdAt:  s.now(),
+	}
+	var skipped []string
+	if !input.Draft {
+		reviewers, skippedAtCap, err := s.pickReviewersLocked(pr, pick)
+		if err != nil {
+			return domain.PullRequest{}, err
+		}
+		pr.status = domain.StatusOpen
+		pr.reviewers = reviewers
+		skipped = skippedAtCap
+	}
+
+	s.pullRequests[pr.key()] = pr
+	s.recordLocked(ctx, assignedEvents(pr.key(), pr.reviewers)...)
+
+	result := pr.toDomain()
+	result.SkippedAtCap = skipped
+	return result, nil
+}
+
+// ChangePullRequestStatus applies the transition. Marking a draft ready assigns
+// reviewers the same way CreatePullRequest does. Repeating a transition to the
+// current status returns the PR with changed set to false.
+func (s *Store) ChangePullRequestStatus(ctx context.Context, repository, prID string, transition service.Transition, pick func([]service.Candidate, []string, int) []string) (domain.PullRequest, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.pullRequests[prKey{repository, prID}]
+	if !ok {
+		return domain.PullRequest{}, false, domain.NewNotFoundError("pull request not found", nil)
+	}
+	if !transition.Allows(pr.status) {
+		return domain.PullRequest{}, false, domain.NewInvalidTransitionError(pr.status, transition.To)
+	}
+	if pr.status == transition.To {
+		return pr.toDomain(), false, nil
+	}
+
+	events := []domain.AuditEvent{{Reason: transition.Reason, Repository: repository, PullRequestID: prID}}
+	var skipped []string
+	if pr.status == domain.StatusDraft && transition.To == domain.StatusOpen {
+		reviewers, skippedAtCap, err := s.pickReviewersLocked(pr, pick)
+		if err != nil {
+			return domain.PullRequest{}, false, err
+		}
+		pr.reviewers = reviewers
+		skipped = skippedAtCap
+		events = append(events, assignedEvents(pr.key(), reviewers)...)
+	}
+	s.recordLocked(ctx, events...)
+
+	pr.status = transition.To
+	pr.closedAt = nil
+	now := s.now()
+	switch transition.To {
+	case domain.StatusMerged:
+		pr.mergedAt = &now
+	case domain.StatusClosed:
+		pr.closedAt = &now
+	}
+
+	result := pr.toDomain()
+	result.SkippedAtCap = skipped
+	return result, true, nil
+}
+
+func (s *Store) ReassignReviewer(ctx context.Context, repository, prID, oldUserID string, pick func([]service.Candidate) (string, bool)) (domain.PullRequest, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.pullRequests[prKey{repository, prID}]
+	if !ok {
+		return domain.PullRequest{}, "", domain.NewNotFoundError("pull request not found", nil)
+	}
+	switch pr.status {
+	case domain.StatusMerged:
+		return domain.PullRequest{}, "", domain.NewPRMergedError()
+	case domain.StatusClosed:
+		return domain.PullRequest{}, "", domain.NewPRClosedError()
+	}
+
+	reviewer, ok := s.users[oldUserID]
+	if !ok {
+		return domain.PullRequest{}, "", domain.NewNotFoundError("reviewer not found", nil)
+	}
+	if !containsID(pr.reviewers, oldUserID) {
+		return domain.PullRequest{}, "", domain.NewNotAssignedError()
+	}
+
+	exclude := append([]string{oldUserID, pr.authorID}, pr.reviewers...)
+	available, skipped := service.WithinCapacity(s.activeTeamMembersLocked(reviewer.teamName, exclude))
+	chosen, ok := pick(available)
+	if !ok {
+		if len(skipped) > 0 {
+			return domain.PullRequest{}, "", domain.NewCapReachedError()
+		}
+		return domain.PullRequest{}, "", domain.NewNoCandidateError()
+	}
+
+	pr.reviewers = append(removeID(pr.reviewers, oldUserID), chosen)
+	s.reassignments = append(s.reassignments, reassignment{pr: pr.key(), oldID: oldUserID, newID: chosen})
+	s.recordLocked(ctx, domain.AuditEvent{Reason: domain.AuditReassigned, Repository: repository, PullRequestID: prID, OldReviewerID: oldUserID, NewReviewerID: chosen})
+
+	return pr.toDomain(), chosen, nil
+}
+
+func (s *Store) DeactivateUsers(ctx context.Context, input service.DeactivateUsersInput, pick func([]service.Candidate) (string, bool)) (domain.DeactivationResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if input.TeamName != "" {
+		if _, ok := s.teams[input.TeamName]; !ok {
+			return domain.DeactivationResult{}, domain.NewNotFoundError("team not found", nil)
+		}
+	}
+	for _, id := range input.UserIDs {
+		if _, ok := s.users[id]; !ok {
+			return domain.DeactivationResult{}, domain.NewNotFoundError("user not found", nil)
+		}
+	}
+
+	selected := make(map[string]struct{})
+	for _, u := range s.users {
+		if input.TeamName != "" && u.teamName == input.TeamName {
+			selected[u.id] = struct{}{}
+		}
+	}
+	for _, id := range input.UserIDs {
+		selected[id] = struct{}{}
+	}
+
+	result := domain.DeactivationResult{Deactivated: make([]string, 0, len(selected)), Reassignments: []domain.ReviewerReplacement{}}
+	for id := range selected {
+		s.users[id].isActive = false
+		result.Deactivated = append(result.Deactivated, id)
+	}
+	sort.Strings(result.Deactivated)
+	for _, id := range result.Deactivated {
+		s.recordLocked(ctx, domain.AuditEvent{Reason: domain.AuditUserDeactivated, UserID: id})
+	}
+
+	for _, pr := range s.pullRequestsByKeyLocked() {
+		if pr.status != domain.StatusOpen {
+			continue
+		}
+		for _, reviewerID := range sortedCopy(pr.reviewers) {
+			if _, ok := selected[reviewerID]; !ok {
+				continue
+			}
+
+			result.Reassignments = append(result.Reassignments, s.replaceReviewerLocked(pr, reviewerID, s.users[reviewerID].teamName, pick))
+		}
+	}
+	s.recordLocked(ctx, reviewerEvents(domain.AuditReviewerDeactivated, result.Reassignments)...)
+
+	return result, nil
+}
+
+func (s *Store) GetUserReviews(_ context.Context, userID string, filter service.UserReviewsFilter) (domain.UserReviews, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := domain.UserReviews{UserID: userID}
+	var prs []domain.PullRequestShort
+	for _, pr := range s.sortedPullRequestsLocked() {
+		if !containsID(pr.reviewers, userID) || !matchesReviewFilter(pr, filter) {
+			continue
+		}
+		if len(prs) == filter.Limit {
+			last := prs[len(prs)-1]
+			createdAt := s.pullRequests[prKey{last.Repository, last.PullRequestID}].createdAt
+			result.NextCursor = service.Cursor{CreatedAt: createdAt, Repository: last.Repository, ID: last.PullRequestID}.Encode()
+			break
+		}
+		prs = append(prs, domain.PullRequestShort{
+			PullRequestID:   pr.id,
+			Repository:      pr.repository,
+			PullRequestName: pr.name,
+			AuthorID:        pr.authorID,
+			Status:          pr.status,
+		})
+	}
+
+	result.PullRequests = prs
+	return result, nil
+}
+
+func matchesReviewFilter(pr *pullRequest, filter service.UserReviewsFilter) bool {
+	if len(filter.Statuses) == 0 {
+		if pr.status == domain.StatusClosed {
+			return false
+		}
+	} else if !containsID(filter.Statuses, pr.status) {
+		return false
+	}
+	if !inRange(&pr.createdAt, filter.CreatedFrom, filter.CreatedTo) {
+		return false
+	}
+	return filter.After == nil || filter.After.Follows(pr.createdAt, pr.repository, pr.id, false)
+}
+
+func (s *Store) GetPullRequest(_ context.Context, repository, prID string) (domain.PullRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pr, ok := s.pullRequests[prKey{repository, prID}]
+	if !ok {
+		return domain.PullRequest{}, domain.NewNotFoundError("pull request not found", nil)
+	}
+	return pr.toDomain(), nil
+}
+
+// Helper functions
+
+func (s *Store) teamLocked(teamName string) (domain.Team, error) {
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.Team{}, domain.NewNotFoundError("team not found", nil)
+	}
+
+	var members []domain.TeamMember
+	for _, u := range s.users {
+		if u.teamName == teamName {
+			members = append(members, domain.TeamMember{
+				UserID:         u.id,
+				Username:       u.username,
+				IsActive:       u.isActive,
+				Tags:           sortedCopy(u.tags),
+				MaxOpenReviews: copyInt(u.maxOpenReviews),
+				OpenReviews:    s.openReviewsLocked(u.id),
+			})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Username != members[j].Username {
+			return members[i].Username < members[j].Username
+		}
+		return members[i].UserID < members[j].UserID
+	})
+
+	return domain.Team{
+		TeamName:          t.name,
+		RequiredReviewers: t.requiredReviewers,
+		MinReviewers:      t.minReviewers,
+		MaxOpenReviews:    t.maxOpenReviews,
+		Members:           members,
+	}, nil
+}
+
+// pickReviewersLocked picks a reviewer for every ownership rule matching the
+// files of pr, then tops it up with teammates below their open review cap,
+// passing the required tags of pr on to pick. It fails if fewer than the team minimum are available, and otherwise also returns the
+// teammates skipped at their cap when pr got fewer than the required reviewers.
+func (s *Store) pickReviewersLocked(pr *pullRequest, pick func([]service.Candidate, []string, int) []string) ([]string, []string, error) {
+	author, ok := s.users[pr.authorID]
+	if !ok || author.teamName == "" {
+		return nil, nil, domain.NewNotFoundError("author not found", nil)
+	}
+	t := s.teams[author.teamName]
+
+	teammates, skipped := service.WithinCapacity(s.activeTeamMembersLocked(author.teamName, []string{pr.authorID}))
+	reviewers, err := service.PickWithOwners(s.ownerCandidatesLocked(pr), teammates, pr.tags, t.requiredReviewers, pick)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(reviewers) < t.minReviewers {
+		if len(skipped) > 0 {
+			return nil, nil, domain.NewCapReachedError()
+		}
+		return nil, nil, domain.NewNotEnoughReviewersError()
+	}
+	if len(reviewers) >= t.requiredReviewers {
+		skipped = nil
+	}
+	return append([]string(nil), reviewers...), skipped, nil
+}
+
+// replaceReviewerLocked removes reviewerID from pr and, where pick finds one,
+// assigns an active member of teamName below their open review cap instead.
+func (s *Store) replaceReviewerLocked(pr *pullRequest, reviewerID, teamName string, pick func([]service.Candidate) (string, bool)) domain.ReviewerReplacement {
+	replacement := domain.ReviewerReplacement{Repository: pr.repository, PullRequestID: pr.id, OldReviewerID: reviewerID}
+	exclude := append([]string{reviewerID, pr.authorID}, pr.reviewers...)
+	pr.reviewers = removeID(pr.reviewers, reviewerID)
+	available, _ := service.WithinCapacity(s.activeTeamMembersLocked(teamName, exclude))
+	if chosen, ok := pick(available); ok {
+		replacement.NewReviewerID = chosen
+		pr.reviewers = append(pr.reviewers, chosen)
+		s.reassignments = append(s.reassignments, reassignment{pr: pr.key(), oldID: reviewerID, newID: chosen})
+	}
+	return replacement
+}
+
+// activeTeamMembersLocked returns available team members with their OPEN
+// review load.
+func (s *Store) activeTeamMembersLocked(teamName string, excludes []string) []service.Candidate {
+	if teamName == "" {
+		return nil
+	}
+	var candidates []service.Candidate
+	for _, u := range s.users {
+		if u.teamName != teamName || !s.availableLocked(u) || containsID(excludes, u.id) {
+			continue
+		}
+		candidates = append(candidates, s.candidateLocked(u))
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].UserID < candidates[j].UserID })
+	return candidates
+}
+
+func (s *Store) candidateLocked(u *user) service.Candidate {
+	return service.Candidate{UserID: u.id, OpenReviews: s.openReviewsLocked(u.id), MaxOpenReviews: s.maxOpenReviewsLocked(u), Tags: sortedCopy(u.tags)}
+}
+
+// maxOpenReviewsLocked returns the cap of the user, falling back to the cap
+// of their team.
+func (s *Store) maxOpenReviewsLocked(u *user) int {
+	if u.maxOpenReviews != nil {
+		return *u.maxOpenReviews
+	}
+	if t, ok := s.teams[u.teamName]; ok {
+		return t.maxOpenReviews
+	}
+	return 0
+}
+
+func (s *Store) openReviewsLocked(userID string) int {
+	count := 0
+	for _, pr := range s.pullRequests {
+		if pr.status == domain.StatusOpen && containsID(pr.reviewers, userID) {
+			count++
+		}
+	}
+	return count
+}
+
+// sortedPullRequestsLocked returns pull requests from newest to oldest, with
+// ties broken by repository and id the same way the Postgres store orders them.
+func (s *Store) sortedPullRequestsLocked() []*pullRequest {
+	prs := make([]*pullRequest, 0, len(s.pullRequests))
+	for _, pr := range s.pullRequests {
+		prs = append(prs, pr)
+	}
+	sort.Slice(prs, func(i, j int) bool {
+		if !prs[i].createdAt.Equal(prs[j].createdAt) {
+			return prs[i].createdAt.After(prs[j].createdAt)
+		}
+		return prs[j].key().less(prs[i].key())
+	})
+	return prs
+}
+
+// pullRequestsByKeyLocked returns pull requests ordered by repository and id.
+func (s *Store) pullRequestsByKeyLocked() []*pullRequest {
+	prs := make([]*pullRequest, 0, len(s.pullRequests))
+	for _, pr := range s.pullRequests {
+		prs = append(prs, pr)
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].key().less(prs[j].key()) })
+	return prs
+}
+
+func (pr *pullRequest) key() prKey {
+	return prKey{pr.repository, pr.id}
+}
+
+func (k prKey) less(other prKey) bool {
+	if k.repository != other.repository {
+		return k.repository < other.repository
+	}
+	return k.id < other.id
+}
+
+func (u *user) toDomain() domain.User {
+	return domain.User{UserID: u.id, Username: u.username, TeamName: u.teamName, IsActive: u.isActive, Tags: sortedCopy(u.tags), MaxOpenReviews: copyInt(u.maxOpenReviews)}
+}
+
+func (pr *pullRequest) toDomain() domain.PullRequest {
+	result := domain.PullRequest{
+		PullRequestID:   pr.id,
+		Repository:      pr.repository,
+		PullRequestName: pr.name,
+		AuthorID:        pr.authorID,
+		Status:          pr.status,
+		Assigned:        sortedCopy(pr.reviewers),
+		RequiredTags:    sortedCopy(pr.tags),
+		CreatedAt:       pr.createdAt,
+	}
+	if pr.mergedAt != nil {
+		mergedAt := *pr.mergedAt
+		result.MergedAt = &mergedAt
+	}
+	if pr.closedAt != nil {
+		closedAt := *pr.closedAt
+		result.ClosedAt = &closedAt
+	}
+	return result
+}
+
+func containsID(ids []string, id string) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeID(ids []string, id string) []string {
+	result := make([]string, 0, len(ids))
+	for _, item := range ids {
+		if item != id {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func sortedCopy(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := append([]string(nil), ids...)
+	sort.Strings(result)
+	return result
+}
+
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
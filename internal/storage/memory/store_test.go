@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/storagetest"
+)
+
+var _ service.Repository = (*Store)(nil)
+
+func TestStoreConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) service.Repository {
+		return New()
+	})
+}
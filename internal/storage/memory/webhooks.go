@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func (s *Store) CreateWebhook(_ context.Context, hook domain.Webhook) (domain.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastHookID++
+	hook.ID = s.lastHookID
+	hook.CreatedAt = s.now()
+	hook.EventTypes = append([]string(nil), hook.EventTypes...)
+	s.webhooks = append(s.webhooks, hook)
+	return hook, nil
+}
+
+func (s *Store) ListWebhooks(_ context.Context) ([]domain.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hooks := make([]domain.Webhook, 0, len(s.webhooks))
+	for _, hook := range s.webhooks {
+		hook.EventTypes = append([]string(nil), hook.EventTypes...)
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes the webhook together with its deliveries.
+func (s *Store) DeleteWebhook(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.webhookIndexLocked(id)
+	if i < 0 {
+		return domain.NewNotFoundError("webhook not found", nil)
+	}
+	s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+
+	kept := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.WebhookID != id {
+			kept = append(kept, d)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// ListWebhookDeliveries returns deliveries of the webhook, newest first.
+func (s *Store) ListWebhookDeliveries(_ context.Context, filter service.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.webhookIndexLocked(filter.WebhookID) < 0 {
+		return nil, domain.NewNotFoundError("webhook not found", nil)
+	}
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < filter.Limit; i-- {
+		d := s.deliveries[i]
+		if d.WebhookID == filter.WebhookID && (filter.Status == "" || d.Status == filter.Status) {
+			deliveries = append(deliveries, s.deliveryLocked(d))
+		}
+	}
+	return deliveries, nil
+}
+
+// ClaimWebhookDeliveries leases up to limit pending deliveries that are due at
+// now by moving their next attempt to leaseUntil.
+func (s *Store) ClaimWebhookDeliveries(_ context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]*domain.WebhookDelivery, 0)
+	for _, d := range s.deliveries {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+
+	claimed := make([]domain.WebhookDelivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = leaseUntil
+		claimed = append(claimed, s.deliveryLocked(d))
+	}
+	return claimed, nil
+}
+
+// CompleteWebhookDelivery marks the delivery as delivered at the given time.
+func (s *Store) CompleteWebhookDelivery(_ context.Context, id int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.findDeliveryLocked(id)
+	if d == nil {
+		return domain.NewNotFoundError("webhook delivery not found", nil)
+	}
+	d.Status = domain.DeliveryDelivered
+	d.Attempts++
+	d.DeliveredAt = &at
+	d.LastError = ""
+	return nil
+}
+
+// FailWebhookDelivery records a failed attempt. The delivery is retried at
+// retryAt, or becomes DEAD when retryAt is nil.
+func (s *Store) FailWebhookDelivery(_ context.Context, id int64, retryAt *time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.findDeliveryLocked(id)
+	if d == nil {
+		return domain.NewNotFoundError("webhook delivery not found", nil)
+	}
+	d.Attempts++
+	d.LastError = lastError
+	if retryAt == nil {
+		d.Status = domain.DeliveryDead
+	} else {
+		d.NextAttemptAt = *retryAt
+	}
+	return nil
+}
+
+// enqueueDeliveriesLocked queues the event for every webhook subscribed to its
+// event type.
+func (s *Store) enqueueDeliveriesLocked(e domain.AuditEvent) {
+	eventType := domain.WebhookEventFor(e.Reason)
+	if eventType == "" {
+		return
+	}
+	payload, _ := json.Marshal(domain.WebhookPayload{Event: eventType, AuditEvent: e})
+	for _, hook := range s.webhooks {
+		if !containsID(hook.EventTypes, eventType) {
+			continue
+		}
+		s.lastDeliveryID++
+		s.deliveries = append(s.deliveries, &domain.WebhookDelivery{
+			ID:            s.lastDeliveryID,
+			WebhookID:     hook.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: e.OccurredAt,
+			CreatedAt:     e.OccurredAt,
+		})
+	}
+}
+
+func (s *Store) webhookIndexLocked(id int64) int {
+	for i, hook := range s.webhooks {
+		if hook.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) findDeliveryLocked(id int64) *domain.WebhookDelivery {
+	for _, d := range s.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// deliveryLocked copies the delivery and fills in the target of its webhook.
+func (s *Store) deliveryLocked(d *domain.WebhookDelivery) domain.WebhookDelivery {
+	out := *d
+	hook := s.webhooks[s.webhookIndexLocked(d.WebhookID)]
+	out.URL, out.Secret = hook.URL, hook.Secret
+	return out
+}
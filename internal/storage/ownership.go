@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+)
+
+const selectOwnershipRules = `SELECT rule_id, repository, pattern, COALESCE(team_name, ''), user_ids, created_at
+	FROM ownership_rules
+	WHERE ($1::text = '' OR repository = $1)
+	ORDER BY repository, rule_id`
+
+// CreateOwnershipRule stores the rule; its repository and its team or every
+// one of its users must exist.
+func (s *Store) CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error) {
+	if err := s.ensureRepositoryExists(ctx, rule.Repository); err != nil {
+		return domain.OwnershipRule{}, err
+	}
+	if rule.TeamName != "" {
+		if err := s.ensureTeamExists(ctx, rule.TeamName); err != nil {
+			return domain.OwnershipRule{}, err
+		}
+	}
+	if len(rule.UserIDs) > 0 {
+		var known int
+		if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE user_id = ANY($1)`, rule.UserIDs).Scan(&known); err != nil {
+			return domain.OwnershipRule{}, err
+		}
+		if known != len(rule.UserIDs) {
+			return domain.OwnershipRule{}, domain.NewNotFoundError("user not found", nil)
+		}
+	}
+
+	userIDs := rule.UserIDs
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+	row := s.pool.QueryRow(ctx, `INSERT INTO ownership_rules(repository, pattern, team_name, user_ids)
+		VALUES($1, $2, NULLIF($3, ''), $4)
+		RETURNING rule_id, created_at`,
+		rule.Repository, rule.Pattern, rule.TeamName, userIDs)
+	if err := row.Scan(&rule.ID, &rule.CreatedAt); err != nil {
+		return domain.OwnershipRule{}, err
+	}
+	return rule, nil
+}
+
+// ListOwnershipRules returns rules ordered by repository and creation; an
+// empty repository matches every repository.
+func (s *Store) ListOwnershipRules(ctx context.Context, repository string) ([]domain.OwnershipRule, error) {
+	rows, err := s.pool.Query(ctx, selectOwnershipRules, repository)
+	if err != nil {
+		return nil, err
+	}
+	return scanOwnershipRules(rows)
+}
+
+func (s *Store) DeleteOwnershipRule(ctx context.Context, id int64) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM ownership_rules WHERE rule_id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFoundError("ownership rule not found", nil)
+	}
+	return nil
+}
+
+// ownerCandidatesTx returns, for every rule of the repository matching one of
+// the files, the active users below their open review cap able to satisfy it
+// other than the author.
+func (s *Store) ownerCandidatesTx(ctx context.Context, tx pgx.Tx, repository string, files []string, authorID string) ([]service.OwnerCandidates, error) {
+	if repository == "" || len(files) == 0 {
+		return nil, nil
+	}
+	rows, err := tx.Query(ctx, selectOwnershipRules, repository)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := scanOwnershipRules(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []service.OwnerCandidates
+	for _, rule := range service.MatchingRules(rules, files) {
+		candidates, err := s.listActiveUsersTx(ctx, tx, rule.TeamName, rule.UserIDs, []string{authorID})
+		if err != nil {
+			return nil, err
+		}
+		available, _ := service.WithinCapacity(candidates)
+		owners = append(owners, service.OwnerCandidates{Rule: rule, Candidates: available})
+	}
+	return owners, nil
+}
+
+func listPullRequestFilesTx(ctx context.Context, tx pgx.Tx, repository, prID string) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT path FROM pull_request_files WHERE repository=$1 AND pull_request_id=$2 ORDER BY path`, repository, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []string
+	for rows.Next() {
+		var file string
+		if err := rows.Scan(&file); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+func scanOwnershipRules(rows pgx.Rows) ([]domain.OwnershipRule, error) {
+	defer rows.Close()
+
+	rules := make([]domain.OwnershipRule, 0)
+	for rows.Next() {
+		var rule domain.OwnershipRule
+		if err := rows.Scan(&rule.ID, &rule.Repository, &rule.Pattern, &rule.TeamName, &rule.UserIDs, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(rule.UserIDs) == 0 {
+			rule.UserIDs = nil
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
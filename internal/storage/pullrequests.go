@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// ListPullRequests returns a page of pull requests matching the filter. One
+// extra row is fetched to tell whether another page follows.
+func (s *Store) ListPullRequests(ctx context.Context, filter service.PullRequestFilter) (domain.PullRequestList, error) {
+	if filter.TeamName != "" {
+		if err := s.ensureTeamExists(ctx, filter.TeamName); err != nil {
+			return domain.PullRequestList{}, err
+		}
+	}
+
+	direction, after := "DESC", "<"
+	if filter.Order == service.OrderOldest {
+		direction, after = "ASC", ">"
+	}
+
+	var afterTime *time.Time
+	var afterRepository, afterID string
+	if filter.After != nil {
+		afterTime, afterRepository, afterID = &filter.After.CreatedAt, filter.After.Repository, filter.After.ID
+	}
+
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`SELECT pr.pull_request_id, pr.repository, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at, pr.required_tags
+		FROM pull_requests pr
+		JOIN users a ON a.user_id = pr.author_id
+		WHERE ($1::text = '' OR pr.author_id = $1)
+		  AND ($2::text = '' OR a.team_name = $2)
+		  AND ($3::text = '' OR EXISTS (
+		      SELECT 1 FROM pull_request_reviewers r
+		      WHERE r.repository = pr.repository AND r.pull_request_id = pr.pull_request_id AND r.reviewer_id = $3))
+		  AND (COALESCE(cardinality($4::text[]), 0) = 0 OR pr.status::text = ANY($4))
+		  AND ($5::text = '' OR strpos(lower(pr.pull_request_name), lower($5)) > 0)
+		  AND ($6::timestamptz IS NULL OR pr.created_at >= $6)
+		  AND ($7::timestamptz IS NULL OR pr.created_at < $7)
+		  AND ($8::timestamptz IS NULL OR pr.merged_at >= $8)
+		  AND ($9::timestamptz IS NULL OR pr.merged_at < $9)
+		  AND ($10::timestamptz IS NULL OR (pr.created_at, pr.repository, pr.pull_request_id) %[2]s ($10, $11::text, $12::text))
+		  AND ($13::text = '' OR pr.repository = $13)
+		ORDER BY pr.created_at %[1]s, pr.repository %[1]s, pr.pull_request_id %[1]s
+		LIMIT $14`, direction, after),
+		filter.AuthorID, filter.TeamName, filter.ReviewerID, filter.Statuses, filter.NameContains,
+		filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo,
+		afterTime, afterRepository, afterID, filter.Repository, filter.Limit+1)
+	if err != nil {
+		return domain.PullRequestList{}, err
+	}
+	defer rows.Close()
+
+	result := domain.PullRequestList{PullRequests: make([]domain.PullRequest, 0)}
+	for rows.Next() {
+		if len(result.PullRequests) == filter.Limit {
+			last := result.PullRequests[len(result.PullRequests)-1]
+			result.NextCursor = service.Cursor{CreatedAt: last.CreatedAt, Repository: last.Repository, ID: last.PullRequestID}.Encode()
+			break
+		}
+		pr, err := scanPullRequestRow(rows)
+		if err != nil {
+			return domain.PullRequestList{}, err
+		}
+		result.PullRequests = append(result.PullRequests, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.PullRequestList{}, err
+	}
+	rows.Close()
+
+	if err := s.fillAssignedReviewers(ctx, result.PullRequests); err != nil {
+		return domain.PullRequestList{}, err
+	}
+	return result, nil
+}
+
+// fillAssignedReviewers loads reviewers of all given pull requests with a
+// single query.
+func (s *Store) fillAssignedReviewers(ctx context.Context, prs []domain.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+	repositories := make([]string, 0, len(prs))
+	ids := make([]string, 0, len(prs))
+	index := make(map[prKey]int, len(prs))
+	for i, pr := range prs {
+		repositories = append(repositories, pr.Repository)
+		ids = append(ids, pr.PullRequestID)
+		index[prKey{pr.Repository, pr.PullRequestID}] = i
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT r.repository, r.pull_request_id, r.reviewer_id
+		FROM pull_request_reviewers r
+		JOIN unnest($1::text[], $2::text[]) AS p(repository, pull_request_id)
+		  ON p.repository = r.repository AND p.pull_request_id = r.pull_request_id
+		ORDER BY r.reviewer_id`, repositories, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key prKey
+		var reviewerID string
+		if err := rows.Scan(&key.repository, &key.id, &reviewerID); err != nil {
+			return err
+		}
+		i := index[key]
+		prs[i].Assigned = append(prs[i].Assigned, reviewerID)
+	}
+	return rows.Err()
+}
+
+// prKey identifies a pull request; its id is unique within the repository.
+type prKey struct {
+	repository string
+	id         string
+}
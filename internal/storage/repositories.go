@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+const selectRepositories = `SELECT r.name, r.created_at,
+		COALESCE(array_agg(t.team_name ORDER BY t.team_name) FILTER (WHERE t.team_name IS NOT NULL), '{}')
+	FROM repositories r
+	LEFT JOIN repository_teams t ON t.repository = r.name`
+
+// CreateRepository registers the repository and links it to its teams, which
+// must exist.
+func (s *Store) CreateRepository(ctx context.Context, repo domain.Repository) (domain.Repository, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.Repository{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if _, execErr := tx.Exec(ctx, `INSERT INTO repositories(name) VALUES($1)`, repo.Name); execErr != nil {
+		if isUniqueViolation(execErr) {
+			return domain.Repository{}, domain.NewRepositoryExistsError(execErr)
+		}
+		return domain.Repository{}, execErr
+	}
+	if err := linkRepositoryTeamsTx(ctx, tx, repo.Name, repo.Teams); err != nil {
+		return domain.Repository{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Repository{}, err
+	}
+	return s.GetRepository(ctx, repo.Name)
+}
+
+func (s *Store) GetRepository(ctx context.Context, name string) (domain.Repository, error) {
+	var repo domain.Repository
+	row := s.pool.QueryRow(ctx, selectRepositories+` WHERE r.name=$1 GROUP BY r.name`, name)
+	if err := row.Scan(&repo.Name, &repo.CreatedAt, &repo.Teams); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Repository{}, domain.NewNotFoundError("repository not found", err)
+		}
+		return domain.Repository{}, err
+	}
+	return repo, nil
+}
+
+// ListRepositories returns repositories ordered by name; a non-empty teamName
+// keeps the repositories linked to that team.
+func (s *Store) ListRepositories(ctx context.Context, teamName string) ([]domain.Repository, error) {
+	if teamName != "" {
+		if err := s.ensureTeamExists(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.pool.Query(ctx, selectRepositories+`
+		WHERE $1::text = '' OR EXISTS (
+		    SELECT 1 FROM repository_teams x WHERE x.repository = r.name AND x.team_name = $1)
+		GROUP BY r.name
+		ORDER BY r.name`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	repos := make([]domain.Repository, 0)
+	for rows.Next() {
+		var repo domain.Repository
+		if err := rows.Scan(&repo.Name, &repo.CreatedAt, &repo.Teams); err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	return repos, rows.Err()
+}
+
+// SetRepositoryTeams replaces the teams linked to the repository.
+func (s *Store) SetRepositoryTeams(ctx context.Context, name string, teams []string) (domain.Repository, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.Repository{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if err := lockRepositoryTx(ctx, tx, name); err != nil {
+		return domain.Repository{}, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM repository_teams WHERE repository=$1`, name); err != nil {
+		return domain.Repository{}, err
+	}
+	if err := linkRepositoryTeamsTx(ctx, tx, name, teams); err != nil {
+		return domain.Repository{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Repository{}, err
+	}
+	return s.GetRepository(ctx, name)
+}
+
+// DeleteRepository removes a repository that has no pull requests; its team
+// links and ownership rules go with it. The row lock keeps pull requests from
+// being created in the repository meanwhile.
+func (s *Store) DeleteRepository(ctx context.Context, name string) error {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(ctx, tx)
+
+	if err := lockRepositoryTx(ctx, tx, name); err != nil {
+		return err
+	}
+	var inUse bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pull_requests WHERE repository=$1)`, name).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return domain.NewRepositoryInUseError()
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM repositories WHERE name=$1`, name); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// linkRepositoryTeamsTx links the repository to teams, failing if any of them
+// does not exist.
+func linkRepositoryTeamsTx(ctx context.Context, tx pgx.Tx, name string, teams []string) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	tag, err := tx.Exec(ctx, `INSERT INTO repository_teams(repository, team_name)
+		SELECT $1, t.team_name FROM teams t WHERE t.team_name = ANY($2)`, name, teams)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != int64(len(teams)) {
+		return domain.NewNotFoundError("team not found", nil)
+	}
+	return nil
+}
+
+func lockRepositoryTx(ctx context.Context, tx pgx.Tx, name string) error {
+	var locked string
+	if err := tx.QueryRow(ctx, `SELECT name FROM repositories WHERE name=$1 FOR UPDATE`, name).Scan(&locked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("repository not found", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureRepositoryExistsTx fails unless the repository is registered. The
+// share lock keeps it from being deleted before tx commits.
+func ensureRepositoryExistsTx(ctx context.Context, tx pgx.Tx, name string) error {
+	var found string
+	if err := tx.QueryRow(ctx, `SELECT name FROM repositories WHERE name=$1 FOR KEY SHARE`, name).Scan(&found); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("repository not found", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureTeamLinkedTx fails unless the repository has no linked teams or is
+// linked to teamName. The caller holds a key share lock on the repository, so
+// SetRepositoryTeams cannot change the links meanwhile.
+func ensureTeamLinkedTx(ctx context.Context, tx pgx.Tx, repository, teamName string) error {
+	var linked bool
+	if err := tx.QueryRow(ctx, `SELECT NOT EXISTS (SELECT 1 FROM repository_teams WHERE repository=$1)
+		OR EXISTS (SELECT 1 FROM repository_teams WHERE repository=$1 AND team_name=$2)`, repository, teamName).Scan(&linked); err != nil {
+		return err
+	}
+	if !linked {
+		return domain.NewTeamNotLinkedError()
+	}
+	return nil
+}
+
+func (s *Store) ensureRepositoryExists(ctx context.Context, name string) error {
+	var found string
+	if err := s.pool.QueryRow(ctx, `SELECT name FROM repositories WHERE name=$1`, name).Scan(&found); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("repository not found", err)
+		}
+		return err
+	}
+	return nil
+}
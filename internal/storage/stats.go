@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetReviewerStats returns review load per user, optionally limited to a team.
+func (s *Store) GetReviewerStats(ctx context.Context, teamName string) ([]domain.ReviewerStats, error) {
+	if teamName != "" {
+		if err := s.ensureTeamExists(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT u.user_id, u.username, COALESCE(u.team_name, '') AS team, u.is_active,
+		       COUNT(r.pull_request_id),
+		       COUNT(r.pull_request_id) FILTER (WHERE pr.status = 'OPEN'),
+		       COUNT(r.pull_request_id) FILTER (WHERE pr.status = 'MERGED'),
+		       COUNT(r.pull_request_id) FILTER (WHERE pr.status = 'CLOSED'),
+		       (SELECT COUNT(*) FROM reviewer_reassignments ra WHERE ra.old_reviewer_id = u.user_id),
+		       (SELECT COUNT(*) FROM reviewer_reassignments ra WHERE ra.new_reviewer_id = u.user_id)
+		FROM users u
+		LEFT JOIN pull_request_reviewers r ON r.reviewer_id = u.user_id
+		LEFT JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id
+		WHERE ($1::text = '' OR u.team_name = $1)
+		GROUP BY u.user_id, u.username, u.team_name, u.is_active
+		ORDER BY team, u.username`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]domain.ReviewerStats, 0)
+	for rows.Next() {
+		var item domain.ReviewerStats
+		if err := rows.Scan(
+			&item.UserID,
+			&item.Username,
+			&item.TeamName,
+			&item.IsActive,
+			&item.AssignedTotal,
+			&item.OpenReviews,
+			&item.MergedReviews,
+			&item.ClosedReviews,
+			&item.ReassignedFrom,
+			&item.ReassignedTo,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, item)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetTeamStats returns aggregates per team, optionally limited to a single team.
+// Pull requests are attributed to the team of their author, assignments to the
+// team of the reviewer.
+func (s *Store) GetTeamStats(ctx context.Context, teamName string) ([]domain.TeamStats, error) {
+	if teamName != "" {
+		if err := s.ensureTeamExists(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT t.team_name,
+		       m.total, m.active,
+		       p.total, p.draft, p.open, p.merged, p.closed,
+		       a.total, a.open,
+		       (SELECT COUNT(*) FROM reviewer_reassignments ra
+		        JOIN users u ON u.user_id = ra.old_reviewer_id
+		        WHERE u.team_name = t.team_name)
+		FROM teams t
+		CROSS JOIN LATERAL (
+		    SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE u.is_active) AS active
+		    FROM users u WHERE u.team_name = t.team_name
+		) m
+		CROSS JOIN LATERAL (
+		    SELECT COUNT(*) AS total,
+		           COUNT(*) FILTER (WHERE pr.status = 'DRAFT') AS draft,
+		           COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open,
+		           COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged,
+		           COUNT(*) FILTER (WHERE pr.status = 'CLOSED') AS closed
+		    FROM pull_requests pr
+		    JOIN users u ON u.user_id = pr.author_id
+		    WHERE u.team_name = t.team_name
+		) p
+		CROSS JOIN LATERAL (
+		    SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open
+		    FROM pull_request_reviewers r
+		    JOIN users u ON u.user_id = r.reviewer_id
+		    JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id
+		    WHERE u.team_name = t.team_name
+		) a
+		WHERE ($1::text = '' OR t.team_name = $1)
+		ORDER BY t.team_name`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]domain.TeamStats, 0)
+	for rows.Next() {
+		var item domain.TeamStats
+		if err := rows.Scan(
+			&item.TeamName,
+			&item.MembersTotal,
+			&item.ActiveMembers,
+			&item.PullRequestsTotal,
+			&item.DraftPullRequests,
+			&item.OpenPullRequests,
+			&item.MergedPullRequests,
+			&item.ClosedPullRequests,
+			&item.AssignmentsTotal,
+			&item.OpenAssignments,
+			&item.Reassignments,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, item)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetPullRequestStats returns assignment counters for a single pull request.
+func (s *Store) GetPullRequestStats(ctx context.Context, repository, prID string) (domain.PullRequestStats, error) {
+	var item domain.PullRequestStats
+	row := s.pool.QueryRow(ctx, `SELECT pr.pull_request_id, pr.repository, pr.pull_request_name, pr.author_id, pr.status,
+		       (SELECT COUNT(*) FROM pull_request_reviewers r
+		        WHERE r.repository = pr.repository AND r.pull_request_id = pr.pull_request_id),
+		       (SELECT COUNT(*) FROM reviewer_reassignments ra
+		        WHERE ra.repository = pr.repository AND ra.pull_request_id = pr.pull_request_id)
+		FROM pull_requests pr
+		WHERE pr.repository = $1 AND pr.pull_request_id = $2`, repository, prID)
+	if err := row.Scan(&item.PullRequestID, &item.Repository, &item.PullRequestName, &item.AuthorID, &item.Status, &item.ReviewersCount, &item.Reassignments); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PullRequestStats{}, domain.NewNotFoundError("pull request not found", err)
+		}
+		return domain.PullRequestStats{}, err
+	}
+	return item, nil
+}
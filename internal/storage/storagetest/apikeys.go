@@ -0,0 +1,72 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func apiKeyCases() []testCase {
+	return []testCase{
+		{"CreateAndLookup", func(t *testing.T, repo service.Repository) {
+			admin := mustCreateAPIKey(t, repo, "ops", domain.RoleAdmin, "hash-1")
+			user := mustCreateAPIKey(t, repo, "ci", domain.RoleUser, "hash-2")
+			if admin.ID == 0 || user.ID <= admin.ID || admin.CreatedAt.IsZero() || admin.RevokedAt != nil {
+				t.Fatalf("unexpected keys: %+v, %+v", admin, user)
+			}
+
+			got, err := repo.GetAPIKeyByHash(context.Background(), "hash-2")
+			if err != nil {
+				t.Fatalf("GetAPIKeyByHash: %v", err)
+			}
+			if got.ID != user.ID || got.Name != "ci" || got.Role != domain.RoleUser {
+				t.Fatalf("unexpected key: %+v", got)
+			}
+			_, err = repo.GetAPIKeyByHash(context.Background(), "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			keys, err := repo.ListAPIKeys(context.Background())
+			if err != nil {
+				t.Fatalf("ListAPIKeys: %v", err)
+			}
+			if len(keys) != 2 || keys[0].ID != admin.ID || keys[1].ID != user.ID {
+				t.Fatalf("unexpected keys: %+v", keys)
+			}
+		}},
+		{"Revoke", func(t *testing.T, repo service.Repository) {
+			key := mustCreateAPIKey(t, repo, "ci", domain.RoleUser, "hash-1")
+			first := time.Now().UTC().Truncate(time.Second)
+
+			revoked, err := repo.RevokeAPIKey(context.Background(), key.ID, first)
+			if err != nil {
+				t.Fatalf("RevokeAPIKey: %v", err)
+			}
+			if revoked.RevokedAt == nil || !revoked.RevokedAt.Equal(first) {
+				t.Fatalf("unexpected revoked key: %+v", revoked)
+			}
+			again, err := repo.RevokeAPIKey(context.Background(), key.ID, first.Add(time.Hour))
+			if err != nil || again.RevokedAt == nil || !again.RevokedAt.Equal(first) {
+				t.Fatalf("second revoke changed the key: %+v, %v", again, err)
+			}
+
+			got, err := repo.GetAPIKeyByHash(context.Background(), "hash-1")
+			if err != nil || got.RevokedAt == nil {
+				t.Fatalf("revoked key lookup = %+v, %v", got, err)
+			}
+			_, err = repo.RevokeAPIKey(context.Background(), 999, first)
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
+
+func mustCreateAPIKey(t *testing.T, repo service.Repository, name, role, tokenHash string) domain.APIKey {
+	t.Helper()
+	key, err := repo.CreateAPIKey(context.Background(), domain.APIKey{Name: name, Role: role}, tokenHash)
+	if err != nil {
+		t.Fatalf("CreateAPIKey(%s): %v", name, err)
+	}
+	return key
+}
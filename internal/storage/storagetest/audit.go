@@ -0,0 +1,146 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func auditCases() []testCase {
+	return []testCase{
+		{"CreateRecordsAssignmentsWithActor", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			ctx := service.WithActor(context.Background(), "alice")
+			if _, err := repo.CreatePullRequest(ctx, prInput("pr-1", "author"), pickFirst); err != nil {
+				t.Fatalf("CreatePullRequest: %v", err)
+			}
+
+			events := mustPRHistory(t, repo, "pr-1")
+			expectEvents(t, events,
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u2"},
+			)
+			for i, e := range events {
+				if e.Actor != "alice" || e.OccurredAt.IsZero() || (i > 0 && e.ID <= events[i-1].ID) {
+					t.Fatalf("unexpected event metadata: %+v", events)
+				}
+			}
+		}},
+		{"ReassignRecordsSwap", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustReassign(t, repo, "pr-1", "u1")
+
+			expectEvents(t, mustPRHistory(t, repo, "pr-1"),
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u2"},
+				domain.AuditEvent{Reason: domain.AuditReassigned, PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			)
+			expectEvents(t, mustUserHistory(t, repo, "u1"),
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditReassigned, PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			)
+		}},
+		{"StatusChanges", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateDraft(t, repo, "pr-1", "author")
+			mustChangeStatus(t, repo, "pr-1", service.TransitionReady)
+			mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+			mustChangeStatus(t, repo, "pr-1", service.TransitionReopen)
+			mustMerge(t, repo, "pr-1")
+			mustMerge(t, repo, "pr-1")
+			_, _, err := repo.ChangePullRequestStatus(context.Background(), "", "pr-1", service.TransitionClose, pickFirst)
+			expectCode(t, err, domain.ErrCodeInvalidTransition)
+
+			expectEvents(t, mustPRHistory(t, repo, "pr-1"),
+				domain.AuditEvent{Reason: domain.AuditReadyForReview, PullRequestID: "pr-1"},
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u2"},
+				domain.AuditEvent{Reason: domain.AuditClosed, PullRequestID: "pr-1"},
+				domain.AuditEvent{Reason: domain.AuditReopened, PullRequestID: "pr-1"},
+				domain.AuditEvent{Reason: domain.AuditMerged, PullRequestID: "pr-1"},
+			)
+		}},
+		{"ActivityToggles", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustSetActive(t, repo, "u4", true)
+			if _, err := repo.DeactivateUsers(context.Background(), service.DeactivateUsersInput{UserIDs: []string{"u1"}}, pickOne); err != nil {
+				t.Fatalf("DeactivateUsers: %v", err)
+			}
+
+			expectEvents(t, mustUserHistory(t, repo, "u4"),
+				domain.AuditEvent{Reason: domain.AuditUserActivated, UserID: "u4"},
+			)
+			expectEvents(t, mustUserHistory(t, repo, "u1"),
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditUserDeactivated, UserID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditReviewerDeactivated, PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			)
+		}},
+		{"MembershipReassignment", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			if _, err := repo.MoveTeamMember(context.Background(), service.MoveTeamMemberInput{UserID: "u1", TeamName: "frontend", ReassignReviews: true}, pickOne); err != nil {
+				t.Fatalf("MoveTeamMember: %v", err)
+			}
+
+			expectEvents(t, mustUserHistory(t, repo, "u3"),
+				domain.AuditEvent{Reason: domain.AuditTeamChanged, PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			)
+		}},
+		{"FailedMutationLeavesNoTrace", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustSetActive(t, repo, "u3", false)
+			_, _, err := repo.ReassignReviewer(context.Background(), "", "pr-1", "u1", pickOne)
+			expectCode(t, err, domain.ErrCodeNoCandidate)
+
+			expectEvents(t, mustPRHistory(t, repo, "pr-1"),
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u2"},
+			)
+		}},
+		{"MissingTargets", func(t *testing.T, repo service.Repository) {
+			_, err := repo.GetPullRequestHistory(context.Background(), "", "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.GetUserHistory(context.Background(), "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
+
+func mustPRHistory(t *testing.T, repo service.Repository, prID string) []domain.AuditEvent {
+	t.Helper()
+	events, err := repo.GetPullRequestHistory(context.Background(), "", prID)
+	if err != nil {
+		t.Fatalf("GetPullRequestHistory(%s): %v", prID, err)
+	}
+	return events
+}
+
+func mustUserHistory(t *testing.T, repo service.Repository, userID string) []domain.AuditEvent {
+	t.Helper()
+	events, err := repo.GetUserHistory(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("GetUserHistory(%s): %v", userID, err)
+	}
+	return events
+}
+
+// expectEvents compares events ignoring id, time and actor.
+func expectEvents(t *testing.T, got []domain.AuditEvent, want ...domain.AuditEvent) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got events %+v, want %+v", got, want)
+	}
+	for i := range want {
+		g := got[i]
+		g.ID, g.OccurredAt, g.Actor = 0, want[i].OccurredAt, ""
+		if g != want[i] {
+			t.Fatalf("event %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package storagetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func availabilityCases() []testCase {
+	return []testCase{
+		{"CreateListDelete", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			start := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+			later := mustCreatePeriod(t, repo, "u1", start.AddDate(0, 1, 0), start.AddDate(0, 1, 7))
+			first := mustCreatePeriod(t, repo, "u1", start, start.AddDate(0, 0, 7))
+			mustCreatePeriod(t, repo, "f1", start, start.AddDate(0, 0, 1))
+			if first.ID == 0 || first.CreatedAt.IsZero() || first.Reason != "vacation" {
+				t.Fatalf("unexpected period: %+v", first)
+			}
+
+			_, err := repo.CreateUnavailability(context.Background(), domain.Unavailability{UserID: "ghost", StartsAt: start, EndsAt: start.Add(time.Hour)})
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.ListUnavailability(context.Background(), "ghost")
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			periods, err := repo.ListUnavailability(context.Background(), "u1")
+			if err != nil {
+				t.Fatalf("ListUnavailability(u1): %v", err)
+			}
+			if got := periodIDs(periods); !reflect.DeepEqual(got, []int64{first.ID, later.ID}) {
+				t.Fatalf("periods must be ordered by start: got %v", got)
+			}
+			if !periods[0].StartsAt.Equal(start) || !periods[0].EndsAt.Equal(start.AddDate(0, 0, 7)) {
+				t.Fatalf("unexpected stored period: %+v", periods[0])
+			}
+			all, err := repo.ListUnavailability(context.Background(), "")
+			if err != nil {
+				t.Fatalf("ListUnavailability: %v", err)
+			}
+			if len(all) != 3 {
+				t.Fatalf("got %d periods, want 3", len(all))
+			}
+
+			expectCode(t, repo.DeleteUnavailability(context.Background(), first.ID), "")
+			expectCode(t, repo.DeleteUnavailability(context.Background(), first.ID), domain.ErrCodeNotFound)
+			periods, err = repo.ListUnavailability(context.Background(), "u1")
+			if err != nil {
+				t.Fatalf("ListUnavailability(u1): %v", err)
+			}
+			if got := periodIDs(periods); !reflect.DeepEqual(got, []int64{later.ID}) {
+				t.Fatalf("deleted period still listed: %v", got)
+			}
+		}},
+		{"ExcludedFromCandidates", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			now := time.Now()
+			mustCreatePeriod(t, repo, "u1", now.Add(-time.Hour), now.Add(time.Hour))
+			mustCreatePeriod(t, repo, "u2", now.Add(time.Hour), now.Add(2*time.Hour))
+
+			pr := mustCreatePR(t, repo, "pr-1", "author")
+			expectIDs(t, "reviewers", pr.Assigned, []string{"u2", "u3"})
+		}},
+		{"ReassignsOnce", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "f1")
+			mustMerge(t, repo, "pr-2")
+			now := time.Now()
+			mustCreatePeriod(t, repo, "u1", now.Add(-time.Hour), now.Add(time.Hour))
+			mustCreatePeriod(t, repo, "f2", now.Add(time.Hour), now.Add(2*time.Hour))
+
+			replaced, err := repo.ReassignUnavailableReviewers(context.Background(), now, pickOne)
+			if err != nil {
+				t.Fatalf("ReassignUnavailableReviewers: %v", err)
+			}
+			want := []domain.ReviewerReplacement{{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"}}
+			if !reflect.DeepEqual(replaced, want) {
+				t.Fatalf("got replacements %+v, want %+v", replaced, want)
+			}
+			replaced, err = repo.ReassignUnavailableReviewers(context.Background(), now, pickOne)
+			if err != nil {
+				t.Fatalf("ReassignUnavailableReviewers(again): %v", err)
+			}
+			if len(replaced) != 0 {
+				t.Fatalf("a period must be handled once: %+v", replaced)
+			}
+
+			expectEvents(t, mustUserHistory(t, repo, "u1"),
+				domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditReviewerUnavailable, PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			)
+		}},
+	}
+}
+
+func mustCreatePeriod(t *testing.T, repo service.Repository, userID string, from, to time.Time) domain.Unavailability {
+	t.Helper()
+	period, err := repo.CreateUnavailability(context.Background(), domain.Unavailability{UserID: userID, StartsAt: from, EndsAt: to, Reason: "vacation"})
+	if err != nil {
+		t.Fatalf("CreateUnavailability(%s): %v", userID, err)
+	}
+	return period
+}
+
+func periodIDs(periods []domain.Unavailability) []int64 {
+	ids := make([]int64, 0, len(periods))
+	for _, period := range periods {
+		ids = append(ids, period.ID)
+	}
+	return ids
+}
@@ -0,0 +1,104 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func capacityCases() []testCase {
+	return []testCase{
+		{"UserCap", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			user := mustSetCap(t, repo, "u1", intPtr(1))
+			if user.MaxOpenReviews == nil || *user.MaxOpenReviews != 1 {
+				t.Fatalf("cap not returned: %+v", user)
+			}
+			_, err := repo.SetUserMaxOpenReviews(context.Background(), "ghost", intPtr(1))
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			mustCreatePR(t, repo, "pr-1", "author")
+			pr := mustCreatePR(t, repo, "pr-2", "author")
+			expectIDs(t, "reviewers past the cap", pr.Assigned, []string{"u2", "u3"})
+
+			team, err := repo.GetTeam(context.Background(), "backend")
+			if err != nil {
+				t.Fatalf("GetTeam: %v", err)
+			}
+			for _, member := range team.Members {
+				want := map[string]int{"u1": 1, "u2": 2, "u3": 1}[member.UserID]
+				if member.OpenReviews != want {
+					t.Fatalf("%s: got %d open reviews, want %d", member.UserID, member.OpenReviews, want)
+				}
+				if (member.MaxOpenReviews != nil) != (member.UserID == "u1") {
+					t.Fatalf("%s: unexpected cap %v", member.UserID, member.MaxOpenReviews)
+				}
+			}
+		}},
+		{"TeamDefault", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustSetTeamCap(t, repo, "backend", 0, 1)
+			full := mustCreatePR(t, repo, "pr-1", "author")
+			expectIDs(t, "nobody skipped", full.SkippedAtCap, nil)
+			partial := mustCreatePR(t, repo, "pr-2", "author")
+			expectIDs(t, "partially assigned", partial.Assigned, []string{"u3"})
+			expectIDs(t, "skipped at the cap", partial.SkippedAtCap, []string{"u1", "u2"})
+			empty := mustCreatePR(t, repo, "pr-3", "author")
+			expectIDs(t, "nobody below the cap", empty.Assigned, nil)
+			expectIDs(t, "everyone skipped", empty.SkippedAtCap, []string{"u1", "u2", "u3"})
+			stored, err := repo.GetPullRequest(context.Background(), "", "pr-3")
+			if err != nil || stored.SkippedAtCap != nil {
+				t.Fatalf("skipped reviewers are only reported on assignment: %+v %v", stored, err)
+			}
+
+			mustSetTeamCap(t, repo, "backend", 1, 1)
+			_, err = repo.CreatePullRequest(context.Background(), prInput("pr-4", "author"), pickFirst)
+			expectCode(t, err, domain.ErrCodeCapReached)
+
+			mustSetCap(t, repo, "u1", intPtr(0))
+			pr := mustCreatePR(t, repo, "pr-4", "author")
+			expectIDs(t, "uncapped user", pr.Assigned, []string{"u1"})
+		}},
+		{"Reassign", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustSetTeamCap(t, repo, "backend", 0, 1)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+
+			_, _, err := repo.ReassignReviewer(context.Background(), "", "pr-1", "u1", pickOne)
+			expectCode(t, err, domain.ErrCodeCapReached)
+
+			mustSetCap(t, repo, "u3", intPtr(2))
+			if replacedBy := mustReassign(t, repo, "pr-1", "u1"); replacedBy != "u3" {
+				t.Fatalf("u1 replaced by %q, want u3", replacedBy)
+			}
+			user := mustSetCap(t, repo, "u3", nil)
+			if user.MaxOpenReviews != nil {
+				t.Fatalf("cap must fall back to the team: %+v", user)
+			}
+		}},
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func mustSetCap(t *testing.T, repo service.Repository, userID string, limit *int) domain.User {
+	t.Helper()
+	user, err := repo.SetUserMaxOpenReviews(context.Background(), userID, limit)
+	if err != nil {
+		t.Fatalf("SetUserMaxOpenReviews(%s): %v", userID, err)
+	}
+	return user
+}
+
+func mustSetTeamCap(t *testing.T, repo service.Repository, teamName string, minimum, limit int) {
+	t.Helper()
+	update := service.TeamSettingsUpdate{MinReviewers: &minimum, MaxOpenReviews: &limit}
+	if _, err := repo.UpdateTeamSettings(context.Background(), teamName, update); err != nil {
+		t.Fatalf("UpdateTeamSettings(%s): %v", teamName, err)
+	}
+}
@@ -0,0 +1,86 @@
+package storagetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func forgeAccountCases() []testCase {
+	return []testCase{
+		{"LinkAndResolve", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustLinkAccount(t, repo, domain.ForgeGitHub, "alice", "u1")
+			mustLinkAccount(t, repo, "other", "alice", "u2")
+
+			expectResolved(t, repo, domain.ForgeGitHub, "alice", "u1")
+			expectResolved(t, repo, "other", "alice", "u2")
+			_, err := repo.ResolveForgeLogin(context.Background(), domain.ForgeGitHub, "bob")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"RelinkReplacesUser", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustLinkAccount(t, repo, domain.ForgeGitHub, "alice", "u1")
+			mustLinkAccount(t, repo, domain.ForgeGitHub, "alice", "u3")
+
+			expectResolved(t, repo, domain.ForgeGitHub, "alice", "u3")
+		}},
+		{"LinkUnknownUser", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.LinkForgeAccount(context.Background(), domain.ForgeAccount{Forge: domain.ForgeGitHub, Login: "ghost", UserID: "missing"})
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"ListAndUnlink", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustLinkAccount(t, repo, domain.ForgeGitHub, "bob", "u2")
+			mustLinkAccount(t, repo, domain.ForgeGitHub, "alice", "u1")
+			mustLinkAccount(t, repo, "other", "carol", "u3")
+
+			all, err := repo.ListForgeAccounts(context.Background(), "")
+			if err != nil {
+				t.Fatalf("ListForgeAccounts: %v", err)
+			}
+			want := []domain.ForgeAccount{
+				{Forge: domain.ForgeGitHub, Login: "alice", UserID: "u1"},
+				{Forge: domain.ForgeGitHub, Login: "bob", UserID: "u2"},
+				{Forge: "other", Login: "carol", UserID: "u3"},
+			}
+			if !reflect.DeepEqual(all, want) {
+				t.Fatalf("got accounts %+v, want %+v", all, want)
+			}
+
+			if err := repo.UnlinkForgeAccount(context.Background(), domain.ForgeGitHub, "bob"); err != nil {
+				t.Fatalf("UnlinkForgeAccount: %v", err)
+			}
+			expectCode(t, repo.UnlinkForgeAccount(context.Background(), domain.ForgeGitHub, "bob"), domain.ErrCodeNotFound)
+			github, err := repo.ListForgeAccounts(context.Background(), domain.ForgeGitHub)
+			if err != nil {
+				t.Fatalf("ListForgeAccounts: %v", err)
+			}
+			if !reflect.DeepEqual(github, want[:1]) {
+				t.Fatalf("got accounts %+v, want %+v", github, want[:1])
+			}
+		}},
+	}
+}
+
+func mustLinkAccount(t *testing.T, repo service.Repository, forge, login, userID string) {
+	t.Helper()
+	if _, err := repo.LinkForgeAccount(context.Background(), domain.ForgeAccount{Forge: forge, Login: login, UserID: userID}); err != nil {
+		t.Fatalf("LinkForgeAccount(%s, %s): %v", forge, login, err)
+	}
+}
+
+func expectResolved(t *testing.T, repo service.Repository, forge, login, want string) {
+	t.Helper()
+	got, err := repo.ResolveForgeLogin(context.Background(), forge, login)
+	if err != nil {
+		t.Fatalf("ResolveForgeLogin(%s, %s): %v", forge, login, err)
+	}
+	if got != want {
+		t.Fatalf("ResolveForgeLogin(%s, %s) = %q, want %q", forge, login, got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func membershipCases() []testCase {
+	return []testCase{
+		{"AddCreatesAndAttachesUsers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustRemoveMembers(t, repo, "backend", "u4")
+
+			team, err := repo.AddTeamMembers(context.Background(), "frontend", []domain.TeamMember{
+				member("u4", "Dave", true),
+				member("n1", "Nora", true),
+			})
+			expectCode(t, err, "")
+			expectIDs(t, "frontend members", memberIDs(team), []string{"u4", "f1", "f2", "n1"})
+			if !team.Members[0].IsActive {
+				t.Fatalf("attached user keeps stale activity flag: %+v", team.Members[0])
+			}
+		}},
+		{"AddRejectsMemberOfAnotherTeam", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.AddTeamMembers(context.Background(), "frontend", []domain.TeamMember{
+				member("n1", "Nora", true),
+				member("u1", "Alice", true),
+			})
+			expectCode(t, err, domain.ErrCodeUserInTeam)
+
+			team, err := repo.GetTeam(context.Background(), "frontend")
+			expectCode(t, err, "")
+			expectIDs(t, "frontend members", memberIDs(team), []string{"f1", "f2"})
+		}},
+		{"AddKeepsExistingMember", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			team, err := repo.AddTeamMembers(context.Background(), "frontend", []domain.TeamMember{member("f2", "Frank", false)})
+			expectCode(t, err, "")
+			expectIDs(t, "frontend members", memberIDs(team), []string{"f1", "f2"})
+			if team.Members[1].IsActive {
+				t.Fatalf("member flags not updated: %+v", team.Members[1])
+			}
+		}},
+		{"AddToMissingTeam", func(t *testing.T, repo service.Repository) {
+			_, err := repo.AddTeamMembers(context.Background(), "missing", []domain.TeamMember{member("n1", "Nora", true)})
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"RemoveDetachesUsers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			result, err := repo.RemoveTeamMembers(context.Background(), service.RemoveTeamMembersInput{TeamName: "backend", UserIDs: []string{"u1"}}, pickOne)
+			expectCode(t, err, "")
+			expectIDs(t, "backend members", memberIDs(result.Team), []string{"author", "u2", "u3", "u4"})
+			if len(result.Reassignments) != 0 {
+				t.Fatalf("unexpected reassignments: %+v", result.Reassignments)
+			}
+			expectReviews(t, repo, "u1", "pr-1")
+
+			user, err := repo.SetUserActive(context.Background(), "u1", true)
+			expectCode(t, err, "")
+			if user.TeamName != "" {
+				t.Fatalf("removed user still in team %q", user.TeamName)
+			}
+
+			_, err = repo.CreatePullRequest(context.Background(), prInput("pr-2", "u1"), pickFirst)
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"RemoveReassignsReviews", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			result, err := repo.RemoveTeamMembers(context.Background(), service.RemoveTeamMembersInput{
+				TeamName:        "backend",
+				UserIDs:         []string{"u1"},
+				ReassignReviews: true,
+			}, pickOne)
+			expectCode(t, err, "")
+			expectReplacements(t, result.Reassignments, domain.ReviewerReplacement{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"})
+			expectReviews(t, repo, "u1")
+			expectReviews(t, repo, "u3", "pr-1")
+		}},
+		{"RemoveNonMember", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.RemoveTeamMembers(context.Background(), service.RemoveTeamMembersInput{TeamName: "backend", UserIDs: []string{"u1", "f1"}}, pickOne)
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			team, err := repo.GetTeam(context.Background(), "backend")
+			expectCode(t, err, "")
+			expectIDs(t, "backend members", memberIDs(team), []string{"u1", "author", "u2", "u3", "u4"})
+		}},
+		{"MoveKeepsReviewsByDefault", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			result, err := repo.MoveTeamMember(context.Background(), service.MoveTeamMemberInput{UserID: "u1", TeamName: "frontend"}, pickOne)
+			expectCode(t, err, "")
+			expectIDs(t, "frontend members", memberIDs(result.Team), []string{"u1", "f1", "f2"})
+			if len(result.Reassignments) != 0 {
+				t.Fatalf("unexpected reassignments: %+v", result.Reassignments)
+			}
+			expectReviews(t, repo, "u1", "pr-1")
+		}},
+		{"MoveReassignsReviewsOutsideAuthorTeam", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "f1")
+
+			result, err := repo.MoveTeamMember(context.Background(), service.MoveTeamMemberInput{UserID: "u1", TeamName: "frontend", ReassignReviews: true}, pickOne)
+			expectCode(t, err, "")
+			expectReplacements(t, result.Reassignments, domain.ReviewerReplacement{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"})
+			expectReviews(t, repo, "u1")
+			expectReviews(t, repo, "f2", "pr-2")
+		}},
+		{"MoveLeavesSlotEmptyWithoutCandidates", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustSetActive(t, repo, "u3", false)
+
+			result, err := repo.MoveTeamMember(context.Background(), service.MoveTeamMemberInput{UserID: "u1", TeamName: "frontend", ReassignReviews: true}, pickOne)
+			expectCode(t, err, "")
+			expectReplacements(t, result.Reassignments, domain.ReviewerReplacement{PullRequestID: "pr-1", OldReviewerID: "u1"})
+			expectReviews(t, repo, "u1")
+		}},
+		{"MoveUnknownTargets", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.MoveTeamMember(context.Background(), service.MoveTeamMemberInput{UserID: "ghost", TeamName: "frontend"}, pickOne)
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.MoveTeamMember(context.Background(), service.MoveTeamMemberInput{UserID: "u1", TeamName: "missing"}, pickOne)
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
+
+func mustRemoveMembers(t *testing.T, repo service.Repository, teamName string, userIDs ...string) {
+	t.Helper()
+	if _, err := repo.RemoveTeamMembers(context.Background(), service.RemoveTeamMembersInput{TeamName: teamName, UserIDs: userIDs}, pickOne); err != nil {
+		t.Fatalf("RemoveTeamMembers(%s): %v", teamName, err)
+	}
+}
+
+// expectReviews checks the ids of pull requests userID reviews, newest first.
+func expectReviews(t *testing.T, repo service.Repository, userID string, want ...string) {
+	t.Helper()
+	reviews, err := repo.GetUserReviews(context.Background(), userID, allReviews)
+	expectCode(t, err, "")
+	expectIDs(t, "reviews of "+userID, reviewIDs(reviews), want)
+}
+
+func expectReplacements(t *testing.T, got []domain.ReviewerReplacement, want ...domain.ReviewerReplacement) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got reassignments %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got reassignments %+v, want %+v", got, want)
+		}
+	}
+}
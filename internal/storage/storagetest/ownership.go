@@ -0,0 +1,141 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func ownershipCases() []testCase {
+	return []testCase{
+		{"CreateListDelete", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "mono")
+			mustCreateRepository(t, repo, "api")
+			deploy := mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", TeamName: "frontend"})
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "api", Pattern: "*.sql", UserIDs: []string{"u1", "u3"}})
+			if deploy.ID == 0 || deploy.CreatedAt.IsZero() {
+				t.Fatalf("rule id and creation time must be set: %+v", deploy)
+			}
+
+			all, err := repo.ListOwnershipRules(context.Background(), "")
+			if err != nil {
+				t.Fatalf("ListOwnershipRules: %v", err)
+			}
+			if len(all) != 2 || all[0].Repository != "api" || all[1].ID != deploy.ID {
+				t.Fatalf("rules must be ordered by repository: %+v", all)
+			}
+			expectIDs(t, "rule users", all[0].UserIDs, []string{"u1", "u3"})
+
+			if err := repo.DeleteOwnershipRule(context.Background(), deploy.ID); err != nil {
+				t.Fatalf("DeleteOwnershipRule: %v", err)
+			}
+			expectCode(t, repo.DeleteOwnershipRule(context.Background(), deploy.ID), domain.ErrCodeNotFound)
+			mono, err := repo.ListOwnershipRules(context.Background(), "mono")
+			if err != nil {
+				t.Fatalf("ListOwnershipRules(mono): %v", err)
+			}
+			if len(mono) != 0 {
+				t.Fatalf("deleted rule is still listed: %+v", mono)
+			}
+		}},
+		{"UnknownOwners", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.CreateOwnershipRule(context.Background(), domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", TeamName: "frontend"})
+			expectCode(t, err, domain.ErrCodeNotFound)
+			mustCreateRepository(t, repo, "mono")
+			_, err = repo.CreateOwnershipRule(context.Background(), domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", TeamName: "infra"})
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.CreateOwnershipRule(context.Background(), domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", UserIDs: []string{"u1", "ghost"}})
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"CreateAssignsOwnersFirst", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "mono")
+			mustCreateRepository(t, repo, "other")
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", TeamName: "frontend"})
+
+			pr := mustCreatePRWithFiles(t, repo, "pr-1", "mono", "README.md", "deploy/k8s/app.yaml")
+			expectIDs(t, "owner and teammate", pr.Assigned, []string{"f1", "u1"})
+
+			pr = mustCreatePRWithFiles(t, repo, "pr-2", "mono", "README.md")
+			expectIDs(t, "no matching rule", pr.Assigned, []string{"u1", "u2"})
+
+			pr = mustCreatePRWithFiles(t, repo, "pr-3", "other", "deploy/k8s/app.yaml")
+			expectIDs(t, "rule of another repository", pr.Assigned, []string{"u1", "u2"})
+		}},
+		{"OwnerWithoutCandidate", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "mono")
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "*.sql", UserIDs: []string{"u4"}})
+
+			input := prWithFiles("pr-1", "mono", "db/001_init.sql")
+			_, err := repo.CreatePullRequest(context.Background(), input, pickFirst)
+			expectCode(t, err, domain.ErrCodeNoCandidate)
+			_, err = repo.GetPullRequest(context.Background(), "mono", "pr-1")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"ReassignAboveTeamSetting", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "mono")
+			mustUpdateTeam(t, repo, "backend", 1, 0)
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", TeamName: "frontend"})
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "*.sql", UserIDs: []string{"u1"}})
+
+			pr := mustCreatePRWithFiles(t, repo, "pr-1", "mono", "deploy/app.yaml", "db/001_init.sql")
+			expectIDs(t, "owners above the team setting", pr.Assigned, []string{"f1", "u1"})
+
+			pr, replacedBy, err := repo.ReassignReviewer(context.Background(), "mono", "pr-1", "f1", pickOne)
+			if err != nil {
+				t.Fatalf("ReassignReviewer: %v", err)
+			}
+			if replacedBy != "f2" {
+				t.Fatalf("replaced by %q, want f2", replacedBy)
+			}
+			expectIDs(t, "reviewers after swap", pr.Assigned, []string{"f2", "u1"})
+		}},
+		{"ReadyAppliesOwners", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "mono")
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "deploy/", UserIDs: []string{"f2"}})
+
+			input := prWithFiles("pr-1", "mono", "deploy/app.yaml")
+			input.Draft = true
+			if _, err := repo.CreatePullRequest(context.Background(), input, pickFirst); err != nil {
+				t.Fatalf("CreatePullRequest(draft): %v", err)
+			}
+			pr, _, err := repo.ChangePullRequestStatus(context.Background(), "mono", "pr-1", service.TransitionReady, pickFirst)
+			if err != nil {
+				t.Fatalf("ChangePullRequestStatus(ready): %v", err)
+			}
+			expectIDs(t, "reviewers of ready PR", pr.Assigned, []string{"f2", "u1"})
+		}},
+	}
+}
+
+func prWithFiles(id, repository string, files ...string) service.CreatePullRequestInput {
+	input := prInput(id, "author")
+	input.Repository = repository
+	input.ChangedFiles = files
+	return input
+}
+
+func mustCreatePRWithFiles(t *testing.T, repo service.Repository, id, repository string, files ...string) domain.PullRequest {
+	t.Helper()
+	pr, err := repo.CreatePullRequest(context.Background(), prWithFiles(id, repository, files...), pickFirst)
+	if err != nil {
+		t.Fatalf("CreatePullRequest(%s): %v", id, err)
+	}
+	return pr
+}
+
+func mustCreateRule(t *testing.T, repo service.Repository, rule domain.OwnershipRule) domain.OwnershipRule {
+	t.Helper()
+	created, err := repo.CreateOwnershipRule(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("CreateOwnershipRule(%s): %v", rule.Pattern, err)
+	}
+	return created
+}
@@ -0,0 +1,781 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func createPullRequestCases() []testCase {
+	tests := []struct {
+		name           string
+		prepare        func(t *testing.T, repo service.Repository)
+		authorID       string
+		wantCandidates []string
+		wantAssigned   []string
+		wantCode       domain.ErrorCode
+	}{
+		{
+			name:           "ExcludesAuthorAndInactive",
+			authorID:       "author",
+			wantCandidates: []string{"u1", "u2", "u3"},
+			wantAssigned:   []string{"u1", "u2"},
+		},
+		{
+			name:           "UsesTeamRequiredReviewers",
+			prepare:        func(t *testing.T, repo service.Repository) { mustUpdateTeam(t, repo, "backend", 3, 0) },
+			authorID:       "author",
+			wantCandidates: []string{"u1", "u2", "u3"},
+			wantAssigned:   []string{"u1", "u2", "u3"},
+		},
+		{
+			name:           "SingleReviewerTeam",
+			prepare:        func(t *testing.T, repo service.Repository) { mustUpdateTeam(t, repo, "backend", 1, 0) },
+			authorID:       "author",
+			wantCandidates: []string{"u1", "u2", "u3"},
+			wantAssigned:   []string{"u1"},
+		},
+		{
+			name:           "AssignsAvailableWhenFewCandidates",
+			prepare:        func(t *testing.T, repo service.Repository) { mustSetActive(t, repo, "f2", false) },
+			authorID:       "f1",
+			wantCandidates: []string{},
+			wantAssigned:   []string{},
+		},
+		{
+			name: "BelowMinimumReviewers",
+			prepare: func(t *testing.T, repo service.Repository) {
+				mustUpdateTeam(t, repo, "backend", 3, 3)
+				mustSetActive(t, repo, "u3", false)
+			},
+			authorID: "author",
+			wantCode: domain.ErrCodeNoCandidate,
+		},
+		{
+			name:     "UnknownAuthor",
+			authorID: "ghost",
+			wantCode: domain.ErrCodeNotFound,
+		},
+	}
+
+	cases := make([]testCase, 0, len(tests)+3)
+	for _, tt := range tests {
+		cases = append(cases, testCase{tt.name, func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			if tt.prepare != nil {
+				tt.prepare(t, repo)
+			}
+
+			var seen []service.Candidate
+			pr, err := repo.CreatePullRequest(context.Background(), prInput("pr-1", tt.authorID), func(candidates []service.Candidate, tags []string, limit int) []string {
+				seen = candidates
+				return pickFirst(candidates, tags, limit)
+			})
+			expectCode(t, err, tt.wantCode)
+			if tt.wantCode != "" {
+				_, statsErr := repo.GetPullRequestStats(context.Background(), "", "pr-1")
+				expectCode(t, statsErr, domain.ErrCodeNotFound)
+				return
+			}
+
+			expectIDs(t, "candidates", candidateIDs(seen), tt.wantCandidates)
+			expectIDs(t, "assigned", pr.Assigned, tt.wantAssigned)
+			if pr.PullRequestID != "pr-1" || pr.AuthorID != tt.authorID || pr.Status != "OPEN" || pr.MergedAt != nil || pr.CreatedAt.IsZero() {
+				t.Fatalf("unexpected pull request: %+v", pr)
+			}
+		}})
+	}
+
+	return append(cases,
+		testCase{"DuplicateID", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			_, err := repo.CreatePullRequest(context.Background(), prInput("pr-1", "f1"), pickFirst)
+			expectCode(t, err, domain.ErrCodePRExists)
+		}},
+		testCase{"CandidatesCarryOpenLoad", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+			mustMerge(t, repo, "pr-2")
+
+			var seen []service.Candidate
+			_, err := repo.CreatePullRequest(context.Background(), prInput("pr-3", "author"), func(candidates []service.Candidate, tags []string, limit int) []string {
+				seen = candidates
+				return nil
+			})
+			expectCode(t, err, "")
+
+			want := []service.Candidate{{UserID: "u1", OpenReviews: 1}, {UserID: "u2", OpenReviews: 1}, {UserID: "u3", OpenReviews: 0}}
+			if !reflect.DeepEqual(seen, want) {
+				t.Fatalf("got candidates %+v, want %+v", seen, want)
+			}
+		}},
+		testCase{"ReturnedStateIsPersisted", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			created := mustCreatePR(t, repo, "pr-1", "author")
+			stats, err := repo.GetPullRequestStats(context.Background(), "", "pr-1")
+			expectCode(t, err, "")
+			if stats.ReviewersCount != len(created.Assigned) || stats.PullRequestName != created.PullRequestName {
+				t.Fatalf("stored state %+v differs from returned %+v", stats, created)
+			}
+		}},
+	)
+}
+
+func mergeCases() []testCase {
+	return []testCase{
+		{"IdempotentMerge", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			first, firstChanged, err := repo.ChangePullRequestStatus(context.Background(), "", "pr-1", service.TransitionMerge, pickFirst)
+			if err != nil {
+				t.Fatalf("first merge: %v", err)
+			}
+			second, secondChanged, err := repo.ChangePullRequestStatus(context.Background(), "", "pr-1", service.TransitionMerge, pickFirst)
+			if err != nil {
+				t.Fatalf("second merge: %v", err)
+			}
+
+			if first.Status != "MERGED" || first.MergedAt == nil || second.MergedAt == nil || !first.MergedAt.Equal(*second.MergedAt) {
+				t.Fatalf("merge is not idempotent: %+v vs %+v", first, second)
+			}
+			if !firstChanged || secondChanged {
+				t.Fatalf("changed flags: first %v, second %v", firstChanged, secondChanged)
+			}
+			expectIDs(t, "assigned after merge", second.Assigned, []string{"u1", "u2"})
+		}},
+		{"MergedPullRequestIsImmutable", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustMerge(t, repo, "pr-1")
+
+			_, _, err := repo.ReassignReviewer(context.Background(), "", "pr-1", "u1", pickOne)
+			expectCode(t, err, domain.ErrCodePRMerged)
+
+			result, err := repo.DeactivateUsers(context.Background(), service.DeactivateUsersInput{UserIDs: []string{"u1"}}, pickOne)
+			expectCode(t, err, "")
+			if len(result.Reassignments) != 0 {
+				t.Fatalf("merged PR must not be touched by deactivation: %+v", result)
+			}
+		}},
+		{"MissingPullRequest", func(t *testing.T, repo service.Repository) {
+			_, _, err := repo.ChangePullRequestStatus(context.Background(), "", "missing", service.TransitionMerge, pickFirst)
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
+
+func lifecycleCases() []testCase {
+	return []testCase{
+		{"DraftHasNoReviewers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			pr := mustCreateDraft(t, repo, "pr-1", "author")
+			if pr.Status != domain.StatusDraft || len(pr.Assigned) != 0 {
+				t.Fatalf("unexpected draft: %+v", pr)
+			}
+
+			_, _, err := repo.ReassignReviewer(context.Background(), "", "pr-1", "u1", pickOne)
+			expectCode(t, err, domain.ErrCodeNotAssigned)
+		}},
+		{"ReadyAssignsReviewers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateDraft(t, repo, "pr-1", "author")
+
+			pr := mustChangeStatus(t, repo, "pr-1", service.TransitionReady)
+			if pr.Status != domain.StatusOpen {
+				t.Fatalf("unexpected status: %+v", pr)
+			}
+			expectIDs(t, "assigned", pr.Assigned, []string{"u1", "u2"})
+			expectReviews(t, repo, "u1", "pr-1")
+		}},
+		{"ReadyBelowTeamMinimum", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustUpdateTeam(t, repo, "frontend", 2, 2)
+			mustCreateDraft(t, repo, "pr-1", "f1")
+
+			_, _, err := repo.ChangePullRequestStatus(context.Background(), "", "pr-1", service.TransitionReady, pickFirst)
+			expectCode(t, err, domain.ErrCodeNoCandidate)
+
+			stats, err := repo.GetPullRequestStats(context.Background(), "", "pr-1")
+			expectCode(t, err, "")
+			if stats.Status != domain.StatusDraft || stats.ReviewersCount != 0 {
+				t.Fatalf("failed transition left changes behind: %+v", stats)
+			}
+		}},
+		{"CloseFreezesReviewers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			pr := mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+			if pr.Status != domain.StatusClosed || pr.ClosedAt == nil || pr.MergedAt != nil {
+				t.Fatalf("unexpected closed PR: %+v", pr)
+			}
+			expectIDs(t, "assigned after close", pr.Assigned, []string{"u1", "u2"})
+
+			_, _, err := repo.ReassignReviewer(context.Background(), "", "pr-1", "u1", pickOne)
+			expectCode(t, err, domain.ErrCodePRClosed)
+
+			result, err := repo.DeactivateUsers(context.Background(), service.DeactivateUsersInput{UserIDs: []string{"u1"}}, pickOne)
+			expectCode(t, err, "")
+			if len(result.Reassignments) != 0 {
+				t.Fatalf("closed PR must not be touched by deactivation: %+v", result)
+			}
+		}},
+		{"CloseDraft", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateDraft(t, repo, "pr-1", "author")
+
+			pr := mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+			if pr.Status != domain.StatusClosed || len(pr.Assigned) != 0 {
+				t.Fatalf("unexpected closed draft: %+v", pr)
+			}
+		}},
+		{"ReopenKeepsReviewers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+
+			pr := mustChangeStatus(t, repo, "pr-1", service.TransitionReopen)
+			if pr.Status != domain.StatusOpen || pr.ClosedAt != nil {
+				t.Fatalf("unexpected reopened PR: %+v", pr)
+			}
+			expectIDs(t, "assigned after reopen", pr.Assigned, []string{"u1", "u2"})
+			expectReviews(t, repo, "u1", "pr-1")
+		}},
+		{"RepeatedTransitionIsNoop", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			first := mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+			second := mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+			if first.ClosedAt == nil || second.ClosedAt == nil || !first.ClosedAt.Equal(*second.ClosedAt) {
+				t.Fatalf("close is not idempotent: %+v vs %+v", first, second)
+			}
+		}},
+		{"RejectsInvalidTransitions", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateDraft(t, repo, "draft", "author")
+			mustCreatePR(t, repo, "closed", "author")
+			mustChangeStatus(t, repo, "closed", service.TransitionClose)
+			mustCreatePR(t, repo, "merged", "author")
+			mustMerge(t, repo, "merged")
+
+			for _, tc := range []struct {
+				prID       string
+				transition service.Transition
+			}{
+				{"draft", service.TransitionMerge},
+				{"draft", service.TransitionReopen},
+				{"closed", service.TransitionMerge},
+				{"closed", service.TransitionReady},
+				{"merged", service.TransitionClose},
+				{"merged", service.TransitionReopen},
+			} {
+				_, _, err := repo.ChangePullRequestStatus(context.Background(), "", tc.prID, tc.transition, pickFirst)
+				expectCode(t, err, domain.ErrCodeInvalidTransition)
+			}
+		}},
+		{"ClosedExcludedFromActiveLoad", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+			mustChangeStatus(t, repo, "pr-1", service.TransitionClose)
+
+			expectReviews(t, repo, "u1", "pr-2")
+
+			var seen []service.Candidate
+			_, err := repo.CreatePullRequest(context.Background(), prInput("pr-3", "author"), func(candidates []service.Candidate, tags []string, limit int) []string {
+				seen = append(seen, candidates...)
+				return pickFirst(candidates, tags, limit)
+			})
+			expectCode(t, err, "")
+			for _, c := range seen {
+				if c.UserID == "u1" && c.OpenReviews != 1 {
+					t.Fatalf("closed PR counted in load: %+v", seen)
+				}
+			}
+		}},
+		{"StatsCountDraftAndClosed", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateDraft(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+			mustChangeStatus(t, repo, "pr-2", service.TransitionClose)
+
+			teams, err := repo.GetTeamStats(context.Background(), "backend")
+			expectCode(t, err, "")
+			if len(teams) != 1 || teams[0].DraftPullRequests != 1 || teams[0].ClosedPullRequests != 1 || teams[0].OpenPullRequests != 0 || teams[0].OpenAssignments != 0 {
+				t.Fatalf("unexpected team stats: %+v", teams)
+			}
+
+			reviewers, err := repo.GetReviewerStats(context.Background(), "backend")
+			expectCode(t, err, "")
+			for _, r := range reviewers {
+				if r.UserID == "u1" && (r.ClosedReviews != 1 || r.OpenReviews != 0) {
+					t.Fatalf("unexpected reviewer stats: %+v", r)
+				}
+			}
+		}},
+	}
+}
+
+func getPullRequestCases() []testCase {
+	return []testCase{
+		{"ReturnsStoredState", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			merged := mustChangeStatus(t, repo, "pr-1", service.TransitionMerge)
+
+			got, err := repo.GetPullRequest(context.Background(), "", "pr-1")
+			expectCode(t, err, "")
+			if got.PullRequestName != "PR pr-1" || got.AuthorID != "author" || got.Status != domain.StatusMerged {
+				t.Fatalf("unexpected pull request: %+v", got)
+			}
+			if got.MergedAt == nil || !got.MergedAt.Equal(*merged.MergedAt) || got.CreatedAt.IsZero() {
+				t.Fatalf("unexpected timestamps: %+v", got)
+			}
+			expectIDs(t, "assigned", got.Assigned, []string{"u1", "u2"})
+		}},
+		{"Missing", func(t *testing.T, repo service.Repository) {
+			_, err := repo.GetPullRequest(context.Background(), "", "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
+
+// seedPullRequests builds on seed with pull requests in every status:
+//
+//	pr-1 "Add search" by author: u1, u2, open
+//	pr-2 "Fix login" by author: u1, u2, merged
+//	pr-3 "Search UI" by f1: f2, closed
+//	pr-4 "Refactor" by u1: draft
+func seedPullRequests(t *testing.T, repo service.Repository) {
+	t.Helper()
+	seed(t, repo)
+	for _, pr := range []struct{ id, name, authorID string }{
+		{"pr-1", "Add search", "author"},
+		{"pr-2", "Fix login", "author"},
+		{"pr-3", "Search UI", "f1"},
+	} {
+		input := prInput(pr.id, pr.authorID)
+		input.PullRequestName = pr.name
+		if _, err := repo.CreatePullRequest(context.Background(), input, pickFirst); err != nil {
+			t.Fatalf("CreatePullRequest(%s): %v", pr.id, err)
+		}
+	}
+	mustMerge(t, repo, "pr-2")
+	mustChangeStatus(t, repo, "pr-3", service.TransitionClose)
+	draft := prInput("pr-4", "u1")
+	draft.PullRequestName, draft.Draft = "Refactor", true
+	if _, err := repo.CreatePullRequest(context.Background(), draft, pickFirst); err != nil {
+		t.Fatalf("CreatePullRequest(pr-4): %v", err)
+	}
+}
+
+func listPullRequestCases() []testCase {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	tests := []struct {
+		name   string
+		filter service.PullRequestFilter
+		want   []string
+	}{
+		{name: "All", want: []string{"pr-4", "pr-3", "pr-2", "pr-1"}},
+		{name: "OldestFirst", filter: service.PullRequestFilter{Order: service.OrderOldest}, want: []string{"pr-1", "pr-2", "pr-3", "pr-4"}},
+		{name: "ByAuthor", filter: service.PullRequestFilter{AuthorID: "author"}, want: []string{"pr-2", "pr-1"}},
+		{name: "ByTeam", filter: service.PullRequestFilter{TeamName: "frontend"}, want: []string{"pr-3"}},
+		{name: "ByReviewer", filter: service.PullRequestFilter{ReviewerID: "f2"}, want: []string{"pr-3"}},
+		{name: "ByStatus", filter: service.PullRequestFilter{Statuses: []string{domain.StatusOpen, domain.StatusDraft}}, want: []string{"pr-4", "pr-1"}},
+		{name: "ByNameIgnoringCase", filter: service.PullRequestFilter{NameContains: "SEARCH"}, want: []string{"pr-3", "pr-1"}},
+		{name: "CreatedInRange", filter: service.PullRequestFilter{CreatedFrom: &past, CreatedTo: &future}, want: []string{"pr-4", "pr-3", "pr-2", "pr-1"}},
+		{name: "CreatedAfterRange", filter: service.PullRequestFilter{CreatedTo: &past}},
+		{name: "MergedInRange", filter: service.PullRequestFilter{MergedFrom: &past}, want: []string{"pr-2"}},
+		{name: "MergedBeforeRange", filter: service.PullRequestFilter{MergedFrom: &future}},
+		{name: "Combined", filter: service.PullRequestFilter{TeamName: "backend", ReviewerID: "u1", Statuses: []string{domain.StatusOpen}}, want: []string{"pr-1"}},
+	}
+
+	cases := make([]testCase, 0, len(tests)+3)
+	for _, tt := range tests {
+		cases = append(cases, testCase{tt.name, func(t *testing.T, repo service.Repository) {
+			seedPullRequests(t, repo)
+			filter := tt.filter
+			if filter.Order == "" {
+				filter.Order = service.OrderNewest
+			}
+			filter.Limit = service.MaxPageSize
+
+			got, err := repo.ListPullRequests(context.Background(), filter)
+			expectCode(t, err, "")
+			if got.NextCursor != "" {
+				t.Fatalf("unexpected next cursor: %q", got.NextCursor)
+			}
+			expectIDs(t, "pull requests", pullRequestIDs(got), tt.want)
+		}})
+	}
+
+	return append(cases,
+		testCase{"ReturnsFullPullRequests", func(t *testing.T, repo service.Repository) {
+			seedPullRequests(t, repo)
+			got, err := repo.ListPullRequests(context.Background(), service.PullRequestFilter{AuthorID: "author", Order: service.OrderNewest, Limit: 1})
+			expectCode(t, err, "")
+			if len(got.PullRequests) != 1 {
+				t.Fatalf("unexpected page: %+v", got)
+			}
+			pr := got.PullRequests[0]
+			if pr.PullRequestID != "pr-2" || pr.PullRequestName != "Fix login" || pr.Status != domain.StatusMerged || pr.MergedAt == nil {
+				t.Fatalf("unexpected pull request: %+v", pr)
+			}
+			expectIDs(t, "assigned", pr.Assigned, []string{"u1", "u2"})
+		}},
+		testCase{"PaginatesInBothOrders", func(t *testing.T, repo service.Repository) {
+			seedPullRequests(t, repo)
+			for _, tc := range []struct {
+				order string
+				want  []string
+			}{
+				{service.OrderNewest, []string{"pr-4", "pr-3", "pr-2", "pr-1"}},
+				{service.OrderOldest, []string{"pr-1", "pr-2", "pr-3", "pr-4"}},
+			} {
+				filter := service.PullRequestFilter{Order: tc.order, Limit: 3}
+				var ids []string
+				for pages := 1; ; pages++ {
+					page, err := repo.ListPullRequests(context.Background(), filter)
+					expectCode(t, err, "")
+					ids = append(ids, pullRequestIDs(page)...)
+					if page.NextCursor == "" {
+						if pages != 2 {
+							t.Fatalf("%s: got %d pages, want 2", tc.order, pages)
+						}
+						break
+					}
+					cursor, err := service.DecodeCursor(page.NextCursor)
+					expectCode(t, err, "")
+					filter.After = &cursor
+				}
+				expectIDs(t, tc.order, ids, tc.want)
+			}
+		}},
+		testCase{"MissingTeam", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.ListPullRequests(context.Background(), service.PullRequestFilter{TeamName: "missing", Order: service.OrderNewest, Limit: service.MaxPageSize})
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	)
+}
+
+func reassignCases() []testCase {
+	tests := []struct {
+		name         string
+		prepare      func(t *testing.T, repo service.Repository)
+		prID         string
+		oldUserID    string
+		wantReplaced string
+		wantAssigned []string
+		wantCode     domain.ErrorCode
+	}{
+		{
+			name:         "ReplacesWithActiveTeammate",
+			prID:         "pr-1",
+			oldUserID:    "u1",
+			wantReplaced: "u3",
+			wantAssigned: []string{"u2", "u3"},
+		},
+		{
+			name:      "NotAssigned",
+			prID:      "pr-1",
+			oldUserID: "u3",
+			wantCode:  domain.ErrCodeNotAssigned,
+		},
+		{
+			name:      "UnknownReviewer",
+			prID:      "pr-1",
+			oldUserID: "ghost",
+			wantCode:  domain.ErrCodeNotFound,
+		},
+		{
+			name:      "UnknownPullRequest",
+			prID:      "missing",
+			oldUserID: "u1",
+			wantCode:  domain.ErrCodeNotFound,
+		},
+		{
+			name:      "NoCandidate",
+			prepare:   func(t *testing.T, repo service.Repository) { mustSetActive(t, repo, "u3", false) },
+			prID:      "pr-1",
+			oldUserID: "u1",
+			wantCode:  domain.ErrCodeNoCandidate,
+		},
+		{
+			name:         "SwapsAboveTeamSetting",
+			prepare:      func(t *testing.T, repo service.Repository) { mustUpdateTeam(t, repo, "backend", 1, 0) },
+			prID:         "pr-1",
+			oldUserID:    "u1",
+			wantReplaced: "u3",
+			wantAssigned: []string{"u2", "u3"},
+		},
+		{
+			name:      "MergedPullRequest",
+			prepare:   func(t *testing.T, repo service.Repository) { mustMerge(t, repo, "pr-1") },
+			prID:      "pr-1",
+			oldUserID: "u1",
+			wantCode:  domain.ErrCodePRMerged,
+		},
+	}
+
+	cases := make([]testCase, 0, len(tests))
+	for _, tt := range tests {
+		cases = append(cases, testCase{tt.name, func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			if tt.prepare != nil {
+				tt.prepare(t, repo)
+			}
+
+			pr, replacedBy, err := repo.ReassignReviewer(context.Background(), "", tt.prID, tt.oldUserID, pickOne)
+			expectCode(t, err, tt.wantCode)
+			if tt.wantCode != "" {
+				return
+			}
+			if replacedBy != tt.wantReplaced {
+				t.Fatalf("replaced by %q, want %q", replacedBy, tt.wantReplaced)
+			}
+			expectIDs(t, "assigned", pr.Assigned, tt.wantAssigned)
+		}})
+	}
+	return cases
+}
+
+func deactivateCases() []testCase {
+	tests := []struct {
+		name              string
+		prepare           func(t *testing.T, repo service.Repository)
+		input             service.DeactivateUsersInput
+		wantDeactivated   []string
+		wantReassignments []domain.ReviewerReplacement
+		wantCode          domain.ErrorCode
+	}{
+		{
+			name:            "ReassignsOpenReviews",
+			input:           service.DeactivateUsersInput{UserIDs: []string{"u1"}},
+			wantDeactivated: []string{"u1"},
+			wantReassignments: []domain.ReviewerReplacement{
+				{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			},
+		},
+		{
+			name:            "WholeTeamLeavesEmptySlots",
+			input:           service.DeactivateUsersInput{TeamName: "backend"},
+			wantDeactivated: []string{"author", "u1", "u2", "u3", "u4"},
+			wantReassignments: []domain.ReviewerReplacement{
+				{PullRequestID: "pr-1", OldReviewerID: "u1"},
+				{PullRequestID: "pr-1", OldReviewerID: "u2"},
+			},
+		},
+		{
+			name:            "TeamAndUsersCombined",
+			input:           service.DeactivateUsersInput{TeamName: "frontend", UserIDs: []string{"u2"}},
+			wantDeactivated: []string{"f1", "f2", "u2"},
+			wantReassignments: []domain.ReviewerReplacement{
+				{PullRequestID: "pr-1", OldReviewerID: "u2", NewReviewerID: "u3"},
+				{PullRequestID: "pr-2", OldReviewerID: "f2"},
+			},
+		},
+		{
+			name:     "UnknownUser",
+			input:    service.DeactivateUsersInput{UserIDs: []string{"u1", "ghost"}},
+			wantCode: domain.ErrCodeNotFound,
+		},
+		{
+			name:     "UnknownTeam",
+			input:    service.DeactivateUsersInput{TeamName: "missing"},
+			wantCode: domain.ErrCodeNotFound,
+		},
+	}
+
+	cases := make([]testCase, 0, len(tests))
+	for _, tt := range tests {
+		cases = append(cases, testCase{tt.name, func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "f1")
+			if tt.prepare != nil {
+				tt.prepare(t, repo)
+			}
+
+			result, err := repo.DeactivateUsers(context.Background(), tt.input, pickOne)
+			expectCode(t, err, tt.wantCode)
+			if tt.wantCode != "" {
+				backend, getErr := repo.GetTeam(context.Background(), "backend")
+				expectCode(t, getErr, "")
+				for _, m := range backend.Members {
+					if m.UserID == "u1" && !m.IsActive {
+						t.Fatalf("failed deactivation must not change users")
+					}
+				}
+				return
+			}
+
+			expectIDs(t, "deactivated", result.Deactivated, tt.wantDeactivated)
+			if len(result.Reassignments) != len(tt.wantReassignments) {
+				t.Fatalf("got reassignments %+v, want %+v", result.Reassignments, tt.wantReassignments)
+			}
+			for i, want := range tt.wantReassignments {
+				if result.Reassignments[i] != want {
+					t.Fatalf("got reassignments %+v, want %+v", result.Reassignments, tt.wantReassignments)
+				}
+			}
+
+			deactivated := make(map[string]bool, len(tt.wantDeactivated))
+			for _, id := range tt.wantDeactivated {
+				deactivated[id] = true
+			}
+			stats, err := repo.GetReviewerStats(context.Background(), "")
+			expectCode(t, err, "")
+			for _, s := range stats {
+				if deactivated[s.UserID] && (s.IsActive || s.OpenReviews != 0) {
+					t.Fatalf("deactivated user %s still active or reviewing: %+v", s.UserID, s)
+				}
+			}
+		}})
+	}
+	return cases
+}
+
+func userReviewCases() []testCase {
+	return []testCase{
+		{"NewestFirstWithStatus", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+			mustCreatePR(t, repo, "pr-3", "f1")
+			mustMerge(t, repo, "pr-1")
+
+			reviews, err := repo.GetUserReviews(context.Background(), "u1", allReviews)
+			expectCode(t, err, "")
+			if reviews.UserID != "u1" || len(reviews.PullRequests) != 2 || reviews.NextCursor != "" {
+				t.Fatalf("unexpected reviews: %+v", reviews)
+			}
+			got := reviews.PullRequests
+			if got[0].PullRequestID != "pr-2" || got[0].Status != "OPEN" || got[1].PullRequestID != "pr-1" || got[1].Status != "MERGED" {
+				t.Fatalf("unexpected reviews: %+v", got)
+			}
+			if got[0].AuthorID != "author" || got[0].PullRequestName != "PR pr-2" {
+				t.Fatalf("unexpected review fields: %+v", got[0])
+			}
+		}},
+		{"UserWithoutReviews", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			reviews, err := repo.GetUserReviews(context.Background(), "u4", allReviews)
+			expectCode(t, err, "")
+			if reviews.UserID != "u4" || len(reviews.PullRequests) != 0 {
+				t.Fatalf("unexpected reviews: %+v", reviews)
+			}
+		}},
+		{"FiltersByStatus", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+			mustCreatePR(t, repo, "pr-3", "author")
+			mustMerge(t, repo, "pr-1")
+			mustChangeStatus(t, repo, "pr-3", service.TransitionClose)
+
+			for _, tc := range []struct {
+				statuses []string
+				want     []string
+			}{
+				{[]string{domain.StatusOpen}, []string{"pr-2"}},
+				{[]string{domain.StatusMerged}, []string{"pr-1"}},
+				{[]string{domain.StatusClosed}, []string{"pr-3"}},
+				{[]string{domain.StatusOpen, domain.StatusClosed}, []string{"pr-3", "pr-2"}},
+			} {
+				reviews, err := repo.GetUserReviews(context.Background(), "u1", service.UserReviewsFilter{Statuses: tc.statuses, Limit: service.MaxPageSize})
+				expectCode(t, err, "")
+				expectIDs(t, fmt.Sprintf("reviews with status %v", tc.statuses), reviewIDs(reviews), tc.want)
+			}
+		}},
+		{"FiltersByCreatedRange", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+			for _, tc := range []struct {
+				name     string
+				from, to *time.Time
+				want     []string
+			}{
+				{"FromPast", &past, nil, []string{"pr-1"}},
+				{"FromFuture", &future, nil, nil},
+				{"ToFuture", nil, &future, []string{"pr-1"}},
+				{"ToPast", nil, &past, nil},
+				{"Between", &past, &future, []string{"pr-1"}},
+			} {
+				reviews, err := repo.GetUserReviews(context.Background(), "u1", service.UserReviewsFilter{CreatedFrom: tc.from, CreatedTo: tc.to, Limit: service.MaxPageSize})
+				expectCode(t, err, "")
+				expectIDs(t, tc.name, reviewIDs(reviews), tc.want)
+			}
+		}},
+		{"PaginatesWithCursor", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			for _, id := range []string{"pr-1", "pr-2", "pr-3", "pr-4", "pr-5"} {
+				mustCreatePR(t, repo, id, "author")
+			}
+
+			filter := service.UserReviewsFilter{Limit: 2}
+			var pages [][]string
+			for {
+				reviews, err := repo.GetUserReviews(context.Background(), "u1", filter)
+				expectCode(t, err, "")
+				pages = append(pages, reviewIDs(reviews))
+				if reviews.NextCursor == "" {
+					break
+				}
+				cursor, err := service.DecodeCursor(reviews.NextCursor)
+				expectCode(t, err, "")
+				filter.After = &cursor
+			}
+
+			if len(pages) != 3 {
+				t.Fatalf("got pages %v, want 3 pages", pages)
+			}
+			expectIDs(t, "page 1", pages[0], []string{"pr-5", "pr-4"})
+			expectIDs(t, "page 2", pages[1], []string{"pr-3", "pr-2"})
+			expectIDs(t, "page 3", pages[2], []string{"pr-1"})
+		}},
+		{"NoCursorOnFullLastPage", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+
+			reviews, err := repo.GetUserReviews(context.Background(), "u1", service.UserReviewsFilter{Limit: 2})
+			expectCode(t, err, "")
+			expectIDs(t, "reviews", reviewIDs(reviews), []string{"pr-2", "pr-1"})
+			if reviews.NextCursor != "" {
+				t.Fatalf("unexpected next cursor on last page: %q", reviews.NextCursor)
+			}
+		}},
+	}
+}
+
+// allReviews asks for the default statuses in a single page.
+var allReviews = service.UserReviewsFilter{Limit: service.MaxPageSize}
+
+func reviewIDs(reviews domain.UserReviews) []string {
+	ids := make([]string, 0, len(reviews.PullRequests))
+	for _, pr := range reviews.PullRequests {
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids
+}
+
+func pullRequestIDs(list domain.PullRequestList) []string {
+	ids := make([]string, 0, len(list.PullRequests))
+	for _, pr := range list.PullRequests {
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids
+}
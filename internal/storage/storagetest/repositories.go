@@ -0,0 +1,232 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func repositoryCases() []testCase {
+	return []testCase{
+		{"CreateGetList", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mono := mustCreateRepository(t, repo, "mono", "backend", "frontend")
+			if mono.CreatedAt.IsZero() {
+				t.Fatalf("creation time must be set: %+v", mono)
+			}
+			expectIDs(t, "mono teams", mono.Teams, []string{"backend", "frontend"})
+			api := mustCreateRepository(t, repo, "api")
+			if api.Teams == nil || len(api.Teams) != 0 {
+				t.Fatalf("repository without teams must list none: %+v", api)
+			}
+
+			_, err := repo.CreateRepository(context.Background(), domain.Repository{Name: "mono"})
+			expectCode(t, err, domain.ErrCodeRepositoryExists)
+			_, err = repo.CreateRepository(context.Background(), domain.Repository{Name: "web", Teams: []string{"frontend", "ghost"}})
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.GetRepository(context.Background(), "web")
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			got, err := repo.GetRepository(context.Background(), "mono")
+			if err != nil {
+				t.Fatalf("GetRepository: %v", err)
+			}
+			expectIDs(t, "stored mono teams", got.Teams, []string{"backend", "frontend"})
+
+			expectRepositories(t, repo, "", "api", "mono")
+			expectRepositories(t, repo, "frontend", "mono")
+			_, err = repo.ListRepositories(context.Background(), "ghost")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"SetTeams", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "mono", "backend")
+
+			updated, err := repo.SetRepositoryTeams(context.Background(), "mono", []string{"frontend"})
+			if err != nil {
+				t.Fatalf("SetRepositoryTeams: %v", err)
+			}
+			expectIDs(t, "replaced teams", updated.Teams, []string{"frontend"})
+			expectRepositories(t, repo, "backend")
+
+			_, err = repo.SetRepositoryTeams(context.Background(), "mono", []string{"backend", "ghost"})
+			expectCode(t, err, domain.ErrCodeNotFound)
+			expectRepositories(t, repo, "frontend", "mono")
+			_, err = repo.SetRepositoryTeams(context.Background(), "web", nil)
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			updated, err = repo.SetRepositoryTeams(context.Background(), "mono", nil)
+			if err != nil {
+				t.Fatalf("SetRepositoryTeams(nil): %v", err)
+			}
+			if len(updated.Teams) != 0 {
+				t.Fatalf("teams must be cleared: %+v", updated)
+			}
+		}},
+		{"ScopedPullRequestIDs", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "api")
+			mustCreateRepository(t, repo, "mono")
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePRIn(t, repo, "api", "pr-1")
+			mustCreatePRIn(t, repo, "mono", "pr-1")
+
+			_, err := repo.CreatePullRequest(context.Background(), prIn("api", "pr-1"), pickFirst)
+			expectCode(t, err, domain.ErrCodePRExists)
+			_, err = repo.CreatePullRequest(context.Background(), prIn("web", "pr-1"), pickFirst)
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			merged, _, err := repo.ChangePullRequestStatus(context.Background(), "api", "pr-1", service.TransitionMerge, pickFirst)
+			if err != nil {
+				t.Fatalf("ChangePullRequestStatus(api): %v", err)
+			}
+			if merged.Repository != "api" || merged.Status != domain.StatusMerged {
+				t.Fatalf("unexpected merged PR: %+v", merged)
+			}
+			for _, repository := range []string{"", "mono"} {
+				pr, err := repo.GetPullRequest(context.Background(), repository, "pr-1")
+				if err != nil {
+					t.Fatalf("GetPullRequest(%q): %v", repository, err)
+				}
+				if pr.Repository != repository || pr.Status != domain.StatusOpen {
+					t.Fatalf("merge leaked into %q: %+v", repository, pr)
+				}
+			}
+
+			if _, _, err := repo.ReassignReviewer(context.Background(), "mono", "pr-1", "u1", pickOne); err != nil {
+				t.Fatalf("ReassignReviewer(mono): %v", err)
+			}
+			history, err := repo.GetPullRequestHistory(context.Background(), "mono", "pr-1")
+			if err != nil {
+				t.Fatalf("GetPullRequestHistory(mono): %v", err)
+			}
+			expectEvents(t, history,
+				domain.AuditEvent{Reason: domain.AuditAssigned, Repository: "mono", PullRequestID: "pr-1", NewReviewerID: "u1"},
+				domain.AuditEvent{Reason: domain.AuditAssigned, Repository: "mono", PullRequestID: "pr-1", NewReviewerID: "u2"},
+				domain.AuditEvent{Reason: domain.AuditReassigned, Repository: "mono", PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			)
+			stats, err := repo.GetPullRequestStats(context.Background(), "", "pr-1")
+			if err != nil {
+				t.Fatalf("GetPullRequestStats: %v", err)
+			}
+			if stats.Reassignments != 0 {
+				t.Fatalf("reassignment leaked into the default repository: %+v", stats)
+			}
+
+			list, err := repo.ListPullRequests(context.Background(), service.PullRequestFilter{Repository: "mono", Limit: 10})
+			if err != nil {
+				t.Fatalf("ListPullRequests(mono): %v", err)
+			}
+			if len(list.PullRequests) != 1 || list.PullRequests[0].Repository != "mono" {
+				t.Fatalf("repository filter ignored: %+v", list.PullRequests)
+			}
+			expectIDs(t, "mono reviewers", list.PullRequests[0].Assigned, []string{"u2", "u3"})
+		}},
+		{"CreateRequiresLinkedTeam", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "web", "frontend")
+			mustCreateRepository(t, repo, "api", "backend", "frontend")
+			mustCreateRepository(t, repo, "mono")
+
+			_, err := repo.CreatePullRequest(context.Background(), prIn("web", "pr-1"), pickFirst)
+			expectCode(t, err, domain.ErrCodeTeamNotLinked)
+			mustCreatePRIn(t, repo, "api", "pr-1")
+			mustCreatePRIn(t, repo, "mono", "pr-1")
+
+			if _, err := repo.SetRepositoryTeams(context.Background(), "web", []string{"backend"}); err != nil {
+				t.Fatalf("SetRepositoryTeams: %v", err)
+			}
+			mustCreatePRIn(t, repo, "web", "pr-1")
+		}},
+		{"PagesAcrossRepositories", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "api")
+			mustCreateRepository(t, repo, "mono")
+			mustCreatePRIn(t, repo, "api", "pr-1")
+			mustCreatePRIn(t, repo, "mono", "pr-1")
+			mustCreatePRIn(t, repo, "mono", "pr-2")
+
+			seen := map[string]bool{}
+			filter := service.PullRequestFilter{Limit: 1}
+			for page := 0; page < 4; page++ {
+				list, err := repo.ListPullRequests(context.Background(), filter)
+				if err != nil {
+					t.Fatalf("ListPullRequests: %v", err)
+				}
+				for _, pr := range list.PullRequests {
+					seen[pr.Repository+"/"+pr.PullRequestID] = true
+				}
+				if list.NextCursor == "" {
+					break
+				}
+				after, err := service.DecodeCursor(list.NextCursor)
+				if err != nil {
+					t.Fatalf("DecodeCursor: %v", err)
+				}
+				filter.After = &after
+			}
+			if len(seen) != 3 {
+				t.Fatalf("pages must cover every pull request once: %v", seen)
+			}
+		}},
+		{"Delete", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateRepository(t, repo, "api")
+			mustCreateRepository(t, repo, "mono", "backend")
+			mustCreatePRIn(t, repo, "api", "pr-1")
+			mustCreateRule(t, repo, domain.OwnershipRule{Repository: "mono", Pattern: "deploy/**", TeamName: "frontend"})
+
+			expectCode(t, repo.DeleteRepository(context.Background(), "api"), domain.ErrCodeRepositoryInUse)
+			expectCode(t, repo.DeleteRepository(context.Background(), "mono"), "")
+			expectCode(t, repo.DeleteRepository(context.Background(), "mono"), domain.ErrCodeNotFound)
+			expectRepositories(t, repo, "", "api")
+
+			rules, err := repo.ListOwnershipRules(context.Background(), "")
+			if err != nil {
+				t.Fatalf("ListOwnershipRules: %v", err)
+			}
+			if len(rules) != 0 {
+				t.Fatalf("rules of a deleted repository must go: %+v", rules)
+			}
+		}},
+	}
+}
+
+func prIn(repository, id string) service.CreatePullRequestInput {
+	input := prInput(id, "author")
+	input.Repository = repository
+	return input
+}
+
+func mustCreatePRIn(t *testing.T, repo service.Repository, repository, id string) domain.PullRequest {
+	t.Helper()
+	pr, err := repo.CreatePullRequest(context.Background(), prIn(repository, id), pickFirst)
+	if err != nil {
+		t.Fatalf("CreatePullRequest(%s/%s): %v", repository, id, err)
+	}
+	return pr
+}
+
+func mustCreateRepository(t *testing.T, repo service.Repository, name string, teams ...string) domain.Repository {
+	t.Helper()
+	created, err := repo.CreateRepository(context.Background(), domain.Repository{Name: name, Teams: teams})
+	if err != nil {
+		t.Fatalf("CreateRepository(%s): %v", name, err)
+	}
+	return created
+}
+
+func expectRepositories(t *testing.T, repo service.Repository, teamName string, want ...string) {
+	t.Helper()
+	repos, err := repo.ListRepositories(context.Background(), teamName)
+	if err != nil {
+		t.Fatalf("ListRepositories(%q): %v", teamName, err)
+	}
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+	expectIDs(t, "repositories of "+teamName, names, want)
+}
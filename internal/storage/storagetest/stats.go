@@ -0,0 +1,117 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// seedActivity builds on seed with some review history:
+//
+//	pr-1 by author: u1 reassigned to u3, open with u2 and u3
+//	pr-2 by author: u1 and u2, merged
+func seedActivity(t *testing.T, repo service.Repository) {
+	t.Helper()
+	seed(t, repo)
+	mustCreatePR(t, repo, "pr-1", "author")
+	if replacedBy := mustReassign(t, repo, "pr-1", "u1"); replacedBy != "u3" {
+		t.Fatalf("seed: u1 replaced by %q, want u3", replacedBy)
+	}
+	mustCreatePR(t, repo, "pr-2", "author")
+	mustMerge(t, repo, "pr-2")
+}
+
+func statsCases() []testCase {
+	return []testCase{
+		{"Reviewers", func(t *testing.T, repo service.Repository) {
+			seedActivity(t, repo)
+
+			got, err := repo.GetReviewerStats(context.Background(), "backend")
+			expectCode(t, err, "")
+			want := []domain.ReviewerStats{
+				{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true, AssignedTotal: 1, MergedReviews: 1, ReassignedFrom: 1},
+				{UserID: "author", Username: "Author", TeamName: "backend", IsActive: true},
+				{UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true, AssignedTotal: 2, OpenReviews: 1, MergedReviews: 1},
+				{UserID: "u3", Username: "Carol", TeamName: "backend", IsActive: true, AssignedTotal: 1, OpenReviews: 1, ReassignedTo: 1},
+				{UserID: "u4", Username: "Dave", TeamName: "backend"},
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("row %d: got %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		}},
+		{"ReviewersAllTeams", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			got, err := repo.GetReviewerStats(context.Background(), "")
+			expectCode(t, err, "")
+			ids := make([]string, 0, len(got))
+			for _, s := range got {
+				ids = append(ids, s.UserID)
+			}
+			expectIDs(t, "reviewers", ids, []string{"u1", "author", "u2", "u3", "u4", "f1", "f2"})
+		}},
+		{"Teams", func(t *testing.T, repo service.Repository) {
+			seedActivity(t, repo)
+
+			got, err := repo.GetTeamStats(context.Background(), "")
+			expectCode(t, err, "")
+			want := []domain.TeamStats{
+				{
+					TeamName:           "backend",
+					MembersTotal:       5,
+					ActiveMembers:      4,
+					PullRequestsTotal:  2,
+					OpenPullRequests:   1,
+					MergedPullRequests: 1,
+					AssignmentsTotal:   4,
+					OpenAssignments:    2,
+					Reassignments:      1,
+				},
+				{TeamName: "frontend", MembersTotal: 2, ActiveMembers: 2},
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("row %d: got %+v, want %+v", i, got[i], want[i])
+				}
+			}
+
+			single, err := repo.GetTeamStats(context.Background(), "frontend")
+			expectCode(t, err, "")
+			if len(single) != 1 || single[0] != want[1] {
+				t.Fatalf("got %+v, want %+v", single, want[1:])
+			}
+		}},
+		{"PullRequests", func(t *testing.T, repo service.Repository) {
+			seedActivity(t, repo)
+
+			for _, want := range []domain.PullRequestStats{
+				{PullRequestID: "pr-1", PullRequestName: "PR pr-1", AuthorID: "author", Status: "OPEN", ReviewersCount: 2, Reassignments: 1},
+				{PullRequestID: "pr-2", PullRequestName: "PR pr-2", AuthorID: "author", Status: "MERGED", ReviewersCount: 2},
+			} {
+				got, err := repo.GetPullRequestStats(context.Background(), "", want.PullRequestID)
+				expectCode(t, err, "")
+				if got != want {
+					t.Fatalf("got %+v, want %+v", got, want)
+				}
+			}
+		}},
+		{"MissingTargets", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.GetReviewerStats(context.Background(), "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.GetTeamStats(context.Background(), "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+			_, err = repo.GetPullRequestStats(context.Background(), "", "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
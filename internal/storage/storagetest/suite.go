@@ -0,0 +1,235 @@
+// Package storagetest holds the behavioural contract every service.Repository
+// implementation must satisfy. Storage backends run it from their own tests.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+// Factory returns a fresh, empty repository for a single test.
+type Factory func(t *testing.T) service.Repository
+
+type testCase struct {
+	name string
+	run  func(t *testing.T, repo service.Repository)
+}
+
+// Run exercises the service.Repository contract against repositories built by newRepo.
+// Every case receives its own empty repository.
+func Run(t *testing.T, newRepo Factory) {
+	groups := []struct {
+		name  string
+		cases []testCase
+	}{
+		{"Teams", teamCases()},
+		{"Users", userCases()},
+		{"Membership", membershipCases()},
+		{"CreatePullRequest", createPullRequestCases()},
+		{"MergePullRequest", mergeCases()},
+		{"Lifecycle", lifecycleCases()},
+		{"ReassignReviewer", reassignCases()},
+		{"DeactivateUsers", deactivateCases()},
+		{"GetUserReviews", userReviewCases()},
+		{"GetPullRequest", getPullRequestCases()},
+		{"ListPullRequests", listPullRequestCases()},
+		{"Audit", auditCases()},
+		{"Webhooks", webhookCases()},
+		{"APIKeys", apiKeyCases()},
+		{"ForgeAccounts", forgeAccountCases()},
+		{"Tags", tagCases()},
+		{"Availability", availabilityCases()},
+		{"Capacity", capacityCases()},
+		{"Repositories", repositoryCases()},
+		{"OwnershipRules", ownershipCases()},
+		{"Stats", statsCases()},
+	}
+
+	for _, group := range groups {
+		t.Run(group.name, func(t *testing.T) {
+			for _, tc := range group.cases {
+				t.Run(tc.name, func(t *testing.T) {
+					tc.run(t, newRepo(t))
+				})
+			}
+		})
+	}
+}
+
+// seed creates the fixture most cases start from:
+//
+//	backend:  author, u1 (Alice), u2 (Bob), u3 (Carol) active; u4 (Dave) inactive
+//	frontend: f1 (Fiona), f2 (Frank) active
+func seed(t *testing.T, repo service.Repository) {
+	t.Helper()
+	mustCreateTeam(t, repo, "backend",
+		member("author", "Author", true),
+		member("u1", "Alice", true),
+		member("u2", "Bob", true),
+		member("u3", "Carol", true),
+		member("u4", "Dave", false),
+	)
+	mustCreateTeam(t, repo, "frontend",
+		member("f1", "Fiona", true),
+		member("f2", "Frank", true),
+	)
+}
+
+func member(id, name string, active bool) domain.TeamMember {
+	return domain.TeamMember{UserID: id, Username: name, IsActive: active}
+}
+
+func prInput(id, authorID string) service.CreatePullRequestInput {
+	return service.CreatePullRequestInput{PullRequestID: id, PullRequestName: "PR " + id, AuthorID: authorID}
+}
+
+func mustCreateTeam(t *testing.T, repo service.Repository, name string, members ...domain.TeamMember) domain.Team {
+	t.Helper()
+	team, err := repo.CreateTeam(context.Background(), domain.Team{
+		TeamName:          name,
+		RequiredReviewers: domain.DefaultRequiredReviewers,
+		Members:           members,
+	})
+	if err != nil {
+		t.Fatalf("CreateTeam(%s): %v", name, err)
+	}
+	return team
+}
+
+func mustCreatePR(t *testing.T, repo service.Repository, id, authorID string) domain.PullRequest {
+	t.Helper()
+	pr, err := repo.CreatePullRequest(context.Background(), prInput(id, authorID), pickFirst)
+	if err != nil {
+		t.Fatalf("CreatePullRequest(%s): %v", id, err)
+	}
+	return pr
+}
+
+func mustSetActive(t *testing.T, repo service.Repository, userID string, active bool) {
+	t.Helper()
+	if _, err := repo.SetUserActive(context.Background(), userID, active); err != nil {
+		t.Fatalf("SetUserActive(%s): %v", userID, err)
+	}
+}
+
+func mustUpdateTeam(t *testing.T, repo service.Repository, teamName string, required, minimum int) {
+	t.Helper()
+	update := service.TeamSettingsUpdate{RequiredReviewers: &required, MinReviewers: &minimum}
+	if _, err := repo.UpdateTeamSettings(context.Background(), teamName, update); err != nil {
+		t.Fatalf("UpdateTeamSettings(%s): %v", teamName, err)
+	}
+}
+
+func mustMerge(t *testing.T, repo service.Repository, prID string) {
+	t.Helper()
+	mustChangeStatus(t, repo, prID, service.TransitionMerge)
+}
+
+func mustChangeStatus(t *testing.T, repo service.Repository, prID string, transition service.Transition) domain.PullRequest {
+	t.Helper()
+	pr, _, err := repo.ChangePullRequestStatus(context.Background(), "", prID, transition, pickFirst)
+	if err != nil {
+		t.Fatalf("ChangePullRequestStatus(%s, %s): %v", prID, transition.To, err)
+	}
+	return pr
+}
+
+func mustCreateDraft(t *testing.T, repo service.Repository, id, authorID string) domain.PullRequest {
+	t.Helper()
+	input := prInput(id, authorID)
+	input.Draft = true
+	pr, err := repo.CreatePullRequest(context.Background(), input, pickFirst)
+	if err != nil {
+		t.Fatalf("CreatePullRequest(%s, draft): %v", id, err)
+	}
+	return pr
+}
+
+func mustReassign(t *testing.T, repo service.Repository, prID, oldUserID string) string {
+	t.Helper()
+	_, replacedBy, err := repo.ReassignReviewer(context.Background(), "", prID, oldUserID, pickOne)
+	if err != nil {
+		t.Fatalf("ReassignReviewer(%s, %s): %v", prID, oldUserID, err)
+	}
+	return replacedBy
+}
+
+// pickFirst deterministically picks candidates in the order the repository
+// returns them, which is ascending user_id for every backend. It ignores
+// required tags like the plain strategies do.
+func pickFirst(candidates []service.Candidate, _ []string, limit int) []string {
+	return firstPicker{}.Pick(candidates, limit)
+}
+
+func pickOne(candidates []service.Candidate) (string, bool) {
+	return firstPicker{}.PickOne(candidates)
+}
+
+// pickCovering is pickFirst with the tag coverage strategy on top.
+var pickCovering = service.NewTagCoveragePicker(firstPicker{}).PickCovering
+
+type firstPicker struct{}
+
+func (firstPicker) Pick(candidates []service.Candidate, limit int) []string {
+	ids := candidateIDs(candidates)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids
+}
+
+func (p firstPicker) PickOne(candidates []service.Candidate) (string, bool) {
+	ids := p.Pick(candidates, 1)
+	if len(ids) == 0 {
+		return "", false
+	}
+	return ids[0], true
+}
+
+func candidateIDs(candidates []service.Candidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.UserID)
+	}
+	return ids
+}
+
+func memberIDs(team domain.Team) []string {
+	ids := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+// expectCode fails unless err is a domain error with the given code.
+// An empty code expects no error at all.
+func expectCode(t *testing.T, err error, code domain.ErrorCode) {
+	t.Helper()
+	if code == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("expected %s error, got %v", code, err)
+	}
+}
+
+func expectIDs(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", what, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", what, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func tagCases() []testCase {
+	return []testCase{
+		{"SetTagsShowInTeam", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateTeam(t, repo, "design", domain.TeamMember{UserID: "d1", Username: "Dana", IsActive: true, Tags: []string{"css", "figma"}})
+
+			user, err := repo.SetUserTags(context.Background(), "u3", []string{"go", "sql"})
+			expectCode(t, err, "")
+			expectIDs(t, "returned tags", user.Tags, []string{"go", "sql"})
+			_, err = repo.SetUserTags(context.Background(), "ghost", []string{"go"})
+			expectCode(t, err, domain.ErrCodeNotFound)
+
+			expectIDs(t, "carol tags", memberTags(t, repo, "backend", "u3"), []string{"go", "sql"})
+			expectIDs(t, "dana tags", memberTags(t, repo, "design", "d1"), []string{"css", "figma"})
+
+			user, err = repo.SetUserTags(context.Background(), "u3", nil)
+			expectCode(t, err, "")
+			if user.Tags != nil || memberTags(t, repo, "backend", "u3") != nil {
+				t.Fatalf("tags must be cleared: %+v", user)
+			}
+		}},
+		{"RequiredTagsPickHolder", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustSetTags(t, repo, "u3", "frontend")
+
+			pr, err := repo.CreatePullRequest(context.Background(), taggedPR("pr-1", "frontend"), pickCovering)
+			if err != nil {
+				t.Fatalf("CreatePullRequest: %v", err)
+			}
+			expectIDs(t, "reviewers", pr.Assigned, []string{"u1", "u3"})
+			expectIDs(t, "required tags", pr.RequiredTags, []string{"frontend"})
+
+			stored, err := repo.GetPullRequest(context.Background(), "", "pr-1")
+			expectCode(t, err, "")
+			expectIDs(t, "stored required tags", stored.RequiredTags, []string{"frontend"})
+
+			pr, err = repo.CreatePullRequest(context.Background(), taggedPR("pr-2", "frontend"), pickFirst)
+			expectCode(t, err, "")
+			expectIDs(t, "reviewers without tag coverage", pr.Assigned, []string{"u1", "u2"})
+		}},
+		{"MissingTagIsSkipped", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustSetTags(t, repo, "u4", "rust")
+
+			pr, err := repo.CreatePullRequest(context.Background(), taggedPR("pr-1", "rust"), pickCovering)
+			expectCode(t, err, "")
+			expectIDs(t, "reviewers", pr.Assigned, []string{"u1", "u2"})
+		}},
+		{"ReadyDraftCoversTags", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			draft := taggedPR("pr-1", "sql")
+			draft.Draft = true
+			_, err := repo.CreatePullRequest(context.Background(), draft, pickCovering)
+			expectCode(t, err, "")
+
+			mustSetTags(t, repo, "u3", "sql")
+			pr, _, err := repo.ChangePullRequestStatus(context.Background(), "", "pr-1", service.TransitionReady, pickCovering)
+			if err != nil {
+				t.Fatalf("ChangePullRequestStatus: %v", err)
+			}
+			expectIDs(t, "reviewers", pr.Assigned, []string{"u1", "u3"})
+			if pr.Status != domain.StatusOpen {
+				t.Fatalf("draft must become open: %+v", pr)
+			}
+		}},
+	}
+}
+
+func taggedPR(id string, tags ...string) service.CreatePullRequestInput {
+	input := prInput(id, "author")
+	input.RequiredTags = tags
+	return input
+}
+
+func mustSetTags(t *testing.T, repo service.Repository, userID string, tags ...string) {
+	t.Helper()
+	if _, err := repo.SetUserTags(context.Background(), userID, tags); err != nil {
+		t.Fatalf("SetUserTags(%s): %v", userID, err)
+	}
+}
+
+func memberTags(t *testing.T, repo service.Repository, teamName, userID string) []string {
+	t.Helper()
+	team, err := repo.GetTeam(context.Background(), teamName)
+	if err != nil {
+		t.Fatalf("GetTeam(%s): %v", teamName, err)
+	}
+	for _, member := range team.Members {
+		if member.UserID == userID {
+			return member.Tags
+		}
+	}
+	t.Fatalf("%s is not a member of %s", userID, teamName)
+	return nil
+}
@@ -0,0 +1,115 @@
+package storagetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func teamCases() []testCase {
+	return []testCase{
+		{"CreateReturnsMembersSortedByUsername", func(t *testing.T, repo service.Repository) {
+			team, err := repo.CreateTeam(context.Background(), domain.Team{
+				TeamName:          "backend",
+				RequiredReviewers: 3,
+				MinReviewers:      1,
+				Members:           []domain.TeamMember{member("u2", "Bob", false), member("u1", "Alice", true)},
+			})
+			expectCode(t, err, "")
+			if team.TeamName != "backend" || team.RequiredReviewers != 3 || team.MinReviewers != 1 {
+				t.Fatalf("unexpected team: %+v", team)
+			}
+			expectIDs(t, "members", memberIDs(team), []string{"u1", "u2"})
+			if !team.Members[0].IsActive || team.Members[1].IsActive {
+				t.Fatalf("activity flags not stored: %+v", team.Members)
+			}
+		}},
+		{"CreateExistingTeamConflicts", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			_, err := repo.CreateTeam(context.Background(), domain.Team{
+				TeamName:          "backend",
+				RequiredReviewers: 2,
+				Members:           []domain.TeamMember{member("x1", "Xavier", true)},
+			})
+			expectCode(t, err, domain.ErrCodeTeamExists)
+
+			team, err := repo.GetTeam(context.Background(), "backend")
+			expectCode(t, err, "")
+			expectIDs(t, "members after conflict", memberIDs(team), []string{"u1", "author", "u2", "u3", "u4"})
+		}},
+		{"CreateMovesExistingUsers", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateTeam(t, repo, "platform", member("u1", "Alice", true))
+
+			backend, err := repo.GetTeam(context.Background(), "backend")
+			expectCode(t, err, "")
+			expectIDs(t, "backend members", memberIDs(backend), []string{"author", "u2", "u3", "u4"})
+
+			platform, err := repo.GetTeam(context.Background(), "platform")
+			expectCode(t, err, "")
+			expectIDs(t, "platform members", memberIDs(platform), []string{"u1"})
+		}},
+		{"GetMissingTeam", func(t *testing.T, repo service.Repository) {
+			_, err := repo.GetTeam(context.Background(), "missing")
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"UpdateSettings", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			team, err := repo.UpdateTeamSettings(context.Background(), "backend", service.TeamSettingsUpdate{
+				RequiredReviewers: intPtr(3), MinReviewers: intPtr(2), MaxOpenReviews: intPtr(4),
+			})
+			expectCode(t, err, "")
+			if team.RequiredReviewers != 3 || team.MinReviewers != 2 || team.MaxOpenReviews != 4 || len(team.Members) != 5 {
+				t.Fatalf("unexpected team: %+v", team)
+			}
+
+			_, err = repo.UpdateTeamSettings(context.Background(), "missing", service.TeamSettingsUpdate{RequiredReviewers: intPtr(1)})
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+		{"UpdateSettingsKeepsOmittedFields", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustUpdateTeam(t, repo, "backend", 3, 1)
+
+			team, err := repo.UpdateTeamSettings(context.Background(), "backend", service.TeamSettingsUpdate{MaxOpenReviews: intPtr(5)})
+			expectCode(t, err, "")
+			if team.RequiredReviewers != 3 || team.MinReviewers != 1 || team.MaxOpenReviews != 5 {
+				t.Fatalf("omitted settings changed: %+v", team)
+			}
+
+			_, err = repo.UpdateTeamSettings(context.Background(), "backend", service.TeamSettingsUpdate{MinReviewers: intPtr(4)})
+			expectCode(t, err, domain.ErrCodeNotFound)
+			team, err = repo.GetTeam(context.Background(), "backend")
+			expectCode(t, err, "")
+			if team.RequiredReviewers != 3 || team.MinReviewers != 1 || team.MaxOpenReviews != 5 {
+				t.Fatalf("rejected update changed settings: %+v", team)
+			}
+		}},
+	}
+}
+
+func userCases() []testCase {
+	return []testCase{
+		{"SetActiveTogglesFlag", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			user, err := repo.SetUserActive(context.Background(), "u4", true)
+			expectCode(t, err, "")
+			want := domain.User{UserID: "u4", Username: "Dave", TeamName: "backend", IsActive: true}
+			if !reflect.DeepEqual(user, want) {
+				t.Fatalf("got %+v, want %+v", user, want)
+			}
+
+			user, err = repo.SetUserActive(context.Background(), "u4", false)
+			expectCode(t, err, "")
+			if user.IsActive {
+				t.Fatalf("user still active: %+v", user)
+			}
+		}},
+		{"SetActiveMissingUser", func(t *testing.T, repo service.Repository) {
+			_, err := repo.SetUserActive(context.Background(), "ghost", true)
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
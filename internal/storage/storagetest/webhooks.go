@@ -0,0 +1,203 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+)
+
+func webhookCases() []testCase {
+	return []testCase{
+		{"CreateListDelete", func(t *testing.T, repo service.Repository) {
+			first := mustCreateWebhook(t, repo, domain.WebhookReviewerAssigned)
+			second := mustCreateWebhook(t, repo, domain.WebhookPullRequestMerged, domain.WebhookReviewerReassigned)
+			if first.ID == 0 || second.ID <= first.ID || first.CreatedAt.IsZero() {
+				t.Fatalf("unexpected webhooks: %+v, %+v", first, second)
+			}
+
+			hooks, err := repo.ListWebhooks(context.Background())
+			if err != nil {
+				t.Fatalf("ListWebhooks: %v", err)
+			}
+			if len(hooks) != 2 || hooks[1].ID != second.ID || hooks[1].Secret != "s3cret" ||
+				!reflect.DeepEqual(hooks[1].EventTypes, second.EventTypes) {
+				t.Fatalf("unexpected webhooks: %+v", hooks)
+			}
+
+			if err := repo.DeleteWebhook(context.Background(), first.ID); err != nil {
+				t.Fatalf("DeleteWebhook: %v", err)
+			}
+			expectCode(t, repo.DeleteWebhook(context.Background(), first.ID), domain.ErrCodeNotFound)
+			if hooks, _ := repo.ListWebhooks(context.Background()); len(hooks) != 1 {
+				t.Fatalf("got %d webhooks after delete, want 1", len(hooks))
+			}
+		}},
+		{"QueuesSubscribedEvents", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			assigned := mustCreateWebhook(t, repo, domain.WebhookReviewerAssigned)
+			merged := mustCreateWebhook(t, repo, domain.WebhookPullRequestMerged, domain.WebhookReviewerReassigned)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustReassign(t, repo, "pr-1", "u1")
+			mustMerge(t, repo, "pr-1")
+
+			got := mustListDeliveries(t, repo, assigned.ID, "")
+			if len(got) != 2 || got[0].EventType != domain.WebhookReviewerAssigned || got[0].ID <= got[1].ID {
+				t.Fatalf("unexpected deliveries: %+v", got)
+			}
+			expectPayload(t, got[1], domain.WebhookPayload{
+				Event:      domain.WebhookReviewerAssigned,
+				AuditEvent: domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: "pr-1", NewReviewerID: "u1"},
+			})
+
+			got = mustListDeliveries(t, repo, merged.ID, "")
+			if len(got) != 2 || got[0].Status != domain.DeliveryPending || got[0].Attempts != 0 {
+				t.Fatalf("unexpected deliveries: %+v", got)
+			}
+			expectPayload(t, got[1], domain.WebhookPayload{
+				Event:      domain.WebhookReviewerReassigned,
+				AuditEvent: domain.AuditEvent{Reason: domain.AuditReassigned, PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u3"},
+			})
+			expectPayload(t, got[0], domain.WebhookPayload{
+				Event:      domain.WebhookPullRequestMerged,
+				AuditEvent: domain.AuditEvent{Reason: domain.AuditMerged, PullRequestID: "pr-1"},
+			})
+		}},
+		{"FailedMutationQueuesNothing", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			hook := mustCreateWebhook(t, repo, domain.WebhookEventTypes...)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustSetActive(t, repo, "u3", false)
+			_, _, err := repo.ReassignReviewer(context.Background(), "", "pr-1", "u1", pickOne)
+			expectCode(t, err, domain.ErrCodeNoCandidate)
+
+			if got := mustListDeliveries(t, repo, hook.ID, ""); len(got) != 2 {
+				t.Fatalf("got %d deliveries, want the 2 assignments", len(got))
+			}
+		}},
+		{"ClaimCompleteAndFail", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			hook := mustCreateWebhook(t, repo, domain.WebhookReviewerAssigned)
+			mustCreatePR(t, repo, "pr-1", "author")
+
+			now := time.Now().Add(time.Minute)
+			lease := now.Add(time.Minute)
+			claimed := mustClaim(t, repo, now, lease, 10)
+			if len(claimed) != 2 || claimed[0].URL != hook.URL || claimed[0].Secret != hook.Secret || claimed[0].ID >= claimed[1].ID {
+				t.Fatalf("unexpected claimed deliveries: %+v", claimed)
+			}
+			if again := mustClaim(t, repo, now, lease, 10); len(again) != 0 {
+				t.Fatalf("leased deliveries claimed again: %+v", again)
+			}
+
+			if err := repo.CompleteWebhookDelivery(context.Background(), claimed[0].ID, now); err != nil {
+				t.Fatalf("CompleteWebhookDelivery: %v", err)
+			}
+			retryAt := lease.Add(time.Minute)
+			if err := repo.FailWebhookDelivery(context.Background(), claimed[1].ID, &retryAt, "status 500"); err != nil {
+				t.Fatalf("FailWebhookDelivery: %v", err)
+			}
+			if got := mustClaim(t, repo, lease, lease, 10); len(got) != 0 {
+				t.Fatalf("delivery claimed before its retry time: %+v", got)
+			}
+			retried := mustClaim(t, repo, retryAt, retryAt.Add(time.Minute), 10)
+			if len(retried) != 1 || retried[0].ID != claimed[1].ID || retried[0].Attempts != 1 || retried[0].LastError != "status 500" {
+				t.Fatalf("unexpected retried deliveries: %+v", retried)
+			}
+			if err := repo.FailWebhookDelivery(context.Background(), claimed[1].ID, nil, "status 502"); err != nil {
+				t.Fatalf("FailWebhookDelivery: %v", err)
+			}
+
+			delivered := mustListDeliveries(t, repo, hook.ID, domain.DeliveryDelivered)
+			if len(delivered) != 1 || delivered[0].Attempts != 1 || delivered[0].DeliveredAt == nil {
+				t.Fatalf("unexpected delivered deliveries: %+v", delivered)
+			}
+			dead := mustListDeliveries(t, repo, hook.ID, domain.DeliveryDead)
+			if len(dead) != 1 || dead[0].Attempts != 2 || dead[0].LastError != "status 502" {
+				t.Fatalf("unexpected dead deliveries: %+v", dead)
+			}
+			if got := mustClaim(t, repo, retryAt.Add(time.Hour), retryAt.Add(time.Hour), 10); len(got) != 0 {
+				t.Fatalf("finished deliveries claimed: %+v", got)
+			}
+			expectCode(t, repo.CompleteWebhookDelivery(context.Background(), 999, now), domain.ErrCodeNotFound)
+		}},
+		{"ClaimRespectsLimit", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			mustCreateWebhook(t, repo, domain.WebhookReviewerAssigned)
+			mustCreatePR(t, repo, "pr-1", "author")
+			mustCreatePR(t, repo, "pr-2", "author")
+
+			now := time.Now().Add(time.Minute)
+			first := mustClaim(t, repo, now, now.Add(time.Minute), 3)
+			second := mustClaim(t, repo, now, now.Add(time.Minute), 3)
+			if len(first) != 3 || len(second) != 1 || second[0].ID <= first[2].ID {
+				t.Fatalf("unexpected batches: %+v, %+v", first, second)
+			}
+		}},
+		{"DeleteDropsDeliveries", func(t *testing.T, repo service.Repository) {
+			seed(t, repo)
+			hook := mustCreateWebhook(t, repo, domain.WebhookReviewerAssigned)
+			mustCreatePR(t, repo, "pr-1", "author")
+			if err := repo.DeleteWebhook(context.Background(), hook.ID); err != nil {
+				t.Fatalf("DeleteWebhook: %v", err)
+			}
+
+			if got := mustClaim(t, repo, time.Now().Add(time.Minute), time.Now().Add(time.Hour), 10); len(got) != 0 {
+				t.Fatalf("deliveries of a deleted webhook claimed: %+v", got)
+			}
+			_, err := repo.ListWebhookDeliveries(context.Background(), service.WebhookDeliveryFilter{WebhookID: hook.ID, Limit: 10})
+			expectCode(t, err, domain.ErrCodeNotFound)
+		}},
+	}
+}
+
+func mustCreateWebhook(t *testing.T, repo service.Repository, eventTypes ...string) domain.Webhook {
+	t.Helper()
+	hook, err := repo.CreateWebhook(context.Background(), domain.Webhook{
+		URL:        "https://hooks.example.com/reviews",
+		Secret:     "s3cret",
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return hook
+}
+
+func mustListDeliveries(t *testing.T, repo service.Repository, webhookID int64, status string) []domain.WebhookDelivery {
+	t.Helper()
+	deliveries, err := repo.ListWebhookDeliveries(context.Background(), service.WebhookDeliveryFilter{WebhookID: webhookID, Status: status, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries(%d): %v", webhookID, err)
+	}
+	return deliveries
+}
+
+func mustClaim(t *testing.T, repo service.Repository, now, leaseUntil time.Time, limit int) []domain.WebhookDelivery {
+	t.Helper()
+	deliveries, err := repo.ClaimWebhookDeliveries(context.Background(), now, leaseUntil, limit)
+	if err != nil {
+		t.Fatalf("ClaimWebhookDeliveries: %v", err)
+	}
+	return deliveries
+}
+
+// expectPayload compares the delivery payload ignoring event id, time and actor.
+func expectPayload(t *testing.T, d domain.WebhookDelivery, want domain.WebhookPayload) {
+	t.Helper()
+	var got domain.WebhookPayload
+	if err := json.Unmarshal(d.Payload, &got); err != nil {
+		t.Fatalf("delivery %d payload %s: %v", d.ID, d.Payload, err)
+	}
+	if got.ID == 0 || got.OccurredAt.IsZero() {
+		t.Fatalf("payload misses event metadata: %s", d.Payload)
+	}
+	got.ID, got.OccurredAt = 0, time.Time{}
+	if got != want {
+		t.Fatalf("got payload %+v, want %+v", got, want)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"log/slog"
+	"time"
 
 	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/logging"
 	"github.com/GolovachevS/pr-reviewer-service/internal/service"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
 )
 
 // Store implements the service.Repository interface using PostgreSQL.
@@ -23,8 +27,14 @@ type Store struct {
 	pool pgxPool
 }
 
-func New(pool pgxPool) *Store {
-	return &Store{pool: pool}
+// New returns a store running statements on pool. Every statement and
+// transaction is traced.
+func New(pool pgxPool, opts ...Option) *Store {
+	o := options{tracerProvider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Store{pool: tracedPool{pool: pool, tracer: o.tracerProvider.Tracer(tracerName)}}
 }
 
 func (s *Store) CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error) {
@@ -41,24 +51,28 @@ func (s *Store) CreateTeam(ctx context.Context, team domain.Team) (domain.Team,
 		return domain.Team{}, scanErr
 	}
 
-	if _, execErr := tx.Exec(ctx, "INSERT INTO teams(team_name) VALUES($1)", team.TeamName); execErr != nil {
+	if _, execErr := tx.Exec(ctx, "INSERT INTO teams(team_name, required_reviewers, min_reviewers, max_open_reviews) VALUES($1, $2, $3, $4)", team.TeamName, team.RequiredReviewers, team.MinReviewers, team.MaxOpenReviews); execErr != nil {
 		return domain.Team{}, execErr
 	}
 
 	for _, member := range team.Members {
 		_, err = tx.Exec(
 			ctx,
-			`INSERT INTO users(user_id, username, team_name, is_active)
-			 VALUES($1, $2, $3, $4)
+			`INSERT INTO users(user_id, username, team_name, is_active, tags, max_open_reviews)
+			 VALUES($1, $2, $3, $4, $5, $6)
 			 ON CONFLICT (user_id)
 			 DO UPDATE SET username = EXCLUDED.username,
 			               team_name = EXCLUDED.team_name,
 			               is_active = EXCLUDED.is_active,
+			               tags = EXCLUDED.tags,
+			               max_open_reviews = EXCLUDED.max_open_reviews,
 			               updated_at = NOW()`,
 			member.UserID,
 			member.Username,
 			team.TeamName,
 			member.IsActive,
+			textArray(member.Tags),
+			member.MaxOpenReviews,
 		)
 		if err != nil {
 			return domain.Team{}, err
@@ -73,7 +87,12 @@ func (s *Store) CreateTeam(ctx context.Context, team domain.Team) (domain.Team,
 }
 
 func (s *Store) GetTeam(ctx context.Context, teamName string) (domain.Team, error) {
-	if err := s.ensureTeamExists(ctx, teamName); err != nil {
+	team := domain.Team{TeamName: teamName}
+	row := s.pool.QueryRow(ctx, "SELECT team_name, required_reviewers, min_reviewers, max_open_reviews FROM teams WHERE team_name=$1", teamName)
+	if err := row.Scan(&team.TeamName, &team.RequiredReviewers, &team.MinReviewers, &team.MaxOpenReviews); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Team{}, domain.NewNotFoundError("team not found", err)
+		}
 		return domain.Team{}, err
 	}
 
@@ -81,57 +100,113 @@ func (s *Store) GetTeam(ctx context.Context, teamName string) (domain.Team, erro
 	if err != nil {
 		return domain.Team{}, err
 	}
+	team.Members = members
 
-	return domain.Team{TeamName: teamName, Members: members}, nil
+	return team, nil
 }
 
-func (s *Store) SetUserActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
-	var user domain.User
-	row := s.pool.QueryRow(ctx, `UPDATE users SET is_active=$2, updated_at=NOW() WHERE user_id=$1 RETURNING user_id, username, team_name, is_active`, userID, isActive)
-	if err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.User{}, domain.NewNotFoundError("user not found", err)
+// UpdateTeamSettings changes the non-nil settings in one statement, so
+// concurrent partial updates of different fields do not overwrite each other.
+func (s *Store) UpdateTeamSettings(ctx context.Context, teamName string, update service.TeamSettingsUpdate) (domain.Team, error) {
+	tag, err := s.pool.Exec(ctx, `UPDATE teams
+		SET required_reviewers = COALESCE($2, required_reviewers),
+		    min_reviewers = COALESCE($3, min_reviewers),
+		    max_open_reviews = COALESCE($4, max_open_reviews)
+		WHERE team_name=$1`, teamName, update.RequiredReviewers, update.MinReviewers, update.MaxOpenReviews)
+	if err != nil {
+		if isCheckViolation(err) {
+			return domain.Team{}, domain.NewInvalidTeamSettingsError(err)
 		}
+		return domain.Team{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.Team{}, domain.NewNotFoundError("team not found", nil)
+	}
+	return s.GetTeam(ctx, teamName)
+}
+
+func (s *Store) SetUserActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.User{}, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	user, err := scanUser(tx.QueryRow(ctx, `UPDATE users SET is_active=$2, updated_at=NOW() WHERE user_id=$1 `+returningUser, userID, isActive))
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	reason := domain.AuditUserDeactivated
+	if isActive {
+		reason = domain.AuditUserActivated
+	}
+	if err := recordEventsTx(ctx, tx, domain.AuditEvent{Reason: reason, UserID: userID}); err != nil {
+		return domain.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
 }
 
-func (s *Store) CreatePullRequest(ctx context.Context, input service.CreatePullRequestInput, pick func([]string, int) []string) (domain.PullRequest, error) {
+// SetUserTags replaces the skill tags of the user.
+func (s *Store) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	return scanUser(s.pool.QueryRow(ctx, `UPDATE users SET tags=$2, updated_at=NOW() WHERE user_id=$1 `+returningUser, userID, textArray(tags)))
+}
+
+// SetUserMaxOpenReviews overrides the team cap on OPEN reviews for the user;
+// nil restores the team default.
+func (s *Store) SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error) {
+	return scanUser(s.pool.QueryRow(ctx, `UPDATE users SET max_open_reviews=$2, updated_at=NOW() WHERE user_id=$1 `+returningUser, userID, maxOpenReviews))
+}
+
+func (s *Store) CreatePullRequest(ctx context.Context, input service.CreatePullRequestInput, pick func([]service.Candidate, []string, int) []string) (domain.PullRequest, error) {
 	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
 	defer rollbackTx(ctx, tx)
 
-	var teamName string
-	row := tx.QueryRow(ctx, `SELECT team_name FROM users WHERE user_id=$1`, input.AuthorID)
-	if scanErr := row.Scan(&teamName); scanErr != nil {
-		if errors.Is(scanErr, pgx.ErrNoRows) {
-			return domain.PullRequest{}, domain.NewNotFoundError("author not found", scanErr)
+	if input.Repository != "" {
+		if err := ensureRepositoryExistsTx(ctx, tx, input.Repository); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+	settings, err := authorTeamSettingsTx(ctx, tx, input.AuthorID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	if input.Repository != "" {
+		if err := ensureTeamLinkedTx(ctx, tx, input.Repository, settings.teamName); err != nil {
+			return domain.PullRequest{}, err
 		}
-		return domain.PullRequest{}, scanErr
 	}
 
-	insertPR := `INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id)
-		VALUES($1, $2, $3)`
-	if _, execErr := tx.Exec(ctx, insertPR, input.PullRequestID, input.PullRequestName, input.AuthorID); execErr != nil {
+	status := domain.StatusOpen
+	if input.Draft {
+		status = domain.StatusDraft
+	}
+
+	insertPR := `INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, repository, required_tags)
+		VALUES($1, $2, $3, $4, $5, $6)`
+	if _, execErr := tx.Exec(ctx, insertPR, input.PullRequestID, input.PullRequestName, input.AuthorID, status, input.Repository, textArray(input.RequiredTags)); execErr != nil {
 		if isUniqueViolation(execErr) {
 			return domain.PullRequest{}, domain.NewPRExistsError(execErr)
 		}
 		return domain.PullRequest{}, execErr
 	}
-
-	excluded := []string{input.AuthorID}
-	candidates, err := s.listActiveTeamMembersTx(ctx, tx, teamName, excluded)
-	if err != nil {
-		return domain.PullRequest{}, err
+	for _, file := range input.ChangedFiles {
+		if _, execErr := tx.Exec(ctx, `INSERT INTO pull_request_files(repository, pull_request_id, path) VALUES($1, $2, $3)`, input.Repository, input.PullRequestID, file); execErr != nil {
+			return domain.PullRequest{}, execErr
+		}
 	}
 
-	reviewers := pick(candidates, 2)
-	for _, reviewerID := range reviewers {
-		if _, execErr := tx.Exec(ctx, `INSERT INTO pull_request_reviewers(pull_request_id, reviewer_id) VALUES($1, $2)`, input.PullRequestID, reviewerID); execErr != nil {
-			return domain.PullRequest{}, execErr
+	var skipped []string
+	if !input.Draft {
+		if skipped, err = s.assignReviewersTx(ctx, tx, input.Repository, input.PullRequestID, input.AuthorID, input.ChangedFiles, input.RequiredTags, settings, pick); err != nil {
+			return domain.PullRequest{}, err
 		}
 	}
 
@@ -139,34 +214,83 @@ func (s *Store) CreatePullRequest(ctx context.Context, input service.CreatePullR
 		return domain.PullRequest{}, err
 	}
 
-	return s.GetPullRequest(ctx, input.PullRequestID)
+	pr, err := s.GetPullRequest(ctx, input.Repository, input.PullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	pr.SkippedAtCap = skipped
+	return pr, nil
 }
 
-func (s *Store) MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	row := s.pool.QueryRow(ctx, `UPDATE pull_requests
-		SET status='MERGED',
-		    merged_at = COALESCE(merged_at, NOW())
-		WHERE pull_request_id=$1
-		RETURNING pull_request_id, pull_request_name, author_id, status, created_at, merged_at`, prID)
-
-	pr, err := scanPullRequestRow(row)
+// ChangePullRequestStatus applies the transition under a row lock. Marking a
+// draft ready assigns reviewers the same way CreatePullRequest does. Repeating
+// a transition to the current status returns the PR with changed set to false.
+func (s *Store) ChangePullRequestStatus(ctx context.Context, repository, prID string, transition service.Transition, pick func([]service.Candidate, []string, int) []string) (domain.PullRequest, bool, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.PullRequest{}, domain.NewNotFoundError("pull request not found", err)
+		return domain.PullRequest{}, false, err
+	}
+	defer rollbackTx(ctx, tx)
+
+	var status, authorID string
+	var requiredTags []string
+	row := tx.QueryRow(ctx, `SELECT status, author_id, required_tags FROM pull_requests WHERE repository=$1 AND pull_request_id=$2 FOR UPDATE`, repository, prID)
+	if scanErr := row.Scan(&status, &authorID, &requiredTags); scanErr != nil {
+		if errors.Is(scanErr, pgx.ErrNoRows) {
+			return domain.PullRequest{}, false, domain.NewNotFoundError("pull request not found", scanErr)
 		}
-		return domain.PullRequest{}, err
+		return domain.PullRequest{}, false, scanErr
 	}
 
-	assigned, err := s.listAssignedReviewers(ctx, pr.PullRequestID)
-	if err != nil {
-		return domain.PullRequest{}, err
+	if !transition.Allows(status) {
+		return domain.PullRequest{}, false, domain.NewInvalidTransitionError(status, transition.To)
+	}
+	if status == transition.To {
+		pr, err := s.GetPullRequest(ctx, repository, prID)
+		return pr, false, err
 	}
-	pr.Assigned = assigned
 
-	return pr, nil
+	if err := recordEventsTx(ctx, tx, domain.AuditEvent{Reason: transition.Reason, PullRequestID: prID, Repository: repository}); err != nil {
+		return domain.PullRequest{}, false, err
+	}
+
+	var skipped []string
+	if status == domain.StatusDraft && transition.To == domain.StatusOpen {
+		settings, err := authorTeamSettingsTx(ctx, tx, authorID)
+		if err != nil {
+			return domain.PullRequest{}, false, err
+		}
+		files, err := listPullRequestFilesTx(ctx, tx, repository, prID)
+		if err != nil {
+			return domain.PullRequest{}, false, err
+		}
+		if skipped, err = s.assignReviewersTx(ctx, tx, repository, prID, authorID, files, requiredTags, settings, pick); err != nil {
+			return domain.PullRequest{}, false, err
+		}
+	}
+
+	if _, execErr := tx.Exec(ctx, `UPDATE pull_requests
+		SET status=$3,
+		    merged_at = CASE WHEN $4 THEN NOW() ELSE merged_at END,
+		    closed_at = CASE WHEN $5 THEN NOW() ELSE NULL END
+		WHERE repository=$1 AND pull_request_id=$2`,
+		repository, prID, transition.To, transition.To == domain.StatusMerged, transition.To == domain.StatusClosed); execErr != nil {
+		return domain.PullRequest{}, false, execErr
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, false, err
+	}
+
+	pr, err := s.GetPullRequest(ctx, repository, prID)
+	if err != nil {
+		return domain.PullRequest{}, false, err
+	}
+	pr.SkippedAtCap = skipped
+	return pr, true, nil
 }
 
-func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string, pick func([]string) (string, bool)) (domain.PullRequest, string, error) {
+func (s *Store) ReassignReviewer(ctx context.Context, repository, prID, oldUserID string, pick func([]service.Candidate) (string, bool)) (domain.PullRequest, string, error) {
 	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return domain.PullRequest{}, "", err
@@ -174,7 +298,7 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string, pi
 	defer rollbackTx(ctx, tx)
 
 	var status string
-	row := tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE pull_request_id=$1 FOR UPDATE`, prID)
+	row := tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE repository=$1 AND pull_request_id=$2 FOR UPDATE`, repository, prID)
 	if scanErr := row.Scan(&status); scanErr != nil {
 		if errors.Is(scanErr, pgx.ErrNoRows) {
 			return domain.PullRequest{}, "", domain.NewNotFoundError("pull request not found", scanErr)
@@ -182,12 +306,15 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string, pi
 		return domain.PullRequest{}, "", scanErr
 	}
 
-	if status == "MERGED" {
+	switch status {
+	case domain.StatusMerged:
 		return domain.PullRequest{}, "", domain.NewPRMergedError()
+	case domain.StatusClosed:
+		return domain.PullRequest{}, "", domain.NewPRClosedError()
 	}
 
 	var reviewerTeam string
-	row = tx.QueryRow(ctx, `SELECT team_name FROM users WHERE user_id=$1`, oldUserID)
+	row = tx.QueryRow(ctx, `SELECT COALESCE(team_name, '') FROM users WHERE user_id=$1`, oldUserID)
 	if scanErr := row.Scan(&reviewerTeam); scanErr != nil {
 		if errors.Is(scanErr, pgx.ErrNoRows) {
 			return domain.PullRequest{}, "", domain.NewNotFoundError("reviewer not found", scanErr)
@@ -196,42 +323,59 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string, pi
 	}
 
 	var exists int
-	if scanErr := tx.QueryRow(ctx, `SELECT 1 FROM pull_request_reviewers WHERE pull_request_id=$1 AND reviewer_id=$2`, prID, oldUserID).Scan(&exists); scanErr != nil {
+	if scanErr := tx.QueryRow(ctx, `SELECT 1 FROM pull_request_reviewers WHERE repository=$1 AND pull_request_id=$2 AND reviewer_id=$3`, repository, prID, oldUserID).Scan(&exists); scanErr != nil {
 		if errors.Is(scanErr, pgx.ErrNoRows) {
 			return domain.PullRequest{}, "", domain.NewNotAssignedError()
 		}
 		return domain.PullRequest{}, "", scanErr
 	}
 
-	assigned, err := s.listAssignedReviewersTx(ctx, tx, prID)
+	var authorID string
+	if scanErr := tx.QueryRow(ctx, `SELECT author_id FROM pull_requests WHERE repository=$1 AND pull_request_id=$2`, repository, prID).Scan(&authorID); scanErr != nil {
+		return domain.PullRequest{}, "", scanErr
+	}
+
+	assigned, err := s.listAssignedReviewersTx(ctx, tx, repository, prID)
 	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
 
-	exclude := append([]string{oldUserID}, assigned...)
+	exclude := append([]string{oldUserID, authorID}, assigned...)
 	candidates, err := s.listActiveTeamMembersTx(ctx, tx, reviewerTeam, exclude)
 	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
 
-	chosen, ok := pick(candidates)
+	available, skipped := service.WithinCapacity(candidates)
+	chosen, ok := pick(available)
 	if !ok {
+		if len(skipped) > 0 {
+			return domain.PullRequest{}, "", domain.NewCapReachedError()
+		}
 		return domain.PullRequest{}, "", domain.NewNoCandidateError()
 	}
 
-	if _, execErr := tx.Exec(ctx, `DELETE FROM pull_request_reviewers WHERE pull_request_id=$1 AND reviewer_id=$2`, prID, oldUserID); execErr != nil {
+	if _, execErr := tx.Exec(ctx, `DELETE FROM pull_request_reviewers WHERE repository=$1 AND pull_request_id=$2 AND reviewer_id=$3`, repository, prID, oldUserID); execErr != nil {
+		return domain.PullRequest{}, "", execErr
+	}
+
+	if _, execErr := tx.Exec(ctx, `INSERT INTO pull_request_reviewers(repository, pull_request_id, reviewer_id) VALUES($1, $2, $3)`, repository, prID, chosen); execErr != nil {
 		return domain.PullRequest{}, "", execErr
 	}
 
-	if _, execErr := tx.Exec(ctx, `INSERT INTO pull_request_reviewers(pull_request_id, reviewer_id) VALUES($1, $2)`, prID, chosen); execErr != nil {
+	if _, execErr := tx.Exec(ctx, `INSERT INTO reviewer_reassignments(repository, pull_request_id, old_reviewer_id, new_reviewer_id) VALUES($1, $2, $3, $4)`, repository, prID, oldUserID, chosen); execErr != nil {
 		return domain.PullRequest{}, "", execErr
 	}
 
+	if err := recordEventsTx(ctx, tx, domain.AuditEvent{Reason: domain.AuditReassigned, PullRequestID: prID, Repository: repository, OldReviewerID: oldUserID, NewReviewerID: chosen}); err != nil {
+		return domain.PullRequest{}, "", err
+	}
+
 	if commitErr := tx.Commit(ctx); commitErr != nil {
 		return domain.PullRequest{}, "", commitErr
 	}
 
-	pr, err := s.GetPullRequest(ctx, prID)
+	pr, err := s.GetPullRequest(ctx, repository, prID)
 	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
@@ -239,23 +383,44 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string, pi
 	return pr, chosen, nil
 }
 
-func (s *Store) GetUserReviews(ctx context.Context, userID string) (domain.UserReviews, error) {
-	rows, err := s.pool.Query(ctx, `SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+// GetUserReviews returns a page of PRs the user reviews, newest first. One
+// extra row is fetched to tell whether another page follows.
+func (s *Store) GetUserReviews(ctx context.Context, userID string, filter service.UserReviewsFilter) (domain.UserReviews, error) {
+	var afterTime *time.Time
+	var afterRepository, afterID string
+	if filter.After != nil {
+		afterTime, afterRepository, afterID = &filter.After.CreatedAt, filter.After.Repository, filter.After.ID
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT pr.pull_request_id, pr.repository, pr.pull_request_name, pr.author_id, pr.status, pr.created_at
 		FROM pull_request_reviewers r
-		JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id
 		WHERE r.reviewer_id=$1
-		ORDER BY pr.created_at DESC`, userID)
+		  AND (COALESCE(cardinality($2::text[]), 0) = 0 AND pr.status <> 'CLOSED' OR pr.status::text = ANY($2))
+		  AND ($3::timestamptz IS NULL OR pr.created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR pr.created_at < $4)
+		  AND ($5::timestamptz IS NULL OR (pr.created_at, pr.repository, pr.pull_request_id) < ($5, $6::text, $7::text))
+		ORDER BY pr.created_at DESC, pr.repository DESC, pr.pull_request_id DESC
+		LIMIT $8`,
+		userID, filter.Statuses, filter.CreatedFrom, filter.CreatedTo, afterTime, afterRepository, afterID, filter.Limit+1)
 	if err != nil {
 		return domain.UserReviews{}, err
 	}
 	defer rows.Close()
 
 	var prs []domain.PullRequestShort
+	var last service.Cursor
+	result := domain.UserReviews{UserID: userID}
 	for rows.Next() {
+		if len(prs) == filter.Limit {
+			result.NextCursor = last.Encode()
+			break
+		}
 		var item domain.PullRequestShort
-		if err := rows.Scan(&item.PullRequestID, &item.PullRequestName, &item.AuthorID, &item.Status); err != nil {
+		if err := rows.Scan(&item.PullRequestID, &item.Repository, &item.PullRequestName, &item.AuthorID, &item.Status, &last.CreatedAt); err != nil {
 			return domain.UserReviews{}, err
 		}
+		last.Repository, last.ID = item.Repository, item.PullRequestID
 		prs = append(prs, item)
 	}
 
@@ -263,11 +428,77 @@ func (s *Store) GetUserReviews(ctx context.Context, userID string) (domain.UserR
 		return domain.UserReviews{}, err
 	}
 
-	return domain.UserReviews{UserID: userID, PullRequests: prs}, nil
+	result.PullRequests = prs
+	return result, nil
 }
 
 // Helper functions
 
+// teamReviewerSettings is the reviewer configuration of a PR author's team.
+type teamReviewerSettings struct {
+	teamName          string
+	requiredReviewers int
+	minReviewers      int
+}
+
+func authorTeamSettingsTx(ctx context.Context, tx pgx.Tx, authorID string) (teamReviewerSettings, error) {
+	var settings teamReviewerSettings
+	row := tx.QueryRow(ctx, `SELECT u.team_name, t.required_reviewers, t.min_reviewers
+		FROM users u
+		JOIN teams t ON t.team_name = u.team_name
+		WHERE u.user_id=$1`, authorID)
+	if err := row.Scan(&settings.teamName, &settings.requiredReviewers, &settings.minReviewers); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return teamReviewerSettings{}, domain.NewNotFoundError("author not found", err)
+		}
+		return teamReviewerSettings{}, err
+	}
+	return settings, nil
+}
+
+// assignReviewersTx picks a reviewer for every ownership rule of the
+// repository matching the changed files, then tops the PR up with teammates
+// of the author below their open review cap, passing the required tags on to
+// pick. It fails if fewer than the team minimum are available, and
+// otherwise returns the teammates skipped at their cap when the PR got fewer
+// than the required reviewers.
+func (s *Store) assignReviewersTx(ctx context.Context, tx pgx.Tx, repository, prID, authorID string, files, requiredTags []string, settings teamReviewerSettings, pick func([]service.Candidate, []string, int) []string) ([]string, error) {
+	owners, err := s.ownerCandidatesTx(ctx, tx, repository, files, authorID)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.listActiveTeamMembersTx(ctx, tx, settings.teamName, []string{authorID})
+	if err != nil {
+		return nil, err
+	}
+	available, skipped := service.WithinCapacity(candidates)
+
+	reviewers, err := service.PickWithOwners(owners, available, requiredTags, settings.requiredReviewers, pick)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviewers) < settings.minReviewers {
+		if len(skipped) > 0 {
+			return nil, domain.NewCapReachedError()
+		}
+		return nil, domain.NewNotEnoughReviewersError()
+	}
+	events := make([]domain.AuditEvent, 0, len(reviewers))
+	for _, reviewerID := range reviewers {
+		if _, err := tx.Exec(ctx, `INSERT INTO pull_request_reviewers(repository, pull_request_id, reviewer_id) VALUES($1, $2, $3)`, repository, prID, reviewerID); err != nil {
+			return nil, err
+		}
+		events = append(events, domain.AuditEvent{Reason: domain.AuditAssigned, PullRequestID: prID, Repository: repository, NewReviewerID: reviewerID})
+	}
+	if err := recordEventsTx(ctx, tx, events...); err != nil {
+		return nil, err
+	}
+	if len(reviewers) >= settings.requiredReviewers {
+		return nil, nil
+	}
+	return skipped, nil
+}
+
 func (s *Store) ensureTeamExists(ctx context.Context, teamName string) error {
 	row := s.pool.QueryRow(ctx, "SELECT team_name FROM teams WHERE team_name=$1", teamName)
 	var name string
@@ -281,7 +512,11 @@ func (s *Store) ensureTeamExists(ctx context.Context, teamName string) error {
 }
 
 func (s *Store) listTeamMembers(ctx context.Context, teamName string) ([]domain.TeamMember, error) {
-	rows, err := s.pool.Query(ctx, `SELECT user_id, username, is_active FROM users WHERE team_name=$1 ORDER BY username`, teamName)
+	rows, err := s.pool.Query(ctx, `SELECT u.user_id, u.username, u.is_active, u.tags, u.max_open_reviews,
+		       (SELECT COUNT(*) FROM pull_request_reviewers r
+		        JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id
+		        WHERE r.reviewer_id = u.user_id AND pr.status = 'OPEN')
+		FROM users u WHERE u.team_name=$1 ORDER BY u.username`, teamName)
 	if err != nil {
 		return nil, err
 	}
@@ -290,9 +525,12 @@ func (s *Store) listTeamMembers(ctx context.Context, teamName string) ([]domain.
 	var members []domain.TeamMember
 	for rows.Next() {
 		var member domain.TeamMember
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Tags, &member.MaxOpenReviews, &member.OpenReviews); err != nil {
 			return nil, err
 		}
+		if len(member.Tags) == 0 {
+			member.Tags = nil
+		}
 		members = append(members, member)
 	}
 
@@ -303,41 +541,73 @@ func (s *Store) listTeamMembers(ctx context.Context, teamName string) ([]domain.
 	return members, nil
 }
 
-func (s *Store) listActiveTeamMembersTx(ctx context.Context, tx pgx.Tx, teamName string, excludes []string) ([]string, error) {
-	exclusion := make(map[string]struct{}, len(excludes))
-	for _, id := range excludes {
-		if id != "" {
-			exclusion[id] = struct{}{}
-		}
-	}
+// listActiveTeamMembersTx returns active members of the team with the number of
+// OPEN pull requests each of them currently reviews.
+func (s *Store) listActiveTeamMembersTx(ctx context.Context, tx pgx.Tx, teamName string, excludes []string) ([]service.Candidate, error) {
+	return s.listActiveUsersTx(ctx, tx, teamName, nil, excludes)
+}
 
-	rows, err := tx.Query(ctx, `SELECT user_id FROM users WHERE team_name=$1 AND is_active=true`, teamName)
+// listActiveUsersTx returns active members of the team and active users
+// listed in userIDs, with their OPEN review counts, caps and tags, ordered by
+// id. Users with an unavailability period covering now are left out; users at
+// their cap are kept for the caller to filter with service.WithinCapacity.
+func (s *Store) listActiveUsersTx(ctx context.Context, tx pgx.Tx, teamName string, userIDs, excludes []string) ([]service.Candidate, error) {
+	rows, err := tx.Query(ctx, `SELECT u.user_id, COUNT(pr.pull_request_id), COALESCE(u.max_open_reviews, t.max_open_reviews, 0), u.tags
+		FROM users u
+		LEFT JOIN teams t ON t.team_name = u.team_name
+		LEFT JOIN pull_request_reviewers r ON r.reviewer_id = u.user_id
+		LEFT JOIN pull_requests pr ON pr.repository = r.repository AND pr.pull_request_id = r.pull_request_id AND pr.status = 'OPEN'
+		WHERE (u.team_name=$1 OR u.user_id = ANY($2)) AND u.is_active=true
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_unavailability a
+		      WHERE a.user_id = u.user_id AND a.starts_at <= NOW() AND a.ends_at > NOW())
+		GROUP BY u.user_id, t.max_open_reviews
+		ORDER BY u.user_id`, teamName, userIDs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var ids []string
+	var candidates []service.Candidate
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var candidate service.Candidate
+		if err := rows.Scan(&candidate.UserID, &candidate.OpenReviews, &candidate.MaxOpenReviews, &candidate.Tags); err != nil {
 			return nil, err
 		}
-		if _, skip := exclusion[id]; skip {
-			continue
+		if len(candidate.Tags) == 0 {
+			candidate.Tags = nil
 		}
-		ids = append(ids, id)
+		candidates = append(candidates, candidate)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return ids, nil
+	return excludeCandidates(candidates, excludes), nil
 }
 
-func (s *Store) listAssignedReviewers(ctx context.Context, prID string) ([]string, error) {
-	rows, err := s.pool.Query(ctx, `SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id=$1 ORDER BY reviewer_id`, prID)
+// excludeCandidates returns candidates without the excluded ids, preserving order.
+func excludeCandidates(candidates []service.Candidate, excludes []string) []service.Candidate {
+	exclusion := make(map[string]struct{}, len(excludes))
+	for _, id := range excludes {
+		if id != "" {
+			exclusion[id] = struct{}{}
+		}
+	}
+
+	var filtered []service.Candidate
+	for _, candidate := range candidates {
+		if _, skip := exclusion[candidate.UserID]; skip {
+			continue
+		}
+		filtered = append(filtered, candidate)
+	}
+	return filtered
+}
+
+func (s *Store) listAssignedReviewers(ctx context.Context, repository, prID string) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `SELECT reviewer_id FROM pull_request_reviewers WHERE repository=$1 AND pull_request_id=$2 ORDER BY reviewer_id`, repository, prID)
 	if err != nil {
 		return nil, err
 	}
@@ -354,8 +624,8 @@ func (s *Store) listAssignedReviewers(ctx context.Context, prID string) ([]strin
 	return reviewers, rows.Err()
 }
 
-func (s *Store) listAssignedReviewersTx(ctx context.Context, tx pgx.Tx, prID string) ([]string, error) {
-	rows, err := tx.Query(ctx, `SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id=$1`, prID)
+func (s *Store) listAssignedReviewersTx(ctx context.Context, tx pgx.Tx, repository, prID string) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT reviewer_id FROM pull_request_reviewers WHERE repository=$1 AND pull_request_id=$2`, repository, prID)
 	if err != nil {
 		return nil, err
 	}
@@ -372,9 +642,9 @@ func (s *Store) listAssignedReviewersTx(ctx context.Context, tx pgx.Tx, prID str
 	return reviewers, rows.Err()
 }
 
-func (s *Store) GetPullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	row := s.pool.QueryRow(ctx, `SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-		FROM pull_requests WHERE pull_request_id=$1`, prID)
+func (s *Store) GetPullRequest(ctx context.Context, repository, prID string) (domain.PullRequest, error) {
+	row := s.pool.QueryRow(ctx, `SELECT pull_request_id, repository, pull_request_name, author_id, status, created_at, merged_at, closed_at, required_tags
+		FROM pull_requests WHERE repository=$1 AND pull_request_id=$2`, repository, prID)
 	pr, err := scanPullRequestRow(row)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -383,7 +653,7 @@ func (s *Store) GetPullRequest(ctx context.Context, prID string) (domain.PullReq
 		return domain.PullRequest{}, err
 	}
 
-	reviewers, err := s.listAssignedReviewers(ctx, prID)
+	reviewers, err := s.listAssignedReviewers(ctx, repository, prID)
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
@@ -393,16 +663,47 @@ func (s *Store) GetPullRequest(ctx context.Context, prID string) (domain.PullReq
 
 func scanPullRequestRow(row pgx.Row) (domain.PullRequest, error) {
 	var pr domain.PullRequest
-	var mergedAt sql.NullTime
-	if err := row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt); err != nil {
+	var mergedAt, closedAt sql.NullTime
+	if err := row.Scan(&pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, &closedAt, &pr.RequiredTags); err != nil {
 		return domain.PullRequest{}, err
 	}
+	if len(pr.RequiredTags) == 0 {
+		pr.RequiredTags = nil
+	}
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
 	return pr, nil
 }
 
+const returningUser = `RETURNING user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews`
+
+func scanUser(row pgx.Row) (domain.User, error) {
+	var user domain.User
+	if err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, domain.NewNotFoundError("user not found", err)
+		}
+		return domain.User{}, err
+	}
+	if len(user.Tags) == 0 {
+		user.Tags = nil
+	}
+	return user, nil
+}
+
+// textArray returns values as a non-nil slice, since a nil one is sent as NULL
+// to TEXT[] columns that are NOT NULL.
+func textArray(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 func isUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -411,11 +712,19 @@ func isUniqueViolation(err error) bool {
 	return false
 }
 
+func isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23514"
+	}
+	return false
+}
+
 func rollbackTx(ctx context.Context, tx pgx.Tx) {
 	if tx == nil {
 		return
 	}
 	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-		_ = err // rollback is best-effort; nothing else to do here
+		logging.FromContext(ctx).Warn("rollback transaction", slog.String("error", err.Error()))
 	}
 }
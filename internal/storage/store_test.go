@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -60,8 +61,8 @@ func TestStoreCreateTeamSuccess(t *testing.T) {
 
 	pool.queryFunc = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 		rows := [][]any{
-			{"u1", "Alice", true},
-			{"u2", "Bob", false},
+			{"u1", "Alice", true, []string{"go"}, nil, 2},
+			{"u2", "Bob", false, []string{}, 5, 0},
 		}
 		return &fakeRows{data: rows}, nil
 	}
@@ -113,7 +114,7 @@ func TestStoreCreatePullRequestAssignsReviewers(t *testing.T) {
 		AuthorID:        "author",
 	}
 
-	candidateRows := &fakeRows{data: [][]any{{"author"}, {"u2"}, {"u3"}}}
+	candidateRows := &fakeRows{data: [][]any{{"author", 0, 0, []string{}}, {"u2", 3, 5, []string{"go"}}, {"u3", 1, 0, []string{}}}}
 	tx := &fakeTx{}
 	tx.queryRowFunc = func(ctx context.Context, sql string, args ...any) pgx.Row {
 		if strings.Contains(sql, "FROM users") {
@@ -141,11 +142,11 @@ func TestStoreCreatePullRequestAssignsReviewers(t *testing.T) {
 		if strings.Contains(sql, "FROM pull_requests") {
 			return fakeRow{scan: func(dest ...any) error {
 				*(dest[0].(*string)) = input.PullRequestID
-				*(dest[1].(*string)) = input.PullRequestName
-				*(dest[2].(*string)) = input.AuthorID
-				*(dest[3].(*string)) = "OPEN"
+				*(dest[2].(*string)) = input.PullRequestName
+				*(dest[3].(*string)) = input.AuthorID
+				*(dest[4].(*string)) = "OPEN"
 				t := time.Now()
-				*(dest[4].(*time.Time)) = t
+				*(dest[5].(*time.Time)) = t
 				return nil
 			}}
 		}
@@ -156,22 +157,116 @@ func TestStoreCreatePullRequestAssignsReviewers(t *testing.T) {
 	}
 
 	store := New(pool)
-	var captured []string
-	pr, err := store.CreatePullRequest(ctx, input, func(ids []string, limit int) []string {
-		captured = append([]string(nil), ids...)
+	var captured []service.Candidate
+	pr, err := store.CreatePullRequest(ctx, input, func(candidates []service.Candidate, _ []string, limit int) []string {
+		captured = append([]service.Candidate(nil), candidates...)
 		return []string{"u2", "u3"}
 	})
 	if err != nil {
 		t.Fatalf("CreatePullRequest error: %v", err)
 	}
-	if contains(captured, "author") {
-		t.Fatalf("author should be excluded from candidates: %v", captured)
+	want := []service.Candidate{{UserID: "u2", OpenReviews: 3, MaxOpenReviews: 5, Tags: []string{"go"}}, {UserID: "u3", OpenReviews: 1}}
+	if !reflect.DeepEqual(captured, want) {
+		t.Fatalf("author should be excluded and load passed to picker: %v", captured)
 	}
 	if len(pr.Assigned) != 2 {
 		t.Fatalf("expected two reviewers, got %v", pr.Assigned)
 	}
 }
 
+func TestStoreCreatePullRequestUsesTeamReviewerSettings(t *testing.T) {
+	ctx := context.Background()
+	tx := &fakeTx{}
+	tx.queryRowFunc = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		if strings.Contains(sql, "FROM users") {
+			return fakeRow{scan: func(dest ...any) error {
+				*(dest[0].(*string)) = "infra"
+				*(dest[1].(*int)) = 3
+				*(dest[2].(*int)) = 3
+				return nil
+			}}
+		}
+		return fakeRow{scan: func(dest ...any) error { return fmt.Errorf("unexpected query row: %s", sql) }}
+	}
+	tx.execFunc = func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+		return pgconn.CommandTag{}, nil
+	}
+	tx.queryFunc = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		return &fakeRows{data: [][]any{{"u2", 0, 0, []string{}}, {"u3", 0, 0, []string{}}}}, nil
+	}
+	committed := false
+	tx.commitFunc = func(context.Context) error {
+		committed = true
+		return nil
+	}
+
+	pool := &fakePool{
+		beginTxFunc: func(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}
+	store := New(pool)
+
+	var requestedLimit int
+	_, err := store.CreatePullRequest(ctx, service.CreatePullRequestInput{PullRequestID: "pr-1", PullRequestName: "Deploy", AuthorID: "author"},
+		func(candidates []service.Candidate, _ []string, limit int) []string {
+			requestedLimit = limit
+			return []string{"u2", "u3"}
+		})
+	if requestedLimit != 3 {
+		t.Fatalf("expected picker to be asked for 3 reviewers, got %d", requestedLimit)
+	}
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrCodeNoCandidate {
+		t.Fatalf("expected NO_CANDIDATE when below minimum, got %v", err)
+	}
+	if committed {
+		t.Fatalf("transaction must not be committed below the minimum")
+	}
+}
+
+func TestStoreCreatePullRequestReportsCapReached(t *testing.T) {
+	ctx := context.Background()
+	tx := &fakeTx{}
+	tx.queryRowFunc = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		if strings.Contains(sql, "FROM users") {
+			return fakeRow{scan: func(dest ...any) error {
+				*(dest[0].(*string)) = "infra"
+				*(dest[1].(*int)) = 2
+				*(dest[2].(*int)) = 2
+				return nil
+			}}
+		}
+		return fakeRow{scan: func(dest ...any) error { return fmt.Errorf("unexpected query row: %s", sql) }}
+	}
+	tx.execFunc = func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+		return pgconn.CommandTag{}, nil
+	}
+	tx.queryFunc = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		return &fakeRows{data: [][]any{{"u2", 4, 4, []string{}}, {"u3", 0, 0, []string{}}}}, nil
+	}
+	pool := &fakePool{
+		beginTxFunc: func(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}
+	store := New(pool)
+
+	var offered []service.Candidate
+	_, err := store.CreatePullRequest(ctx, service.CreatePullRequestInput{PullRequestID: "pr-1", PullRequestName: "Deploy", AuthorID: "author"},
+		func(candidates []service.Candidate, _ []string, limit int) []string {
+			offered = candidates
+			ids := make([]string, 0, len(candidates))
+			for _, c := range candidates {
+				ids = append(ids, c.UserID)
+			}
+			return ids
+		})
+	if len(offered) != 1 || offered[0].UserID != "u3" {
+		t.Fatalf("a user at the cap must not be offered: %v", offered)
+	}
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrCodeCapReached {
+		t.Fatalf("expected REVIEW_CAP_REACHED, got %v", err)
+	}
+}
+
 func TestStoreReassignReviewerMerged(t *testing.T) {
 	ctx := context.Background()
 	tx := &fakeTx{}
@@ -191,7 +286,7 @@ func TestStoreReassignReviewerMerged(t *testing.T) {
 	}
 	store := New(pool)
 
-	_, _, err := store.ReassignReviewer(ctx, "pr-1", "old", func([]string) (string, bool) {
+	_, _, err := store.ReassignReviewer(ctx, "", "pr-1", "old", func([]service.Candidate) (string, bool) {
 		return "", false
 	})
 	if err == nil {
@@ -203,6 +298,53 @@ func TestStoreReassignReviewerMerged(t *testing.T) {
 	}
 }
 
+func TestStoreGetPullRequestStatsNotFound(t *testing.T) {
+	ctx := context.Background()
+	pool := &fakePool{}
+	pool.queryRowFunc = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		return fakeRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+	}
+	store := New(pool)
+
+	_, err := store.GetPullRequestStats(ctx, "", "missing")
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrCodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+}
+
+func TestStoreDeactivateUsersUnknownUser(t *testing.T) {
+	ctx := context.Background()
+	tx := &fakeTx{}
+	tx.queryFunc = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		if strings.Contains(sql, "UPDATE users") {
+			return &fakeRows{data: [][]any{{"u1"}}}, nil
+		}
+		return nil, fmt.Errorf("unexpected query: %s", sql)
+	}
+	committed := false
+	tx.commitFunc = func(context.Context) error {
+		committed = true
+		return nil
+	}
+
+	pool := &fakePool{
+		beginTxFunc: func(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}
+	store := New(pool)
+
+	_, err := store.DeactivateUsers(ctx, service.DeactivateUsersInput{UserIDs: []string{"u1", "ghost"}}, func([]service.Candidate) (string, bool) {
+		return "", false
+	})
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrCodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+	if committed {
+		t.Fatalf("transaction must not be committed when a user is missing")
+	}
+}
+
 // --- test fakes ---
 
 type fakePool struct {
@@ -334,8 +476,19 @@ func (r *fakeRows) Scan(dest ...any) error {
 			*v = row[i].(string)
 		case *bool:
 			*v = row[i].(bool)
+		case *int:
+			*v = row[i].(int)
 		case *time.Time:
 			*v = row[i].(time.Time)
+		case *[]string:
+			*v = row[i].([]string)
+		case **int:
+			if row[i] == nil {
+				*v = nil
+			} else {
+				n := row[i].(int)
+				*v = &n
+			}
 		default:
 			return fmt.Errorf("unsupported scan dest")
 		}
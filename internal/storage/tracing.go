@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/GolovachevS/pr-reviewer-service/internal/storage"
+
+// Option configures a Store.
+type Option func(*options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+}
+
+// WithTracerProvider records spans with tp instead of the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+// tracedPool records a span per statement and per transaction. Statements run
+// in a transaction are children of the transaction span, so a slow lock wait
+// and the queries around it show up separately.
+type tracedPool struct {
+	pool   pgxPool
+	tracer trace.Tracer
+}
+
+func (p tracedPool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	txCtx, span := p.tracer.Start(ctx, "postgres.tx", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(dbSystem))
+	tx, err := p.pool.BeginTx(txCtx, txOptions)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedTx{Tx: tx, tracer: p.tracer, span: span}, nil
+}
+
+func (p tracedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, span := startStatement(ctx, p.tracer, sql)
+	rows, err := p.pool.Query(ctx, sql, args...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (p tracedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, span := startStatement(ctx, p.tracer, sql)
+	return tracedRow{row: p.pool.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (p tracedPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, span := startStatement(ctx, p.tracer, sql)
+	tag, err := p.pool.Exec(ctx, sql, args...)
+	endSpan(span, err)
+	return tag, err
+}
+
+// tracedTx parents statement spans to the transaction span, which ends on
+// commit or on the first rollback.
+type tracedTx struct {
+	pgx.Tx
+	tracer trace.Tracer
+	span   trace.Span
+	done   bool
+}
+
+func (t *tracedTx) statementContext(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, t.span)
+}
+
+func (t *tracedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, span := startStatement(t.statementContext(ctx), t.tracer, sql)
+	rows, err := t.Tx.Query(ctx, sql, args...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (t *tracedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, span := startStatement(t.statementContext(ctx), t.tracer, sql)
+	return tracedRow{row: t.Tx.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (t *tracedTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, span := startStatement(t.statementContext(ctx), t.tracer, sql)
+	tag, err := t.Tx.Exec(ctx, sql, args...)
+	endSpan(span, err)
+	return tag, err
+}
+
+func (t *tracedTx) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(ctx)
+	t.end("commit", err)
+	return err
+}
+
+func (t *tracedTx) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(ctx)
+	if errors.Is(err, pgx.ErrTxClosed) {
+		return err
+	}
+	t.end("rollback", err)
+	return err
+}
+
+func (t *tracedTx) end(outcome string, err error) {
+	if t.done {
+		return
+	}
+	t.done = true
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", outcome))
+	endSpan(t.span, err)
+}
+
+// tracedRows ends the statement span once the rows are read or closed.
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+	done bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.end()
+}
+
+func (r *tracedRows) end() {
+	if r.done {
+		return
+	}
+	r.done = true
+	endSpan(r.span, r.Rows.Err())
+}
+
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.span.End()
+		return err
+	}
+	endSpan(r.span, err)
+	return err
+}
+
+var dbSystem = attribute.String("db.system", "postgresql")
+
+// startStatement starts a span named after the SQL operation, such as
+// "postgres.SELECT", with the statement text attached.
+func startStatement(ctx context.Context, tracer trace.Tracer, sql string) (context.Context, trace.Span) {
+	operation := "QUERY"
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	return tracer.Start(ctx, "postgres."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			dbSystem,
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", sql),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
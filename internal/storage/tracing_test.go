@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestStoreTracesTransactionStatements(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, root := tp.Tracer("test").Start(context.Background(), "root")
+
+	tx := &fakeTx{}
+	tx.queryRowFunc = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		if strings.Contains(sql, "status FROM pull_requests") {
+			return fakeRow{scan: func(dest ...any) error {
+				*(dest[0].(*string)) = "MERGED"
+				return nil
+			}}
+		}
+		return fakeRow{scan: func(dest ...any) error { return fmt.Errorf("unexpected query row: %s", sql) }}
+	}
+	pool := &fakePool{
+		beginTxFunc: func(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+		queryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return fakeRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}
+	store := New(pool, WithTracerProvider(tp))
+
+	_, _, _ = store.ReassignReviewer(ctx, "", "pr-1", "old", func([]service.Candidate) (string, bool) { return "", false })
+	_, _ = store.GetPullRequestStats(ctx, "", "missing")
+	root.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want tx, its SELECT, a pool SELECT and root", len(spans))
+	}
+	lock, txSpan, stats := spans[0], spans[1], spans[2]
+
+	if txSpan.Name() != "postgres.tx" || spanAttr(txSpan, "db.transaction.outcome") != "rollback" ||
+		txSpan.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Fatalf("unexpected transaction span %q %v", txSpan.Name(), txSpan.Attributes())
+	}
+	if lock.Name() != "postgres.SELECT" || !strings.Contains(spanAttr(lock, "db.statement"), "FOR UPDATE") ||
+		lock.Parent().SpanID() != txSpan.SpanContext().SpanID() {
+		t.Fatalf("lock statement is not a child of the transaction: %q %v", lock.Name(), lock.Attributes())
+	}
+	if stats.Name() != "postgres.SELECT" || stats.Parent().SpanID() != root.SpanContext().SpanID() ||
+		stats.Status().Code == codes.Error {
+		t.Fatalf("unexpected pool statement span %q %v %v", stats.Name(), stats.Parent(), stats.Status())
+	}
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) CreateWebhook(ctx context.Context, hook domain.Webhook) (domain.Webhook, error) {
+	err := s.pool.QueryRow(ctx, `INSERT INTO webhooks(url, secret, event_types) VALUES($1, $2, $3)
+		RETURNING id, created_at`, hook.URL, hook.Secret, hook.EventTypes).Scan(&hook.ID, &hook.CreatedAt)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	return hook, nil
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := s.pool.Query(ctx, `SELECT id, url, secret, event_types, created_at FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := make([]domain.Webhook, 0)
+	for rows.Next() {
+		var hook domain.Webhook
+		if err := rows.Scan(&hook.ID, &hook.URL, &hook.Secret, &hook.EventTypes, &hook.CreatedAt); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+// DeleteWebhook removes the webhook; its deliveries are dropped by the
+// cascading foreign key.
+func (s *Store) DeleteWebhook(ctx context.Context, id int64) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM webhooks WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFoundError("webhook not found", nil)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns deliveries of the webhook, newest first.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, filter service.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	var exists int
+	if err := s.pool.QueryRow(ctx, `SELECT 1 FROM webhooks WHERE id=$1`, filter.WebhookID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("webhook not found", err)
+		}
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, deliveryColumns+`
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND ($2::text = '' OR d.status = $2)
+		ORDER BY d.id DESC
+		LIMIT $3`, filter.WebhookID, filter.Status, filter.Limit)
+	return scanDeliveries(rows, err)
+}
+
+// ClaimWebhookDeliveries leases up to limit pending deliveries that are due at
+// now by moving their next attempt to leaseUntil. Rows locked by another
+// dispatcher are skipped, so replicas never send the same delivery at once.
+func (s *Store) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := s.pool.Query(ctx, `WITH due AS (
+		    SELECT id FROM webhook_deliveries
+		    WHERE status = 'PENDING' AND next_attempt_at <= $1
+		    ORDER BY next_attempt_at, id
+		    LIMIT $3
+		    FOR UPDATE SKIP LOCKED
+		), claimed AS (
+		    UPDATE webhook_deliveries d SET next_attempt_at = $2
+		    FROM due WHERE d.id = due.id
+		    RETURNING d.*
+		)
+		`+deliveryColumns+`
+		FROM claimed d JOIN webhooks w ON w.id = d.webhook_id
+		ORDER BY d.id`, now, leaseUntil, limit)
+	return scanDeliveries(rows, err)
+}
+
+// CompleteWebhookDelivery marks the delivery as delivered at the given time.
+func (s *Store) CompleteWebhookDelivery(ctx context.Context, id int64, at time.Time) error {
+	return s.updateDelivery(ctx, `UPDATE webhook_deliveries
+		SET status = 'DELIVERED', attempts = attempts + 1, delivered_at = $2, last_error = ''
+		WHERE id = $1`, id, at)
+}
+
+// FailWebhookDelivery records a failed attempt. The delivery is retried at
+// retryAt, or becomes DEAD when retryAt is nil.
+func (s *Store) FailWebhookDelivery(ctx context.Context, id int64, retryAt *time.Time, lastError string) error {
+	return s.updateDelivery(ctx, `UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_error = $3,
+		    status = CASE WHEN $2::timestamptz IS NULL THEN 'DEAD' ELSE status END,
+		    next_attempt_at = COALESCE($2, next_attempt_at)
+		WHERE id = $1`, id, retryAt, lastError)
+}
+
+func (s *Store) updateDelivery(ctx context.Context, query string, args ...any) error {
+	tag, err := s.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFoundError("webhook delivery not found", nil)
+	}
+	return nil
+}
+
+const deliveryColumns = `SELECT d.id, d.webhook_id, w.url, w.secret, d.event_type, d.payload::text, d.status,
+		d.attempts, d.next_attempt_at, d.last_error, d.created_at, d.delivered_at`
+
+func scanDeliveries(rows pgx.Rows, err error) ([]domain.WebhookDelivery, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var payload string
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.EventType, &payload, &d.Status,
+			&d.Attempts, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
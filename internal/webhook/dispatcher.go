@@ -0,0 +1,198 @@
+// Package webhook delivers queued webhook payloads to subscriber endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+)
+
+// Headers set on every delivery request.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Outbox is the part of the repository the dispatcher works with.
+type Outbox interface {
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64, at time.Time) error
+	FailWebhookDelivery(ctx context.Context, id int64, retryAt *time.Time, lastError string) error
+}
+
+// Config tunes delivery. Zero fields fall back to the defaults.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+}
+
+// Default delivery settings.
+const (
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 20
+	DefaultMaxAttempts  = 8
+	DefaultBaseBackoff  = 5 * time.Second
+	DefaultMaxBackoff   = time.Hour
+	DefaultTimeout      = 10 * time.Second
+)
+
+// Dispatcher polls the outbox and posts due deliveries. Failed deliveries are
+// retried with exponential backoff until MaxAttempts is reached, after which
+// they are marked DEAD.
+type Dispatcher struct {
+	outbox Outbox
+	client *http.Client
+	cfg    Config
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+// NewDispatcher returns a dispatcher reading from outbox.
+func NewDispatcher(outbox Outbox, cfg Config, logger *slog.Logger) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = DefaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Dispatcher{
+		outbox: outbox,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		now:    func() time.Time { return time.Now().UTC() },
+		logger: logger,
+	}
+}
+
+// Run delivers batches until ctx is cancelled. A full batch is followed by the
+// next one right away; otherwise the dispatcher waits for PollInterval.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Error("webhook dispatch failed", slog.String("error", err.Error()))
+		}
+		if n == d.cfg.BatchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.cfg.PollInterval):
+		}
+	}
+}
+
+// RunOnce claims one batch of due deliveries and attempts each of them. It
+// returns how many deliveries were claimed.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	now := d.now()
+	// A claimed delivery is hidden from other dispatchers until every request
+	// of the batch could have timed out.
+	lease := now.Add(d.cfg.Timeout * time.Duration(d.cfg.BatchSize+1))
+	deliveries, err := d.outbox.ClaimWebhookDeliveries(ctx, now, lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claim deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if err := d.deliver(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) error {
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		return d.outbox.CompleteWebhookDelivery(ctx, delivery.ID, d.now())
+	}
+
+	attempts := delivery.Attempts + 1
+	var retryAt *time.Time
+	if attempts < d.cfg.MaxAttempts {
+		at := d.now().Add(d.backoff(attempts))
+		retryAt = &at
+	}
+	d.logger.Warn("webhook delivery failed",
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int64("webhook_id", delivery.WebhookID),
+		slog.Int("attempts", attempts),
+		slog.Bool("dead", retryAt == nil),
+		slog.String("error", sendErr.Error()),
+	)
+	return d.outbox.FailWebhookDelivery(ctx, delivery.ID, retryAt, sendErr.Error())
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery domain.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt after the given number of
+// failed attempts: BaseBackoff doubled per attempt, capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+// Sign returns the signature header value for body: the hex encoded
+// HMAC-SHA256 of body keyed with secret, prefixed with "sha256=".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
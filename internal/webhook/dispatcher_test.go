@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GolovachevS/pr-reviewer-service/internal/domain"
+	"github.com/GolovachevS/pr-reviewer-service/internal/service"
+	"github.com/GolovachevS/pr-reviewer-service/internal/storage/memory"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a server answering with the given statuses in turn and
+// repeating the last one.
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []receivedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(statuses[min(len(received), len(statuses))-1])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), received...)
+	}
+}
+
+// setup creates a team, a webhook subscribed to merges and a merged PR, so a
+// single delivery is queued.
+func setup(t *testing.T, url string) (*memory.Store, domain.Webhook) {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.New()
+	_, err := store.CreateTeam(ctx, domain.Team{TeamName: "backend", RequiredReviewers: 1, Members: []domain.TeamMember{
+		{UserID: "author", Username: "Author", IsActive: true},
+		{UserID: "u1", Username: "Alice", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	hook, err := store.CreateWebhook(ctx, domain.Webhook{URL: url, Secret: "s3cret", EventTypes: []string{domain.WebhookPullRequestMerged}})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	svc := service.New(store, nil)
+	if _, err := svc.CreatePullRequest(ctx, service.CreatePullRequestInput{PullRequestID: "pr-1", PullRequestName: "PR", AuthorID: "author"}); err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if _, err := svc.MergePullRequest(ctx, "", "pr-1"); err != nil {
+		t.Fatalf("MergePullRequest: %v", err)
+	}
+	return store, hook
+}
+
+func deliveries(t *testing.T, store *memory.Store, webhookID int64) []domain.WebhookDelivery {
+	t.Helper()
+	list, err := store.ListWebhookDeliveries(context.Background(), service.WebhookDeliveryFilter{WebhookID: webhookID, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	return list
+}
+
+func TestDispatcherDeliversSignedPayload(t *testing.T) {
+	srv, received := newReceiver(t, http.StatusNoContent)
+	store, hook := setup(t, srv.URL)
+	d := NewDispatcher(store, Config{}, nil)
+
+	n, err := d.RunOnce(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("RunOnce = %d, %v; want 1 delivery", n, err)
+	}
+
+	reqs := received()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	req := reqs[0]
+	if got := req.header.Get(HeaderSignature); got != Sign("s3cret", req.body) {
+		t.Fatalf("signature %q does not match body", got)
+	}
+	if req.header.Get(HeaderEvent) != domain.WebhookPullRequestMerged || req.header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers: %v", req.header)
+	}
+	var payload domain.WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != domain.WebhookPullRequestMerged || payload.Reason != domain.AuditMerged || payload.PullRequestID != "pr-1" {
+		t.Fatalf("unexpected payload: %s", req.body)
+	}
+
+	list := deliveries(t, store, hook.ID)
+	if len(list) != 1 || list[0].Status != domain.DeliveryDelivered || list[0].Attempts != 1 ||
+		req.header.Get(HeaderDelivery) != strconv.FormatInt(list[0].ID, 10) {
+		t.Fatalf("unexpected deliveries: %+v", list)
+	}
+	if n, _ := d.RunOnce(context.Background()); n != 0 {
+		t.Fatalf("delivered payload sent again")
+	}
+}
+
+func TestDispatcherRetriesWithBackoffThenGivesUp(t *testing.T) {
+	srv, received := newReceiver(t, http.StatusInternalServerError)
+	store, hook := setup(t, srv.URL)
+	d := NewDispatcher(store, Config{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour}, nil)
+	now := time.Now().UTC()
+	d.now = func() time.Time { return now }
+
+	if _, err := d.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	list := deliveries(t, store, hook.ID)
+	if list[0].Status != domain.DeliveryPending || list[0].Attempts != 1 || !list[0].NextAttemptAt.Equal(now.Add(time.Minute)) ||
+		list[0].LastError == "" {
+		t.Fatalf("unexpected delivery after first failure: %+v", list[0])
+	}
+
+	now = now.Add(59 * time.Second)
+	if n, _ := d.RunOnce(context.Background()); n != 0 {
+		t.Fatalf("delivery retried before its backoff elapsed")
+	}
+
+	now = now.Add(time.Second)
+	if _, err := d.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if list = deliveries(t, store, hook.ID); !list[0].NextAttemptAt.Equal(now.Add(2 * time.Minute)) {
+		t.Fatalf("second retry at %v, want backoff doubled", list[0].NextAttemptAt)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := d.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if list = deliveries(t, store, hook.ID); list[0].Status != domain.DeliveryDead || list[0].Attempts != 3 {
+		t.Fatalf("unexpected delivery after last attempt: %+v", list[0])
+	}
+	if len(received()) != 3 {
+		t.Fatalf("got %d requests, want 3", len(received()))
+	}
+
+	now = now.Add(24 * time.Hour)
+	if n, _ := d.RunOnce(context.Background()); n != 0 {
+		t.Fatalf("dead delivery retried")
+	}
+}
+
+func TestDispatcherRecoversAfterFailure(t *testing.T) {
+	srv, _ := newReceiver(t, http.StatusBadGateway, http.StatusOK)
+	store, hook := setup(t, srv.URL)
+	d := NewDispatcher(store, Config{BaseBackoff: time.Second}, nil)
+	now := time.Now().UTC()
+	d.now = func() time.Time { return now }
+
+	_, _ = d.RunOnce(context.Background())
+	now = now.Add(time.Second)
+	_, _ = d.RunOnce(context.Background())
+
+	list := deliveries(t, store, hook.ID)
+	if list[0].Status != domain.DeliveryDelivered || list[0].Attempts != 2 || list[0].LastError != "" {
+		t.Fatalf("unexpected delivery: %+v", list[0])
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, Config{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}, nil)
+	cases := map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 60: 10 * time.Second}
+	for attempts, want := range cases {
+		if got := d.backoff(attempts); got != want {
+			t.Fatalf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}